@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- The default parser recognizes HTML tags on its own, which then are
+-- dropped by the `english` configuration. No need to strip them first
+ALTER TABLE "articles"
+    ADD COLUMN "search_vector" TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', "title"), 'A')
+        || setweight(to_tsvector('english', "subtitle"), 'B')
+        || setweight(to_tsvector('english', "content"), 'C')) STORED;
+CREATE INDEX "articles_search_vector_idx"
+    ON "articles" USING GIN("search_vector");
+
+ALTER TABLE "projects"
+    ADD COLUMN "search_vector" TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', "name"), 'A')
+        || setweight(to_tsvector('english', "synopsis"), 'B')
+        || setweight(to_tsvector('english', "description"), 'C')) STORED;
+CREATE INDEX "projects_search_vector_idx"
+    ON "projects" USING GIN("search_vector");
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX "projects_search_vector_idx";
+ALTER TABLE "projects" DROP COLUMN "search_vector";
+
+DROP INDEX "articles_search_vector_idx";
+ALTER TABLE "articles" DROP COLUMN "search_vector";
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- Revisions are never updated, every write of the content records a new one
+CREATE TABLE "article_revisions"(
+    "id" SERIAL PRIMARY KEY,
+    "article_id" INTEGER NOT NULL,
+    "title" VARCHAR(128) NOT NULL,
+    "content" TEXT NOT NULL,
+    "message" VARCHAR(256) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY("article_id")
+        REFERENCES "articles"("id")
+        ON DELETE CASCADE);
+CREATE INDEX "article_revisions_article_id_idx"
+    ON "article_revisions"("article_id");
+
+CREATE TABLE "project_revisions"(
+    "id" SERIAL PRIMARY KEY,
+    "project_id" INTEGER NOT NULL,
+    "name" VARCHAR(128) NOT NULL,
+    "description" TEXT NOT NULL,
+    "message" VARCHAR(256) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY("project_id")
+        REFERENCES "projects"("id")
+        ON DELETE CASCADE);
+CREATE INDEX "project_revisions_project_id_idx"
+    ON "project_revisions"("project_id");
+
+-- Existing content starts off with a single revision
+INSERT INTO "article_revisions"("article_id", "title", "content", "message", "created_at")
+SELECT "id", "title", "content", 'Initial revision', "updated_at"
+FROM "articles";
+
+INSERT INTO "project_revisions"("project_id", "name", "description", "message", "created_at")
+SELECT "id", "name", "description", 'Initial revision', "updated_at"
+FROM "projects";
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TABLE "project_revisions";
+DROP TABLE "article_revisions";
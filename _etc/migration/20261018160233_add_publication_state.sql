@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- Only published entries are listed. Archived ones are still reachable
+-- through their link, while drafts and scheduled ones aren't shown at all
+CREATE TYPE "publication_state" AS ENUM(
+    'draft',
+    'scheduled',
+    'published',
+    'archived');
+
+-- Existing entries were public already, so they stay published
+ALTER TABLE "articles"
+    ADD COLUMN "state" publication_state NOT NULL DEFAULT 'published',
+    ADD COLUMN "publish_at" TIMESTAMP,
+    ADD CONSTRAINT "articles_publish_at_check"
+        CHECK ("state" <> 'scheduled' OR "publish_at" IS NOT NULL);
+CREATE INDEX "articles_scheduled_idx"
+    ON "articles"("publish_at") WHERE "state" = 'scheduled';
+
+ALTER TABLE "projects"
+    ADD COLUMN "state" publication_state NOT NULL DEFAULT 'published',
+    ADD COLUMN "publish_at" TIMESTAMP,
+    ADD CONSTRAINT "projects_publish_at_check"
+        CHECK ("state" <> 'scheduled' OR "publish_at" IS NOT NULL);
+CREATE INDEX "projects_scheduled_idx"
+    ON "projects"("publish_at") WHERE "state" = 'scheduled';
+
+ALTER TABLE "series"
+    ADD COLUMN "state" publication_state NOT NULL DEFAULT 'published',
+    ADD COLUMN "publish_at" TIMESTAMP,
+    ADD CONSTRAINT "series_publish_at_check"
+        CHECK ("state" <> 'scheduled' OR "publish_at" IS NOT NULL);
+CREATE INDEX "series_scheduled_idx"
+    ON "series"("publish_at") WHERE "state" = 'scheduled';
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX "series_scheduled_idx";
+ALTER TABLE "series"
+    DROP CONSTRAINT "series_publish_at_check",
+    DROP COLUMN "publish_at",
+    DROP COLUMN "state";
+
+DROP INDEX "projects_scheduled_idx";
+ALTER TABLE "projects"
+    DROP CONSTRAINT "projects_publish_at_check",
+    DROP COLUMN "publish_at",
+    DROP COLUMN "state";
+
+DROP INDEX "articles_scheduled_idx";
+ALTER TABLE "articles"
+    DROP CONSTRAINT "articles_publish_at_check",
+    DROP COLUMN "publish_at",
+    DROP COLUMN "state";
+
+DROP TYPE "publication_state";
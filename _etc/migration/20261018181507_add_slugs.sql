@@ -0,0 +1,120 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE "articles" ADD COLUMN "slug" TEXT;
+ALTER TABLE "projects" ADD COLUMN "slug" TEXT;
+ALTER TABLE "series" ADD COLUMN "slug" TEXT;
+
+-- Slugs of existing entries come from their title or name. Whenever that
+-- leaves nothing, a number (which would be taken for an id) or a slug that's
+-- already used, the id tells them apart. Natural slugs are handed out first,
+-- then the others take the first one that's still free, so they can't run
+-- into one another
+-- +goose StatementBegin
+DO $$
+DECLARE
+    tbl TEXT;
+    kind TEXT;
+    src TEXT;
+    entry RECORD;
+    base TEXT;
+    candidate TEXT;
+    nth INT;
+    taken BOOLEAN;
+BEGIN
+    FOR tbl, kind, src IN
+        SELECT * FROM (VALUES
+            ('articles', 'article', 'title'),
+            ('projects', 'project', 'name'),
+            ('series', 'serie', 'name')) AS "t"
+    LOOP
+        EXECUTE format($query$
+            WITH
+                base AS (
+                    SELECT
+                        id,
+                        TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(COALESCE(%I, '')), '[^a-z0-9]+', '-', 'g')) AS "slug"
+                    FROM %I),
+                ranked AS (
+                    SELECT
+                        id,
+                        slug,
+                        ROW_NUMBER() OVER (PARTITION BY slug ORDER BY id) AS "nth"
+                    FROM base)
+            UPDATE %I
+            SET "slug" = ranked.slug
+            FROM ranked
+            WHERE %I.id = ranked.id
+                AND ranked.nth = 1
+                AND ranked.slug <> ''
+                AND ranked.slug !~ '^[0-9]+$'
+            $query$, src, tbl, tbl, tbl);
+
+        FOR entry IN EXECUTE format(
+            $query$SELECT id, COALESCE(%I, '') AS "source" FROM %I WHERE slug IS NULL ORDER BY id$query$,
+            src, tbl)
+        LOOP
+            base := TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(entry.source), '[^a-z0-9]+', '-', 'g'));
+            IF base = '' THEN
+                base := kind;
+            END IF;
+
+            candidate := base || '-' || entry.id;
+            nth := 1;
+            LOOP
+                EXECUTE format('SELECT EXISTS(SELECT 1 FROM %I WHERE slug = $1)', tbl)
+                    INTO taken
+                    USING candidate;
+                EXIT WHEN NOT taken;
+                nth := nth + 1;
+                candidate := base || '-' || entry.id || '-' || nth;
+            END LOOP;
+            EXECUTE format('UPDATE %I SET slug = $1 WHERE id = $2', tbl)
+                USING candidate, entry.id;
+        END LOOP;
+    END LOOP;
+END
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE "articles"
+    ALTER COLUMN "slug" SET NOT NULL,
+    ADD CONSTRAINT "articles_slug_key" UNIQUE("slug");
+ALTER TABLE "projects"
+    ALTER COLUMN "slug" SET NOT NULL,
+    ADD CONSTRAINT "projects_slug_key" UNIQUE("slug");
+ALTER TABLE "series"
+    ALTER COLUMN "slug" SET NOT NULL,
+    ADD CONSTRAINT "series_slug_key" UNIQUE("slug");
+
+-- Former slugs, so links to them lead to where the entry is now. The target
+-- isn't a foreign key as it may be any of the three tables, hence lookups
+-- join it with the table of its kind
+CREATE TABLE "slug_redirects"(
+    "kind" TEXT NOT NULL,
+    "slug" TEXT NOT NULL,
+    "target_id" INT NOT NULL,
+    "created_at" TIMESTAMP NOT NULL DEFAULT LOCALTIMESTAMP,
+
+    PRIMARY KEY("kind", "slug"),
+    CONSTRAINT "slug_redirects_kind_check"
+        CHECK ("kind" IN ('article', 'project', 'serie')));
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TABLE "slug_redirects";
+
+ALTER TABLE "series"
+    DROP CONSTRAINT "series_slug_key",
+    DROP COLUMN "slug";
+ALTER TABLE "projects"
+    DROP CONSTRAINT "projects_slug_key",
+    DROP COLUMN "slug";
+ALTER TABLE "articles"
+    DROP CONSTRAINT "articles_slug_key",
+    DROP COLUMN "slug";
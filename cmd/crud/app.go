@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -11,6 +12,8 @@ import (
 	"github.com/solsteace/misite/internal/controller"
 	"github.com/solsteace/misite/internal/persistence"
 	"github.com/solsteace/misite/internal/service"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+	"github.com/solsteace/misite/internal/utility/lib/markdown"
 )
 
 type appState int
@@ -26,6 +29,11 @@ const (
 	fLAG_TARGET     = "--target"
 	fLAG_ENTITY     = "--entity"
 	fLAG_ACTION     = "--action"
+	fLAG_MIGRATE    = "--migrate"
+	fLAG_SYNC       = "--sync"
+	fLAG_DRY_RUN    = "--dry-run"
+	fLAG_EXPORT     = "--export"
+	fLAG_WRITE_IDS  = "--write-ids"
 	fLAG_HELP       = "--help"
 )
 
@@ -37,14 +45,23 @@ func main() {
 	var target string
 	var entity string
 	var action string
+	var migrateAction string
+	var syncDir string
+	var exportDir string
+	var dryRun bool
+	var writeBack bool
 	var lastFlag string
 	for _, arg := range args {
 		switch state {
 		case sTATE_READY:
 			switch arg {
-			case fLAG_TARGET, fLAG_SOURCEFILE, fLAG_ENTITY, fLAG_ACTION:
+			case fLAG_TARGET, fLAG_SOURCEFILE, fLAG_ENTITY, fLAG_ACTION, fLAG_MIGRATE, fLAG_SYNC, fLAG_EXPORT:
 				state = sTATE_NEED_ARG
 				lastFlag = arg
+			case fLAG_DRY_RUN:
+				dryRun = true
+			case fLAG_WRITE_IDS:
+				writeBack = true
 			case fLAG_HELP:
 				state = sTATE_OVER
 			}
@@ -58,6 +75,12 @@ func main() {
 				entity = arg
 			case fLAG_ACTION:
 				action = arg
+			case fLAG_MIGRATE:
+				migrateAction = arg
+			case fLAG_SYNC:
+				syncDir = arg
+			case fLAG_EXPORT:
+				exportDir = arg
 			}
 			state = sTATE_READY
 		case sTATE_OVER:
@@ -81,8 +104,69 @@ func main() {
 			log.Fatalf("missing data source file argument")
 		case fLAG_TARGET:
 			log.Fatalf("missing target argument")
+		case fLAG_MIGRATE:
+			log.Fatalf("missing migrate argument")
+		case fLAG_SYNC:
+			log.Fatalf("missing sync directory argument")
+		case fLAG_EXPORT:
+			log.Fatalf("missing export directory argument")
 		}
 	}
+	if migrateAction != "" {
+		if target == "" {
+			log.Fatalf("missing target argument")
+		}
+
+		dbCfg, err := pgx.ParseConfig(target)
+		if err != nil {
+			log.Fatalf("db init: %v", err)
+		}
+		dbConn := sqlx.NewDb(stdlib.OpenDB(*dbCfg), "pgx")
+		defer dbConn.Close()
+		if err := migrate(dbConn, migrateAction); err != nil {
+			log.Fatalf("migrating: %s", err.Error())
+		}
+		return
+	}
+	if syncDir != "" {
+		if target == "" {
+			log.Fatalf("missing target argument")
+		}
+
+		dbCfg, err := pgx.ParseConfig(target)
+		if err != nil {
+			log.Fatalf("db init: %v", err)
+		}
+		dbConn := sqlx.NewDb(stdlib.OpenDB(*dbCfg), "pgx")
+		defer dbConn.Close()
+		if err := sync(dbConn, syncDir, dryRun); err != nil {
+			log.Fatalf("syncing: %s", err.Error())
+		}
+		return
+	}
+	if exportDir != "" {
+		if target == "" {
+			log.Fatalf("missing target argument")
+		}
+
+		dbCfg, err := pgx.ParseConfig(target)
+		if err != nil {
+			log.Fatalf("db init: %v", err)
+		}
+		dbConn := sqlx.NewDb(stdlib.OpenDB(*dbCfg), "pgx")
+		defer dbConn.Close()
+
+		key, err := cursor.RandomKey()
+		if err != nil {
+			log.Fatalf("cursor init: %v", err)
+		}
+		db := persistence.NewPg(dbConn, cursor.NewSealer(key))
+		controller := controller.NewController(service.NewService(&db), "", "", "", "", "", "")
+		if err := controller.Export(context.Background(), exportDir); err != nil {
+			log.Fatalf("exporting: %s", err.Error())
+		}
+		return
+	}
 	switch "" {
 	case entity:
 		log.Fatalf("missing entity argument")
@@ -101,25 +185,33 @@ func main() {
 	dbConn := sqlx.NewDb(stdlib.OpenDB(*dbCfg), "pgx")
 	defer dbConn.Close()
 
-	db := persistence.NewPg(dbConn)
+	// Nothing gets listed here, so the cursors don't need a lasting key
+	key, err := cursor.RandomKey()
+	if err != nil {
+		log.Fatalf("cursor init: %v", err)
+	}
+	db := persistence.NewPg(dbConn, cursor.NewSealer(key))
 	service := service.NewService(&db)
-	controller := controller.NewController(service, "", "", "")
+	controller := controller.NewController(service, "", "", "", "", "", "")
 
-	var handler func(*os.File) error
+	var made insertion
+	var handler func(context.Context, *os.File) error
 	switch entity {
 	case "a", "articles":
 		switch action {
 		case "a", "add":
-			handler = controller.InsertArticles
+			handler = inserting(controller.InsertArticles, articleId, &made)
 		case "u", "update":
 			handler = controller.UpsertArticles
 		case "d", "delete":
 			handler = controller.DeleteArticles
+		case "r", "rollback":
+			handler = controller.RollbackArticles
 		}
 	case "at", "article_tags":
 		switch action {
 		case "a", "add":
-			handler = controller.InsertArticleTags
+			handler = inserting(controller.InsertArticleTags, articleTagId, &made)
 		case "u", "update":
 			handler = controller.UpsertArticleTags
 		case "d", "delete":
@@ -128,7 +220,7 @@ func main() {
 	case "p", "projects":
 		switch action {
 		case "a", "add":
-			handler = controller.InsertProjects
+			handler = inserting(controller.InsertProjects, projectId, &made)
 		case "u", "update":
 			handler = controller.UpsertProjects
 		case "d", "delete":
@@ -137,7 +229,7 @@ func main() {
 	case "pt", "project_tags":
 		switch action {
 		case "a", "add":
-			handler = controller.InsertProjectTags
+			handler = inserting(controller.InsertProjectTags, projectTagId, &made)
 		case "u", "update":
 			handler = controller.UpsertProjectTags
 		case "d", "delete":
@@ -146,7 +238,7 @@ func main() {
 	case "pl", "project_links":
 		switch action {
 		case "a", "add":
-			handler = controller.InsertProjectLinks
+			handler = inserting(controller.InsertProjectLinks, projectLinkId, &made)
 		case "u", "update":
 			handler = controller.UpsertProjectLinks
 		case "d", "delete":
@@ -155,7 +247,7 @@ func main() {
 	case "t", "tags":
 		switch action {
 		case "a", "add":
-			handler = controller.InsertTags
+			handler = inserting(controller.InsertTags, tagId, &made)
 		case "u", "update":
 			handler = controller.UpsertTags
 		case "d", "delete":
@@ -164,7 +256,7 @@ func main() {
 	case "s", "series":
 		switch action {
 		case "a", "add":
-			handler = controller.InsertSeries
+			handler = inserting(controller.InsertSeries, serieId, &made)
 		case "u", "update":
 			handler = controller.UpsertSeries
 		case "d", "delete":
@@ -179,9 +271,30 @@ func main() {
 	if err != nil {
 		log.Fatalf("opening data file: %s", err.Error())
 	}
-	if err := handler(f); err != nil {
+	plan, err := controller.Atomic(context.Background(), dryRun, func(ctx context.Context) error {
+		return handler(ctx, f)
+	})
+	if err != nil {
 		log.Fatalf("handling action: %s", err.Error())
 	}
+	if dryRun {
+		printPlan(plan)
+		return
+	}
+
+	if len(made.records) == 0 {
+		return
+	}
+	if err := made.print(); err != nil {
+		log.Fatalf("printing what was made: %s", err.Error())
+	}
+	if !writeBack {
+		return
+	} else if markdown.IsFile(sourceFile) {
+		log.Printf("ids aren't written into Markdown sources")
+	} else if err := writeIds(sourceFile, made.ids); err != nil {
+		log.Fatalf("writing ids into data file: %s", err.Error())
+	}
 }
 
 const tEXT_HELP = `NOTE ============
@@ -193,10 +306,22 @@ help - do you need help?
 *action - what do you want to do?
 - (a)dd
 - (u)pdate
-- (d)pdate
+- (d)elete
+- (r)ollback: restore articles from one of their revisions, given as
+  {"article_id", "revision_id", "message"}
 
 *source - where the app should look the data from to do the action?
 
+write-ids - on add, write the ids given to the entries into the source, so
+it can be used to update them afterwards. Takes no argument. Whatever is
+added gets printed along with its id either way
+
+dry-run - print what the action would do, then undo it. Takes no argument.
+Every action runs in a single transaction, so a failure keeps nothing, and
+this shows the rows it would add, the fields it would change from what to
+what, and the rows it would delete, including the tags and links going
+along with them
+
 *target - where the action should be applied to?
 
 *entity - to what object the action should be applied to?
@@ -206,4 +331,55 @@ help - do you need help?
 - (p)roject_(t)ags
 - (p)roject_(l)inks
 - (t)ags
-- (s)eries `
+- (s)eries
+
+Articles, projects and series may carry a "state" (draft, scheduled,
+published or archived) and a "publish_at". When both are left out, they're
+published right away on add and keep their publication on update. They're
+scheduled when only "publish_at" is given.
+Their "slug" names their URL, made from the title or name when left out on
+add, and kept as it is when left out on update. Former slugs redirect.
+Their "created_at" (and "updated_at" of articles and projects) is the time
+of the write when left out, except for "created_at" on update
+
+The content of articles and projects is either HTML or Markdown (.md),
+which is converted on the way in. Markdown may start with a YAML front
+matter between "---" lines, giving what the JSON leaves out:
+    title, subtitle (the name and synopsis of projects), tags (by name,
+    made when missing), serie (by slug) and order (within the serie)
+Adding a single Markdown file as source needs no JSON at all. In JSON, an
+entry fully describes its article or project, also taking
+    "thumbnail"
+    "serie" as {"slug", "order"} or {"id", "order"}, or for articles the
+    flat "serie_id" and "serie_order", where 0 takes it out of its serie
+    "tags" as names, made when missing
+    "links" of projects as {"url", "display_text"}, matched by url
+each kept as it is when left out, and replacing what's stored otherwise
+
+migrate - manage the schema of the target, ignoring other flags but target
+- up: apply every pending migration
+- down: revert the latest migration
+- redo: revert, then re-apply the latest migration
+- status: list migrations and whether they're applied
+
+sync - make the target match a content directory, ignoring other flags but
+target. Entries are matched by slug: missing ones are added, differing ones
+updated and the ones left out of the directory deleted, along with their
+tags and links. The directory is laid out as
+    series/<slug>/serie.json
+    articles/<slug>/index.md (or index.html), with an optional article.json
+    projects/<slug>/index.md (or index.html), with an optional project.json
+The JSON files take the fields of a single entry above, where series are
+given by slug. Thumbnail, serie, tags and links left out of an entry are
+taken off it. Takes dry-run too
+
+export - write the content of the target into a directory, ignoring other
+flags but target. Every entity gets its data file, named like
+writeArticle.json or writeProjectLink.json, in the layout the actions
+above take. Contents go to articles/<slug>.html and projects/<slug>.html,
+which the data files refer to by a path starting with the directory as
+given, so update from the same working directory. Updating each entity in
+the order series, tags, articles, article_tags, projects, project_tags,
+project_links brings another target to the same content, ids and dates
+included.
+The demo server seeds itself from such a directory too `
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+// Longest value shown whole. Contents are cut, as only the fact they changed
+// matters here
+const cHANGE_VALUE_WIDTH = 60
+
+// Tells what a dry run would have done, which is nothing at all when the
+// plan is empty
+func printPlan(plan []entity.Change) {
+	if len(plan) == 0 {
+		fmt.Println("Dry run: nothing would change")
+		return
+	}
+	fmt.Println("Dry run, nothing was kept:")
+	printChanges(plan)
+}
+
+func printChanges(changes []entity.Change) {
+	for _, c := range changes {
+		fmt.Printf("%s %s %s\n", c.Action, c.Entity, c.Ref)
+		for _, f := range c.Fields {
+			fmt.Printf("    %s: %s -> %s\n", f.Name, shownValue(f.Before), shownValue(f.After))
+		}
+	}
+}
+
+func shownValue(v string) string {
+	v = strings.Join(strings.Fields(v), " ")
+	if n := utf8.RuneCountInString(v); n > cHANGE_VALUE_WIDTH {
+		return fmt.Sprintf("%q... (%d characters)", string([]rune(v)[:cHANGE_VALUE_WIDTH]), n)
+	}
+	return fmt.Sprintf("%q", v)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+// What an insert made, reported once it's kept
+type insertion struct {
+	records []any
+	ids     []int
+}
+
+// Turns an insert handler into a plain one, keeping what it made in `made`
+func inserting[T any](
+	insert func(context.Context, *os.File) ([]T, error),
+	id func(T) int,
+	made *insertion,
+) func(context.Context, *os.File) error {
+	return func(ctx context.Context, f *os.File) error {
+		records, err := insert(ctx, f)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			made.records = append(made.records, r)
+			made.ids = append(made.ids, id(r))
+		}
+		return nil
+	}
+}
+
+var (
+	articleId     = func(a entity.WriteArticle) int { return a.Id }
+	articleTagId  = func(at entity.WriteArticleTag) int { return at.Id }
+	projectId     = func(p entity.WriteProject) int { return p.Id }
+	projectTagId  = func(pt entity.WriteProjectTag) int { return pt.Id }
+	projectLinkId = func(pl entity.WriteProjectLink) int { return pl.Id }
+	tagId         = func(t entity.WriteTag) int { return t.Id }
+	serieId       = func(s entity.WriteSerie) int { return s.Id }
+)
+
+// Prints what was made in the layout of the data files
+func (made insertion) print() error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(struct {
+		Data []any `json:"data"`
+	}{made.records})
+}
+
+// Writes the ids given to the entries of the data file `file`, in their
+// order, so the file can be used for the updates coming after. Everything
+// else is kept as it's written, save for the order of the keys
+func writeIds(file string, ids []int) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(source, &document); err != nil {
+		return err
+	}
+	var entries []map[string]json.RawMessage
+	if err := json.Unmarshal(document["data"], &entries); err != nil {
+		return err
+	} else if len(entries) != len(ids) {
+		return fmt.Errorf("%s has %d entries, while %d were made", file, len(entries), len(ids))
+	}
+	for idx, e := range entries {
+		e["id"] = json.RawMessage(strconv.Itoa(ids[idx]))
+	}
+	if document["data"], err = json.Marshal(entries); err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(document, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(out, '\n'), info.Mode().Perm())
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solsteace/misite"
+	"github.com/solsteace/misite/internal/migration"
+)
+
+func migrate(db *sqlx.DB, action string) error {
+	migrations, err := migration.Parse(misite.Migrations, misite.MigrationDir)
+	if err != nil {
+		return err
+	}
+	migrator := migration.NewMigrator(db, migrations)
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("Applied %s\n", m.Name)
+		}
+		if err != nil {
+			return err
+		} else if len(applied) == 0 {
+			fmt.Println("Nothing to apply, schema is up to date")
+		}
+	case "down":
+		reverted, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %s\n", reverted.Name)
+	case "redo":
+		redone, err := migrator.Redo()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Redone %s\n", redone.Name)
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-24s %s\n", "Applied At", "Migration")
+		for _, s := range status {
+			appliedAt := "Pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-24s %s\n", appliedAt, s.Migration.Name)
+		}
+	default:
+		return fmt.Errorf("unknown migrate action `%s`", action)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solsteace/misite/internal/controller"
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/persistence"
+	"github.com/solsteace/misite/internal/service"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+)
+
+func sync(db *sqlx.DB, dir string, dryRun bool) error {
+	key, err := cursor.RandomKey()
+	if err != nil {
+		return err
+	}
+	store := persistence.NewPg(db, cursor.NewSealer(key))
+	controller := controller.NewController(service.NewService(&store), "", "", "", "", "", "")
+
+	var changes []entity.Change
+	plan, err := controller.Atomic(context.Background(), dryRun, func(ctx context.Context) error {
+		var err error
+		changes, err = controller.Sync(ctx, dir)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		printPlan(plan)
+		return nil
+	}
+
+	printChanges(changes)
+	if len(changes) == 0 {
+		fmt.Println("Nothing to sync, the target is up to date")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Renders the whole site into a directory that any static host can serve.
+//
+// Every GET route of `route.Router` is requested in-process, from the entries
+// of the sitemap, and so is every in-site link found in the rendered pages.
+// Pages are written as `index.html` of their own directory, and the links
+// between them (`href` and `hx-get`) are rewritten to point there. Search and
+// the "load more" of the exploration pages still need the server, so the
+// exploration pages are exported with all of their entries instead
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+	"github.com/solsteace/misite"
+	"github.com/solsteace/misite/internal/controller"
+	"github.com/solsteace/misite/internal/migration"
+	"github.com/solsteace/misite/internal/persistence"
+	"github.com/solsteace/misite/internal/route"
+	"github.com/solsteace/misite/internal/service"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+)
+
+func main() {
+	loadEnv()
+
+	// Cursors only live as long as the export
+	var store service.Store
+	key, err := cursor.RandomKey()
+	if err != nil {
+		log.Fatalf("cursor init: %v", err)
+	}
+	cursors := cursor.NewSealer(key)
+	if dB_URL == "" {
+		store = persistence.NewMemory(cursors)
+	} else {
+		dbCfg, err := pgx.ParseConfig(dB_URL)
+		if err != nil {
+			log.Fatalf("db init: %v", err)
+		}
+		dbConn := sqlx.NewDb(stdlib.OpenDB(*dbCfg), "pgx")
+		defer dbConn.Close()
+
+		migrations, err := migration.Parse(misite.Migrations, misite.MigrationDir)
+		if err != nil {
+			log.Fatalf("migration init: %v", err)
+		}
+		if err := migration.NewMigrator(dbConn, migrations).Verify(); err != nil {
+			log.Fatalf("schema check: %v", err)
+		}
+
+		pg := persistence.NewPg(dbConn, cursors)
+		store = &pg
+	}
+
+	service := service.NewService(store)
+	if dB_URL == "" {
+		fmt.Println("DB_URL is empty, exporting the demo...")
+		if dEMO_SEED_DIR != "" {
+			service.Seed(context.Background(), dEMO_SEED_DIR)
+		}
+	}
+	entries, err := service.Sitemap(context.Background())
+	if err != nil {
+		log.Fatalf("export init: %v", err)
+	}
+
+	// There's nothing to load more from, so the exploration pages list every
+	// entry at once
+	controller := controller.NewController(
+		service,
+		sITE_URL,
+		sITE_NAME,
+		rOBOTS_RULES,
+		iNDEX_URL,
+		aLPINE_URL,
+		hTMX_URL).WithListLimit(len(entries) + 1)
+	app := chi.NewRouter()
+	route.NewRouter(controller).UseOn(app)
+
+	exporter, err := newExporter(app, entries, sITE_URL)
+	if err != nil {
+		log.Fatalf("export init: %v", err)
+	}
+	if err := exporter.crawl(); err != nil {
+		log.Fatalf("crawling: %v", err)
+	}
+	if err := exporter.write(eXPORT_DIR, sTATIC_DIR); err != nil {
+		log.Fatalf("writing: %v", err)
+	}
+	fmt.Printf("Exported %d documents to %s\n", exporter.len(), eXPORT_DIR)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path"
+)
+
+// Same as the ones of `cmd/srv`, as the pages are rendered the same way
+var (
+	dB_URL        string
+	dEMO_SEED_DIR string
+
+	sITE_URL     string
+	sITE_NAME    string
+	rOBOTS_RULES string
+
+	iNDEX_URL  string
+	aLPINE_URL string
+	hTMX_URL   string
+
+	eXPORT_DIR string
+	sTATIC_DIR string
+)
+
+func loadEnv() {
+	dB_URL = os.Getenv("DB_URL")
+	dEMO_SEED_DIR = os.Getenv("DEMO_SEED_DIR") // only used when `DB_URL` is empty
+
+	// Links of feeds, sitemaps and the head of the pages are absolute, so
+	// this should be where the export is going to be hosted
+	sITE_URL = os.Getenv("SITE_URL")
+	if sITE_URL == "" {
+		log.Println("env: SITE_URL is empty, absolute links will point to http://localhost")
+	}
+	sITE_NAME = os.Getenv("SITE_NAME")
+	if sITE_NAME == "" {
+		sITE_NAME = "misite"
+	}
+	rOBOTS_RULES = "User-agent: *\nDisallow: /write\nDisallow: /search\n"
+	if file := os.Getenv("ROBOTS_FILE"); file != "" {
+		rules, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("env: reading ROBOTS_FILE: %v", err)
+		}
+		rOBOTS_RULES = string(rules)
+	}
+
+	iNDEX_URL = os.Getenv("INDEX_URL")
+	if iNDEX_URL == "" {
+		iNDEX_URL = "/static/index.html"
+	}
+	aLPINE_URL = os.Getenv("ALPINEJS_URL")
+	hTMX_URL = os.Getenv("HTMX_URL")
+	if root := os.Getenv("LOCAL_SCRIPT_URL"); root != "" {
+		aLPINE_URL = path.Join(root, "alpinejs")
+		hTMX_URL = path.Join(root, "htmx")
+	}
+
+	// Where the site is written to. Files of a former export are overwritten
+	// but never removed
+	eXPORT_DIR = os.Getenv("EXPORT_DIR")
+	if eXPORT_DIR == "" {
+		eXPORT_DIR = "./public"
+	}
+	sTATIC_DIR = "./static" // as served by `route.Router`
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"log"
+	"maps"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/api"
+	"github.com/solsteace/misite/internal/utility/lib/sitemap"
+	"github.com/solsteace/misite/internal/utility/lib/slug"
+)
+
+var (
+	// Start tags, along with the attributes within them
+	startTag  = regexp.MustCompile(`<[a-zA-Z][\w-]*(?:\s+[^\s"'>/=]+(?:="[^"]*")?)*\s*/?>`)
+	attribute = regexp.MustCompile(`(\s)([^\s"'>/=]+)="([^"]*)"`)
+
+	// Routes that only make sense with the server behind them
+	skipped = []string{"/static/*", "/write", api.Search}
+
+	// Routes that need a query, which are only reached through the links of
+	// the pages
+	linkedOnly = []string{"/tags", "/article/{ref}/diff", "/serie/{ref}/articles", "/serie/{ref}/projects"}
+)
+
+// A response of the site, along with where it ends up in the export
+type document struct {
+	file     string // relative to the export root
+	link     string // to the file from within the export
+	page     bool   // a page on its own, rather than a fragment or a file
+	mimeType string
+	body     []byte
+}
+
+type exporter struct {
+	handler http.Handler
+	host    string
+	seeds   []string
+
+	documents map[string]*document // by the link they were found by
+	order     []string
+	files     map[string]bool // taken paths of the export
+}
+
+// Starts from the routes of `handler`, filled with the sitemap `entries`
+func newExporter(handler *chi.Mux, entries []entity.SitemapEntry, siteUrl string) (*exporter, error) {
+	host := "localhost"
+	if siteUrl != "" {
+		parsed, err := url.Parse(siteUrl)
+		if err != nil {
+			return nil, fmt.Errorf("newExporter: %w", err)
+		}
+		host = parsed.Host
+	}
+
+	// Refs of the entries of each kind, standing for the params of the routes
+	refs := map[string][]string{}
+	tags := map[string]bool{}
+	for _, e := range entries {
+		switch e.Kind {
+		case entity.SitemapArticle, entity.SitemapProject, entity.SitemapSerie:
+			refs[string(e.Kind)] = append(refs[string(e.Kind)], e.Ref)
+		case entity.SitemapArticleTag, entity.SitemapProjectTag:
+			if !tags[e.Ref] {
+				tags[e.Ref] = true
+				refs["tag"] = append(refs["tag"], url.PathEscape(e.Ref))
+			}
+		}
+	}
+	// The sitemap is only split past its limits, counting the home and the
+	// exploration pages along with the entries. Its size is left out, as it
+	// would take urls of a kilobyte each to reach the limit first
+	if parts := (len(entries) + 4 + sitemap.MaxUrls - 1) / sitemap.MaxUrls; parts > 1 {
+		for part := 1; part <= parts; part++ {
+			refs["sitemap"] = append(refs["sitemap"], strconv.Itoa(part))
+		}
+	}
+
+	e := exporter{
+		handler:   handler,
+		host:      host,
+		documents: map[string]*document{},
+		files:     map[string]bool{}}
+	err := chi.Walk(handler, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if method != http.MethodGet || isSkipped(route) || slices.Contains(linkedOnly, route) {
+			return nil
+		}
+		e.seeds = append(e.seeds, expand(route, refs)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("newExporter: %w", err)
+	}
+	return &e, nil
+}
+
+// Every path `route` stands for, filling its params with the `refs` of the
+// kind named by the segment before them (or by the route, for sitemaps)
+func expand(route string, refs map[string][]string) []string {
+	start := strings.Index(route, "{")
+	if start < 0 {
+		return []string{route}
+	}
+	end := strings.Index(route[start:], "}") + start
+
+	kind := path.Base(route[:start])
+	if strings.HasPrefix(route, "/sitemap-") {
+		kind = "sitemap"
+	}
+	var paths []string
+	for _, ref := range refs[kind] {
+		paths = append(paths, expand(route[:start]+ref+route[end+1:], refs)...)
+	}
+	return paths
+}
+
+func isSkipped(link string) bool {
+	for _, s := range skipped {
+		if prefix, ok := strings.CutSuffix(s, "*"); ok && strings.HasPrefix(link, prefix) {
+			return true
+		} else if link == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *exporter) len() int {
+	return len(e.documents)
+}
+
+// Requests every seed and every in-site link of the pages found along the
+// way. Missing documents and redirections are reported and left out, as
+// there's nothing to export behind them
+func (e *exporter) crawl() error {
+	queue := e.seeds
+	queued := map[string]bool{}
+	for _, s := range queue {
+		queued[s] = true
+	}
+	for len(queue) > 0 {
+		link := queue[0]
+		queue = queue[1:]
+
+		doc, err := e.fetch(link)
+		if err != nil {
+			return fmt.Errorf("exporter.crawl: %w", err)
+		} else if doc == nil {
+			continue
+		}
+		e.documents[link] = doc
+		e.order = append(e.order, link)
+
+		if doc.mimeType != "text/html" {
+			continue
+		}
+		for _, tag := range startTag.FindAll(doc.body, -1) {
+			for _, attr := range attribute.FindAllSubmatch(tag, -1) {
+				name := string(attr[2])
+				if name != "href" && name != "hx-get" {
+					continue
+				}
+				found, ok := internalLink(html.UnescapeString(string(attr[3])))
+				if ok && !queued[found] && !isSkipped(found) {
+					queued[found] = true
+					queue = append(queue, found)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Normalizes a link of the site, telling whether it is one
+func internalLink(link string) (string, bool) {
+	if !strings.HasPrefix(link, "/") || strings.HasPrefix(link, "//") {
+		return "", false
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+	if query := parsed.Query().Encode(); query != "" {
+		return parsed.EscapedPath() + "?" + query, true
+	}
+	return parsed.EscapedPath(), true
+}
+
+// Renders `link` as the server would, then decides where it's written to.
+// Routes only answering HTMX requests redirect the others elsewhere, and
+// are exported as fragments instead
+func (e *exporter) fetch(link string) (*document, error) {
+	res := e.request(link, false)
+	fragment := false
+	if res.Code == http.StatusSeeOther {
+		res = e.request(link, true)
+		fragment = true
+	}
+	switch {
+	case res.Code >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("exporter.fetch: %s answered with %d", link, res.Code)
+	case res.Code != http.StatusOK:
+		log.Printf("skipping %s: answered with %d", link, res.Code)
+		return nil, nil
+	}
+
+	// Fragments are written as is, without telling what they are
+	contentType := res.Header().Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(res.Body.Bytes())
+	}
+	mimeType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("exporter.fetch: %s: %w", link, err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("exporter.fetch: %w", err)
+	}
+
+	doc := document{mimeType: mimeType, body: res.Body.Bytes()}
+	dir := strings.TrimSuffix(parsed.Path, "/")
+	switch {
+	case mimeType != "text/html": // feeds, sitemaps and such keep their path
+		doc.file = parsed.Path
+		doc.link = parsed.EscapedPath()
+	case fragment:
+		doc.file = e.free(dir, queryName(parsed, "fragment")+".html")
+		doc.link = escapePath(doc.file)
+	default:
+		if parsed.RawQuery != "" {
+			dir = e.free(dir, queryName(parsed, ""))
+		}
+		doc.page = true
+		doc.file = dir + "/index.html"
+		doc.link = escapePath(dir + "/")
+	}
+	e.files[doc.file] = true
+	return &doc, nil
+}
+
+func (e *exporter) request(link string, app bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, link, nil)
+	req.Host = e.host
+	if app {
+		req.Header.Set("Hx-Request", "true")
+	}
+
+	res := httptest.NewRecorder()
+	e.handler.ServeHTTP(res, req)
+	return res
+}
+
+// Names a directory (or a file) after the query of `link`, or a digest of it
+// when it doesn't make for a readable name
+func queryName(link *url.URL, fallback string) string {
+	query := link.Query()
+	var words []string
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		words = append(words, key)
+		words = append(words, query[key]...)
+	}
+	name := slug.Make(strings.Join(words, " "))
+	if name == "" {
+		name = fallback
+	}
+	if len(name) > 64 {
+		name = fmt.Sprintf("q-%x", sha256.Sum256([]byte(link.RawQuery)))[:18]
+	}
+	return name
+}
+
+// A path within `dir` that's not taken yet, named after `name`
+func (e *exporter) free(dir, name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	base = slug.Free(base, func(candidate string) bool {
+		p := path.Join(dir, candidate+ext)
+		return e.files[p] || e.files[p+"/index.html"]
+	})
+	return path.Join(dir, base+ext)
+}
+
+func escapePath(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+// Points the links of a page to the documents of the export. HTMX requests
+// of a page are answered with the whole page there, so only the part that
+// the server would've sent is picked out of it
+func (e *exporter) rewrite(body []byte) []byte {
+	return startTag.ReplaceAllFunc(body, func(tag []byte) []byte {
+		target := "#page"
+		hasSelect := false
+		for _, attr := range attribute.FindAllSubmatch(tag, -1) {
+			switch string(attr[2]) {
+			case "hx-target":
+				if strings.HasPrefix(string(attr[3]), "#") {
+					target = html.UnescapeString(string(attr[3]))
+				}
+			case "hx-select":
+				hasSelect = true
+			}
+		}
+
+		selectPage := false
+		tag = attribute.ReplaceAllFunc(tag, func(attr []byte) []byte {
+			parts := attribute.FindSubmatch(attr)
+			name := string(parts[2])
+			if name != "href" && name != "hx-get" && name != "src" {
+				return attr
+			}
+			link, ok := internalLink(html.UnescapeString(string(parts[3])))
+			if !ok {
+				return attr
+			}
+			doc, ok := e.documents[link]
+			if !ok {
+				return attr
+			}
+			selectPage = selectPage || (name == "hx-get" && doc.page)
+			return fmt.Appendf(nil, `%s%s="%s"`, parts[1], name, html.EscapeString(doc.link))
+		})
+		if selectPage && !hasSelect {
+			end := len(tag) - 1
+			if tag[end-1] == '/' {
+				end--
+			}
+			tag = append(tag[:end:end], fmt.Appendf(nil, ` hx-select="%s"%s`,
+				html.EscapeString(target+" > *"), tag[end:])...)
+		}
+		return tag
+	})
+}
+
+// Writes the documents into `dir`, along with the static files
+func (e *exporter) write(dir, staticDir string) error {
+	for _, link := range e.order {
+		doc := e.documents[link]
+		body := doc.body
+		if doc.mimeType == "text/html" {
+			body = e.rewrite(body)
+		}
+
+		file := filepath.Join(dir, filepath.FromSlash(doc.file))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return fmt.Errorf("exporter.write: %w", err)
+		}
+		if err := os.WriteFile(file, body, 0o644); err != nil {
+			return fmt.Errorf("exporter.write: %w", err)
+		}
+	}
+
+	err := filepath.WalkDir(staticDir, func(src string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(staticDir, src)
+		if err != nil {
+			return err
+		}
+		return copyFile(src, filepath.Join(dir, "static", rel))
+	})
+	if err != nil {
+		return fmt.Errorf("exporter.write: %w", err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,34 +11,76 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/solsteace/misite"
 	"github.com/solsteace/misite/internal/controller"
+	"github.com/solsteace/misite/internal/migration"
 	"github.com/solsteace/misite/internal/persistence"
 	"github.com/solsteace/misite/internal/route"
 	"github.com/solsteace/misite/internal/service"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
 )
 
 func main() {
 	loadEnv()
 
-	dbCfg, err := pgx.ParseConfig(dB_URL)
-	if err != nil {
-		log.Fatalf("db init: %v", err)
+	// Without a database, run on an in-memory store for demo purposes
+	var store service.Store
+	cursors := cursor.NewSealer(cURSOR_KEY)
+	if dB_URL == "" {
+		store = persistence.NewMemory(cursors)
+	} else {
+		dbCfg, err := pgx.ParseConfig(dB_URL)
+		if err != nil {
+			log.Fatalf("db init: %v", err)
+		}
+		dbConn := sqlx.NewDb(stdlib.OpenDB(*dbCfg), "pgx")
+		defer dbConn.Close()
+
+		migrations, err := migration.Parse(misite.Migrations, misite.MigrationDir)
+		if err != nil {
+			log.Fatalf("migration init: %v", err)
+		}
+		migrator := migration.NewMigrator(dbConn, migrations)
+		if mIGRATE_ON_START {
+			applied, err := migrator.Up()
+			for _, m := range applied {
+				fmt.Printf("Applied migration %s\n", m.Name)
+			}
+			if err != nil {
+				log.Fatalf("migrating: %v", err)
+			}
+		}
+		if err := migrator.Verify(); err != nil {
+			log.Fatalf("schema check: %v", err)
+		}
+
+		pg := persistence.NewPg(dbConn, cursors)
+		store = &pg
 	}
-	dbConn := sqlx.NewDb(stdlib.OpenDB(*dbCfg), "pgx")
-	defer dbConn.Close()
 
 	app := chi.NewRouter()
-	store := persistence.NewPg(dbConn)
-	service := service.NewService(&store)
+	service := service.NewService(store)
 	controller := controller.NewController(
 		service,
+		sITE_URL,
+		sITE_NAME,
+		rOBOTS_RULES,
 		iNDEX_URL,
 		aLPINE_URL,
 		hTMX_URL)
+	if dB_URL == "" {
+		fmt.Println("DB_URL is empty, running in demo mode...")
+		if dEMO_SEED_DIR != "" {
+			service.Seed(context.Background(), dEMO_SEED_DIR)
+		}
+	}
+
+	go service.RunScheduler(context.Background(), sCHEDULER_CHECK)
 
 	app.Use(middleware.RequestID)
 	app.Use(middleware.Logger)
 	app.Use(middleware.Recoverer)
+	app.Use(route.Timeout(rEQUEST_TIMEOUT))
 	route.NewRouter(controller).UseOn(app)
 
 	port := 10000
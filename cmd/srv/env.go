@@ -1,12 +1,25 @@
 package main
 
 import (
+	"log"
 	"os"
 	"path"
+	"time"
+
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
 )
 
 var (
-	dB_URL string
+	dB_URL           string
+	dEMO_SEED_DIR    string
+	mIGRATE_ON_START bool
+	cURSOR_KEY       []byte
+	rEQUEST_TIMEOUT  time.Duration
+	sCHEDULER_CHECK  time.Duration
+
+	sITE_URL     string
+	sITE_NAME    string
+	rOBOTS_RULES string
 
 	iNDEX_URL  string
 	aLPINE_URL string
@@ -15,6 +28,62 @@ var (
 
 func loadEnv() {
 	dB_URL = os.Getenv("DB_URL")
+	dEMO_SEED_DIR = os.Getenv("DEMO_SEED_DIR") // only used when `DB_URL` is empty
+
+	// Otherwise, the server only checks whether the schema is up to date
+	mIGRATE_ON_START = os.Getenv("MIGRATE_ON_START") == "true"
+
+	// Signs listing cursors. Without it, cursors are only valid until the
+	// server restarts
+	cURSOR_KEY = []byte(os.Getenv("CURSOR_KEY"))
+	if len(cURSOR_KEY) == 0 {
+		key, err := cursor.RandomKey()
+		if err != nil {
+			log.Fatalf("env: %v", err)
+		}
+		cURSOR_KEY = key
+	}
+
+	// Requests whose queries take longer than this are answered with 504
+	rEQUEST_TIMEOUT = 10 * time.Second
+	if timeout := os.Getenv("REQUEST_TIMEOUT"); timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("env: REQUEST_TIMEOUT should be a positive duration, like `10s`")
+		}
+		rEQUEST_TIMEOUT = parsed
+	}
+
+	// How often to look for newly scheduled entries, on top of waking up for
+	// the ones already known
+	sCHEDULER_CHECK = time.Minute
+	if check := os.Getenv("SCHEDULER_CHECK"); check != "" {
+		parsed, err := time.ParseDuration(check)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("env: SCHEDULER_CHECK should be a positive duration, like `1m`")
+		}
+		sCHEDULER_CHECK = parsed
+	}
+
+	// Absolute url the site is served from, like `https://example.com`, for
+	// the links shared outside of it. Taken from each request when empty
+	sITE_URL = os.Getenv("SITE_URL")
+	sITE_NAME = os.Getenv("SITE_NAME")
+	if sITE_NAME == "" {
+		sITE_NAME = "misite"
+	}
+
+	// Rules of robots.txt, read from a file. The sitemap is referenced on
+	// top of them
+	rOBOTS_RULES = "User-agent: *\nDisallow: /write\nDisallow: /search\n"
+	if file := os.Getenv("ROBOTS_FILE"); file != "" {
+		rules, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("env: reading ROBOTS_FILE: %v", err)
+		}
+		rOBOTS_RULES = string(rules)
+	}
+
 	iNDEX_URL = os.Getenv("INDEX_URL")
 	if iNDEX_URL == "" {
 		iNDEX_URL = "/static/index.html"
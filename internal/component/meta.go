@@ -0,0 +1,79 @@
+package component
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/a-h/templ"
+)
+
+// Describes a page to browsers, crawlers and link previews
+type Meta struct {
+	SiteName    string
+	Title       string // of the page alone, left empty on the homepage
+	Description string
+	Url         string // canonical, absolute
+	Image       string // absolute, if any
+	Type        string // OpenGraph type, like `website` or `article`
+	NoIndex     bool   // keeps crawlers from indexing the page
+
+	// schema.org description of the page, written as JSON-LD
+	JsonLd map[string]any
+}
+
+func (m Meta) FullTitle() string {
+	if m.Title == "" {
+		return m.SiteName
+	}
+	return m.Title + " | " + m.SiteName
+}
+
+// Title of link previews, which carry the site name on their own
+func (m Meta) headline() string {
+	if m.Title == "" {
+		return m.SiteName
+	}
+	return m.Title
+}
+
+func (m Meta) twitterCard() string {
+	if m.Image != "" {
+		return "summary_large_image"
+	}
+	return "summary"
+}
+
+func (m Meta) robots() string {
+	if m.NoIndex {
+		return "noindex"
+	}
+	return "index, follow"
+}
+
+// The description of the page in a script element. `encoding/json` escapes
+// `<`, `>` and `&`, so the description can't close the element on its own
+func jsonLd(meta Meta, oob bool) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		encoded, err := json.Marshal(meta.JsonLd)
+		if err != nil {
+			return err
+		}
+		swap := ""
+		if oob {
+			swap = ` hx-swap-oob="true"`
+		}
+		_, err = fmt.Fprintf(w,
+			`<script id="meta-jsonld" type="application/ld+json"%s>%s</script>`, swap, encoded)
+		return err
+	})
+}
+
+// Marks the head tags of a partial response to replace the current ones
+func outOfBand(oob bool) templ.Attributes {
+	if !oob {
+		return templ.Attributes{}
+	}
+	return templ.Attributes{"hx-swap-oob": "true"}
+}
@@ -1,13 +1,19 @@
 package controller
 
 import (
-	"context"
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
 	"github.com/solsteace/misite/internal/component"
 	"github.com/solsteace/misite/internal/component/page"
+	"github.com/solsteace/misite/internal/entity"
 	"github.com/solsteace/misite/internal/service"
 )
 
@@ -16,11 +22,20 @@ const (
 	serieQueryParam = "sId"
 
 	dEFAULT_PAGE_SIZE = 10
+
+	sERIE_PAGE_SIZE     = 10
+	sERIE_READING_ORDER = "reading" // `order` of the serie page listing from the first part
+
+	fEED_SIZE = 20
 )
 
 type Controller struct {
 	service service.Service
 
+	siteUrl     string // absolute url the site is served from, taken from the requests when empty
+	siteName    string
+	robotsRules string // content of robots.txt, save for the sitemap
+	listLimit   int    // entries of every exploration page, when they aren't paged
 	indexUrl    string // url to homepage
 	alpinejsUrl string // url to alpinejs script (unrelated to controller, but we're gonna stick with these infra anyway for now)
 	htmxUrl     string // url to htmx script (unrelated to controller, but we're gonna stick with these infra anyway for now)
@@ -28,41 +43,116 @@ type Controller struct {
 
 func NewController(
 	service service.Service,
+	siteUrl string,
+	siteName string,
+	robotsRules string,
 	indexUrl string,
 	alpinejsUrl string,
 	htmxUrl string,
 ) Controller {
 	return Controller{
 		service:     service,
+		siteUrl:     strings.TrimSuffix(siteUrl, "/"),
+		siteName:    siteName,
+		robotsRules: robotsRules,
 		indexUrl:    indexUrl,
 		alpinejsUrl: alpinejsUrl,
 		htmxUrl:     htmxUrl}
 }
 
+// Lists `limit` entries on every exploration page, rather than a page of
+// them. Meant for static exports, where there's nothing to load more from
+func (c Controller) WithListLimit(limit int) Controller {
+	c.listLimit = limit
+	return c
+}
+
 // This is not totally fool-proof as it could be "spoofed". Better way? maybe next time
 func (c Controller) isAppRequest(r *http.Request) bool {
 	_, ok := r.Header["Hx-Request"]
 	return ok
 }
 
-// Serves a page with its base
-func (c Controller) serveWithBase(
+// Absolute url of the site root, without the trailing slash
+func (c Controller) siteRoot(r *http.Request) string {
+	if c.siteUrl != "" {
+		return c.siteUrl
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// Serves a document generated on each request, such as a feed or a sitemap.
+// Crawlers and readers polling it are answered by `http.ServeContent` with
+// 304 whenever their copy is still current, judging by an ETag of the
+// document or its latest modification
+func serveGenerated(
+	w http.ResponseWriter,
+	r *http.Request,
+	contentType string,
+	modTime time.Time,
+	body []byte,
+) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", fmt.Sprintf(`"%x"`, sha256.Sum256(body)))
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(body))
+}
+
+// Link to the search of the entries of an exploration `endpoint` having the
+// tag, which is where the tag badges lead to
+func tagSearchUrl(endpoint, tagName string) string {
+	return fmt.Sprintf("%s?search=%s", endpoint, url.QueryEscape(
+		fmt.Sprintf("tag:%s", strings.ReplaceAll(tagName, " ", "_"))))
+}
+
+// Serves a page with its base, or alone when it's requested through HTMX. In
+// the latter, the head is updated out-of-band to describe the page instead
+func (c Controller) servePage(
 	body templ.Component,
+	meta component.Meta,
 	w http.ResponseWriter,
 	r *http.Request,
 ) error {
-	ctx := templ.WithChildren(context.Background(), body)
-	if err := component.Base(c.alpinejsUrl, c.htmxUrl).Render(ctx, w); err != nil {
-		return fmt.Errorf("controller.serveWithBase: %w", err)
+	meta.SiteName = c.siteName
+	if !c.isAppRequest(r) {
+		ctx := templ.WithChildren(r.Context(), body)
+		if err := component.Base(c.alpinejsUrl, c.htmxUrl, meta).Render(ctx, w); err != nil {
+			return fmt.Errorf("controller.servePage: %w", err)
+		}
+	} else if err := templ.Join(body, component.HeadSwap(meta)).Render(r.Context(), w); err != nil {
+		return fmt.Errorf("controller.servePage: %w", err)
 	}
 	return nil
 }
 
+// Finds the entry behind the `ref` URL param of a `kind` route. When it's
+// reached through anything but its current slug (its id or a former slug),
+// answers with a permanent redirect to the same route holding the slug
+// instead and gives false, so the handler has nothing else to do
+func (c Controller) resolve(w http.ResponseWriter, r *http.Request, kind entity.SlugKind) (int, bool, error) {
+	ref := chi.URLParam(r, "ref")
+	id, slug, err := c.service.ResolveSlug(r.Context(), kind, ref)
+	if err != nil {
+		return 0, false, fmt.Errorf("controller.resolve: %w", err)
+	} else if ref == slug {
+		return id, true, nil
+	}
+
+	canonical := url.URL{
+		Path:     strings.Replace(chi.RouteContext(r.Context()).RoutePattern(), "{ref}", slug, 1),
+		RawQuery: r.URL.RawQuery}
+	http.Redirect(w, r, canonical.String(), http.StatusMovedPermanently)
+	return 0, false, nil
+}
+
 func (c Controller) Home(w http.ResponseWriter, r *http.Request) error {
 	pageComponent := page.Home(c.indexUrl)
-	if !c.isAppRequest(r) {
-		return c.serveWithBase(pageComponent, w, r)
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
+	meta := c.pageMeta(
+		r, "", fmt.Sprintf("Projects, articles and series of %s", c.siteName), "/", "WebSite")
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
 		return fmt.Errorf("controller.Home: %w", err)
 	}
 	return nil
@@ -79,11 +169,10 @@ func (c Controller) Error(w http.ResponseWriter, r *http.Request) error {
 		code = http.StatusInternalServerError
 	}
 	pageComponent := page.Error(code, extraMesssage)
-	if !c.isAppRequest(r) {
-		if err := c.serveWithBase(pageComponent, w, r); err != nil {
-			return fmt.Errorf("controller.Error: %w", err)
-		}
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
+	meta := c.pageMeta(r, fmt.Sprintf("Error %d", code), extraMesssage, r.URL.Path, "WebPage")
+	meta.NoIndex = true
+	w.WriteHeader(code)
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
 		return fmt.Errorf("controller.Error: %w", err)
 	}
 	return nil
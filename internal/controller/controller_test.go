@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/persistence"
+	"github.com/solsteace/misite/internal/service"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	s := service.NewService(persistence.NewMemory(cursor.NewSealer([]byte("key"))))
+	content := filepath.Join(t.TempDir(), "content.html")
+	if err := os.WriteFile(content, []byte("<p>Hi</p>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpsertArticles(ctx, []entity.WriteArticle{
+		{Id: 1, Title: "Hello", Content: content},
+		{Id: 2, Title: "Draft", Content: content, State: entity.StateDraft}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpsertArticles(ctx, []entity.WriteArticle{
+		{Id: 1, Title: "Hello", Content: content, Slug: "hello-world"}}); err != nil {
+		t.Fatal(err)
+	}
+	c := NewController(s, "", "misite", "", "/", "", "")
+
+	var resolved error
+	router := chi.NewRouter()
+	for _, route := range []string{"/article/{ref}", "/article/{ref}/diff"} {
+		router.Get(route, func(w http.ResponseWriter, r *http.Request) {
+			id, found, err := c.resolve(w, r, entity.SlugArticle)
+			if resolved = err; err == nil && found {
+				fmt.Fprint(w, id)
+			}
+		})
+	}
+
+	tests := []struct {
+		target   string
+		code     int
+		location string // of the redirect, or the id found
+		notFound bool
+	}{
+		{target: "/article/hello-world", code: http.StatusOK, location: "1"},
+		{target: "/article/hello-world/diff?from=1", code: http.StatusOK, location: "1"},
+		{target: "/article/hello", code: http.StatusMovedPermanently, location: "/article/hello-world"},
+		{target: "/article/1", code: http.StatusMovedPermanently, location: "/article/hello-world"},
+		{
+			target:   "/article/1/diff?from=1&to=2",
+			code:     http.StatusMovedPermanently,
+			location: "/article/hello-world/diff?from=1&to=2"},
+		{target: "/article/draft", notFound: true},
+		{target: "/article/2", notFound: true},
+		{target: "/article/missing", notFound: true},
+	}
+	for _, tt := range tests {
+		resolved = nil
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if tt.notFound {
+			if !errors.As(resolved, &oops.NotFound{}) {
+				t.Errorf("%s: expected nothing to be found, got %v", tt.target, resolved)
+			}
+			continue
+		}
+
+		got := res.Body.String()
+		if tt.code == http.StatusMovedPermanently {
+			got = res.Header().Get("Location")
+		}
+		if resolved != nil || res.Code != tt.code || got != tt.location {
+			t.Errorf("%s: got %d %q %v, want %d %q", tt.target, res.Code, got, resolved, tt.code, tt.location)
+		}
+	}
+}
@@ -1,28 +1,33 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/markdown"
 )
 
-func (c Controller) InsertArticles(f *os.File) error {
+func (c Controller) InsertArticles(ctx context.Context, f *os.File) ([]entity.WriteArticle, error) {
 	var data struct {
 		Articles []entity.WriteArticle `json:"data"`
 	}
-	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		return fmt.Errorf("controller<Controller.InsertArticle>: %w", err)
+	if markdown.IsFile(f.Name()) { // described by its front matter alone
+		data.Articles = []entity.WriteArticle{{Content: f.Name()}}
+	} else if err := json.NewDecoder(f).Decode(&data); err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertArticle>: %w", err)
 	}
 
-	if err := c.service.InsertArticles(data.Articles); err != nil {
-		return fmt.Errorf("controller<Controller.InsertArticle>: %w", err)
+	created, err := c.service.InsertArticles(ctx, data.Articles)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertArticle>: %w", err)
 	}
-	return nil
+	return created, nil
 }
 
-func (c Controller) UpsertArticles(f *os.File) error {
+func (c Controller) UpsertArticles(ctx context.Context, f *os.File) error {
 	var data struct {
 		Articles []entity.WriteArticle `json:"data"`
 	}
@@ -30,13 +35,13 @@ func (c Controller) UpsertArticles(f *os.File) error {
 		return fmt.Errorf("controller<Controller.UpsertArticle>: %w", err)
 	}
 
-	if err := c.service.UpsertArticles(data.Articles); err != nil {
+	if err := c.service.UpsertArticles(ctx, data.Articles); err != nil {
 		return fmt.Errorf("controller<Controller.UpsertArticle>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) DeleteArticles(f *os.File) error {
+func (c Controller) DeleteArticles(ctx context.Context, f *os.File) error {
 	var data struct {
 		Articles []entity.DeleteById `json:"data"`
 	}
@@ -44,27 +49,42 @@ func (c Controller) DeleteArticles(f *os.File) error {
 		return fmt.Errorf("controller<Controller.DeleteArticle>: %w", err)
 	}
 
-	if err := c.service.DeleteArticles(data.Articles); err != nil {
+	if err := c.service.DeleteArticles(ctx, data.Articles); err != nil {
 		return fmt.Errorf("controller<Controller.DeleteArticle>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) InsertArticleTags(f *os.File) error {
+func (c Controller) RollbackArticles(ctx context.Context, f *os.File) error {
 	var data struct {
-		ArticleTags []entity.WriteArticleTag `json:"data"`
+		Rollbacks []entity.RollbackArticle `json:"data"`
 	}
 	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		return fmt.Errorf("controller<Controller.InsertArticleTag>: %w", err)
+		return fmt.Errorf("controller<Controller.RollbackArticle>: %w", err)
 	}
 
-	if err := c.service.InsertArticleTags(data.ArticleTags); err != nil {
-		return fmt.Errorf("controller<Controller.InsertArticleTag>: %w", err)
+	if err := c.service.RollbackArticles(ctx, data.Rollbacks); err != nil {
+		return fmt.Errorf("controller<Controller.RollbackArticle>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) UpsertArticleTags(f *os.File) error {
+func (c Controller) InsertArticleTags(ctx context.Context, f *os.File) ([]entity.WriteArticleTag, error) {
+	var data struct {
+		ArticleTags []entity.WriteArticleTag `json:"data"`
+	}
+	if err := json.NewDecoder(f).Decode(&data); err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertArticleTag>: %w", err)
+	}
+
+	created, err := c.service.InsertArticleTags(ctx, data.ArticleTags)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertArticleTag>: %w", err)
+	}
+	return created, nil
+}
+
+func (c Controller) UpsertArticleTags(ctx context.Context, f *os.File) error {
 	var data struct {
 		ArticleTags []entity.WriteArticleTag `json:"data"`
 	}
@@ -72,13 +92,13 @@ func (c Controller) UpsertArticleTags(f *os.File) error {
 		return fmt.Errorf("controller<Controller.UpsertArticleTag>: %w", err)
 	}
 
-	if err := c.service.UpsertArticleTags(data.ArticleTags); err != nil {
+	if err := c.service.UpsertArticleTags(ctx, data.ArticleTags); err != nil {
 		return fmt.Errorf("controller<Controller.UpsertArticleTag>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) DeleteArticleTags(f *os.File) error {
+func (c Controller) DeleteArticleTags(ctx context.Context, f *os.File) error {
 	var data struct {
 		ArticleTags []entity.DeleteById `json:"data"`
 	}
@@ -86,27 +106,30 @@ func (c Controller) DeleteArticleTags(f *os.File) error {
 		return fmt.Errorf("controller<Controller.DeleteArticleTag>: %w", err)
 	}
 
-	if err := c.service.DeleteArticleTags(data.ArticleTags); err != nil {
+	if err := c.service.DeleteArticleTags(ctx, data.ArticleTags); err != nil {
 		return fmt.Errorf("controller<Controller.DeleteArticleTag>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) InsertProjects(f *os.File) error {
+func (c Controller) InsertProjects(ctx context.Context, f *os.File) ([]entity.WriteProject, error) {
 	var data struct {
 		Projects []entity.WriteProject `json:"data"`
 	}
-	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		return fmt.Errorf("controller<Controller.InsertProject>: %w", err)
+	if markdown.IsFile(f.Name()) { // described by its front matter alone
+		data.Projects = []entity.WriteProject{{Description: f.Name()}}
+	} else if err := json.NewDecoder(f).Decode(&data); err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertProject>: %w", err)
 	}
 
-	if err := c.service.InsertProjects(data.Projects); err != nil {
-		return fmt.Errorf("controller<Controller.InsertProject>: %w", err)
+	created, err := c.service.InsertProjects(ctx, data.Projects)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertProject>: %w", err)
 	}
-	return nil
+	return created, nil
 }
 
-func (c Controller) UpsertProjects(f *os.File) error {
+func (c Controller) UpsertProjects(ctx context.Context, f *os.File) error {
 	var data struct {
 		Projects []entity.WriteProject `json:"data"`
 	}
@@ -114,13 +137,13 @@ func (c Controller) UpsertProjects(f *os.File) error {
 		return fmt.Errorf("controller<Controller.UpsertProject>: %w", err)
 	}
 
-	if err := c.service.UpsertProjects(data.Projects); err != nil {
+	if err := c.service.UpsertProjects(ctx, data.Projects); err != nil {
 		return fmt.Errorf("controller<Controller.UpsertProject>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) DeleteProjects(f *os.File) error {
+func (c Controller) DeleteProjects(ctx context.Context, f *os.File) error {
 	var data struct {
 		Projects []entity.DeleteById `json:"data"`
 	}
@@ -128,27 +151,28 @@ func (c Controller) DeleteProjects(f *os.File) error {
 		return fmt.Errorf("controller<Controller.DeleteProject>: %w", err)
 	}
 
-	if err := c.service.DeleteProjects(data.Projects); err != nil {
+	if err := c.service.DeleteProjects(ctx, data.Projects); err != nil {
 		return fmt.Errorf("controller<Controller.DeleteProject>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) InsertProjectTags(f *os.File) error {
+func (c Controller) InsertProjectTags(ctx context.Context, f *os.File) ([]entity.WriteProjectTag, error) {
 	var data struct {
 		ProjectTags []entity.WriteProjectTag `json:"data"`
 	}
 	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		return fmt.Errorf("controller<Controller.InsertProjectTag>: %w", err)
+		return nil, fmt.Errorf("controller<Controller.InsertProjectTag>: %w", err)
 	}
 
-	if err := c.service.InsertProjectTags(data.ProjectTags); err != nil {
-		return fmt.Errorf("controller<Controller.InsertProjectTag>: %w", err)
+	created, err := c.service.InsertProjectTags(ctx, data.ProjectTags)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertProjectTag>: %w", err)
 	}
-	return nil
+	return created, nil
 }
 
-func (c Controller) UpsertProjectTags(f *os.File) error {
+func (c Controller) UpsertProjectTags(ctx context.Context, f *os.File) error {
 	var data struct {
 		ProjectTags []entity.WriteProjectTag `json:"data"`
 	}
@@ -156,13 +180,13 @@ func (c Controller) UpsertProjectTags(f *os.File) error {
 		return fmt.Errorf("controller<Controller.UpsertProjectTag>: %w", err)
 	}
 
-	if err := c.service.UpsertProjectTags(data.ProjectTags); err != nil {
+	if err := c.service.UpsertProjectTags(ctx, data.ProjectTags); err != nil {
 		return fmt.Errorf("controller<Controller.UpsertProjectTag>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) DeleteProjectTags(f *os.File) error {
+func (c Controller) DeleteProjectTags(ctx context.Context, f *os.File) error {
 	var data struct {
 		ProjectTags []entity.DeleteById `json:"data"`
 	}
@@ -170,27 +194,28 @@ func (c Controller) DeleteProjectTags(f *os.File) error {
 		return fmt.Errorf("controller<Controller.DeleteProjectTag>: %w", err)
 	}
 
-	if err := c.service.DeleteProjectTags(data.ProjectTags); err != nil {
+	if err := c.service.DeleteProjectTags(ctx, data.ProjectTags); err != nil {
 		return fmt.Errorf("controller<Controller.DeleteProjectTag>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) InsertProjectLinks(f *os.File) error {
+func (c Controller) InsertProjectLinks(ctx context.Context, f *os.File) ([]entity.WriteProjectLink, error) {
 	var data struct {
 		ProjectLink []entity.WriteProjectLink `json:"data"`
 	}
 	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		return fmt.Errorf("controller<Controller.InsertProjectLink>: %w", err)
+		return nil, fmt.Errorf("controller<Controller.InsertProjectLink>: %w", err)
 	}
 
-	if err := c.service.InsertProjectLinks(data.ProjectLink); err != nil {
-		return fmt.Errorf("controller<Controller.InsertProjectLink>: %w", err)
+	created, err := c.service.InsertProjectLinks(ctx, data.ProjectLink)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertProjectLink>: %w", err)
 	}
-	return nil
+	return created, nil
 }
 
-func (c Controller) UpsertProjectLinks(f *os.File) error {
+func (c Controller) UpsertProjectLinks(ctx context.Context, f *os.File) error {
 	var data struct {
 		ProjectLink []entity.WriteProjectLink `json:"data"`
 	}
@@ -198,13 +223,13 @@ func (c Controller) UpsertProjectLinks(f *os.File) error {
 		return fmt.Errorf("controller<Controller.UpsertProjectLink>: %w", err)
 	}
 
-	if err := c.service.UpsertProjectLinks(data.ProjectLink); err != nil {
+	if err := c.service.UpsertProjectLinks(ctx, data.ProjectLink); err != nil {
 		return fmt.Errorf("controller<Controller.UpsertProjectLink>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) DeleteProjectLinks(f *os.File) error {
+func (c Controller) DeleteProjectLinks(ctx context.Context, f *os.File) error {
 	var data struct {
 		ProjectLink []entity.DeleteById `json:"data"`
 	}
@@ -212,27 +237,28 @@ func (c Controller) DeleteProjectLinks(f *os.File) error {
 		return fmt.Errorf("controller<Controller.DeleteProjectLink>: %w", err)
 	}
 
-	if err := c.service.DeleteProjectTags(data.ProjectLink); err != nil {
+	if err := c.service.DeleteProjectTags(ctx, data.ProjectLink); err != nil {
 		return fmt.Errorf("controller<Controller.DeleteProjectLink>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) InsertTags(f *os.File) error {
+func (c Controller) InsertTags(ctx context.Context, f *os.File) ([]entity.WriteTag, error) {
 	var data struct {
 		Tag []entity.WriteTag `json:"data"`
 	}
 	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		return fmt.Errorf("controller<Controller.InsertTag>: %w", err)
+		return nil, fmt.Errorf("controller<Controller.InsertTag>: %w", err)
 	}
 
-	if err := c.service.InsertTags(data.Tag); err != nil {
-		return fmt.Errorf("controller<Controller.InsertTag>: %w", err)
+	created, err := c.service.InsertTags(ctx, data.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertTag>: %w", err)
 	}
-	return nil
+	return created, nil
 }
 
-func (c Controller) UpsertTags(f *os.File) error {
+func (c Controller) UpsertTags(ctx context.Context, f *os.File) error {
 	var data struct {
 		Tag []entity.WriteTag `json:"data"`
 	}
@@ -240,13 +266,13 @@ func (c Controller) UpsertTags(f *os.File) error {
 		return fmt.Errorf("controller<Controller.UpsertTag>: %w", err)
 	}
 
-	if err := c.service.UpsertTags(data.Tag); err != nil {
+	if err := c.service.UpsertTags(ctx, data.Tag); err != nil {
 		return fmt.Errorf("controller<Controller.UpsertTag>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) DeleteTags(f *os.File) error {
+func (c Controller) DeleteTags(ctx context.Context, f *os.File) error {
 	var data struct {
 		Tag []entity.DeleteById `json:"data"`
 	}
@@ -254,27 +280,28 @@ func (c Controller) DeleteTags(f *os.File) error {
 		return fmt.Errorf("controller<Controller.DeleteTag>: %w", err)
 	}
 
-	if err := c.service.DeleteProjectTags(data.Tag); err != nil {
+	if err := c.service.DeleteProjectTags(ctx, data.Tag); err != nil {
 		return fmt.Errorf("controller<Controller.DeleteTag>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) InsertSeries(f *os.File) error {
+func (c Controller) InsertSeries(ctx context.Context, f *os.File) ([]entity.WriteSerie, error) {
 	var data struct {
 		Serie []entity.WriteSerie `json:"data"`
 	}
 	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		return fmt.Errorf("controller<Controller.InsertSerie>: %w", err)
+		return nil, fmt.Errorf("controller<Controller.InsertSerie>: %w", err)
 	}
 
-	if err := c.service.InsertSeries(data.Serie); err != nil {
-		return fmt.Errorf("controller<Controller.InsertSerie>: %w", err)
+	created, err := c.service.InsertSeries(ctx, data.Serie)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.InsertSerie>: %w", err)
 	}
-	return nil
+	return created, nil
 }
 
-func (c Controller) UpsertSeries(f *os.File) error {
+func (c Controller) UpsertSeries(ctx context.Context, f *os.File) error {
 	var data struct {
 		Serie []entity.WriteSerie `json:"data"`
 	}
@@ -282,13 +309,13 @@ func (c Controller) UpsertSeries(f *os.File) error {
 		return fmt.Errorf("controller<Controller.UpsertSerie>: %w", err)
 	}
 
-	if err := c.service.UpsertSeries(data.Serie); err != nil {
+	if err := c.service.UpsertSeries(ctx, data.Serie); err != nil {
 		return fmt.Errorf("controller<Controller.UpsertSerie>: %w", err)
 	}
 	return nil
 }
 
-func (c Controller) DeleteSeries(f *os.File) error {
+func (c Controller) DeleteSeries(ctx context.Context, f *os.File) error {
 	var data struct {
 		Serie []entity.DeleteById `json:"data"`
 	}
@@ -296,8 +323,22 @@ func (c Controller) DeleteSeries(f *os.File) error {
 		return fmt.Errorf("controller<Controller.DeleteSerie>: %w", err)
 	}
 
-	if err := c.service.DeleteSeries(data.Serie); err != nil {
+	if err := c.service.DeleteSeries(ctx, data.Serie); err != nil {
 		return fmt.Errorf("controller<Controller.DeleteSerie>: %w", err)
 	}
 	return nil
 }
+
+// Runs the handlers called by `fx` as a single write. On `dryRun`, nothing is
+// kept and the plan of what would have been done is given instead
+func (c Controller) Atomic(
+	ctx context.Context,
+	dryRun bool,
+	fx func(ctx context.Context) error,
+) ([]entity.Change, error) {
+	plan, err := c.service.Atomic(ctx, dryRun, fx)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.Atomic>: %w", err)
+	}
+	return plan, nil
+}
@@ -1,33 +1,19 @@
 package controller
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/a-h/templ"
 	"github.com/solsteace/misite/internal/component/page"
+	"github.com/solsteace/misite/internal/entity"
 	"github.com/solsteace/misite/internal/persistence"
+	"github.com/solsteace/misite/internal/search"
 	"github.com/solsteace/misite/internal/utility/api"
-)
-
-const (
-	reTagOpPrefix   = `tag:`
-	reSerieOpPrefix = `serie:`
-	reTitleOpPrefix = `title:`
-
-	reTagOp   = reTagOpPrefix + `[\w,]+`
-	reSerieOp = reSerieOpPrefix + `[\w,]+`
-	reTitleOp = reTitleOpPrefix + `[\w]+`
-	reAnyOp   = `\w`
-
-	reArticle = reTagOp + "|" + reSerieOp + "|" + reAnyOp
-	reProject = reTagOp + "|" + reSerieOp + "|" + reAnyOp
-	reSerie   = reTitleOp + "|" + reAnyOp
+	"github.com/solsteace/misite/internal/utility/lib/oops"
 )
 
 func (c Controller) ArticleList(w http.ResponseWriter, r *http.Request) error {
@@ -37,9 +23,14 @@ func (c Controller) ArticleList(w http.ResponseWriter, r *http.Request) error {
 	}
 	urlQuery := r.URL.Query()
 
-	searchQuery := strings.ToLower(urlQuery.Get("search"))
+	searchQuery := urlQuery.Get("search")
 	lastItem := urlQuery.Get("last")
-	param := persistence.ArticlesQueryParam{Last: lastItem}
+	firstItem := urlQuery.Get("before")
+	param := persistence.ArticlesQueryParam{Last: lastItem, Before: firstItem}
+	if c.listLimit > 0 {
+		param.Limit = c.listLimit
+	}
+	searchErr := ""
 	if searchQuery != "" {
 		if sLimit := urlQuery.Get("limit"); sLimit != "" {
 			nLimit, err := strconv.ParseInt(sLimit, 10, strconv.IntSize)
@@ -51,47 +42,38 @@ func (c Controller) ArticleList(w http.ResponseWriter, r *http.Request) error {
 			param.Limit = int(nLimit)
 		}
 
-		re, err := regexp.Compile(fmt.Sprintf("(%s)+", reArticle))
-		if err != nil {
+		filter, err := search.Articles(searchQuery)
+		if msg, ok := searchError(err); ok {
+			searchErr = msg
+		} else if err != nil {
 			return fmt.Errorf("controller.ArticleList: %w", err)
 		}
-		for _, t := range re.FindAll([]byte(searchQuery), -1) {
-			token := string(t)
-			if p := reTagOpPrefix; strings.HasPrefix(token, p) {
-				value := strings.ReplaceAll(strings.TrimPrefix(token, p), "_", " ")
-				for _, v := range strings.Split(value, ",") {
-					param.Tag = append(param.Tag, v)
-				}
-			} else if p := reSerieOpPrefix; strings.HasPrefix(token, p) {
-				value := strings.ReplaceAll(strings.TrimPrefix(token, p), "_", " ")
-				for _, v := range strings.Split(value, ",") {
-					param.Serie = append(param.Serie, v)
-				}
-			} else {
-				// Deal with non-operator thing
-			}
-		}
+		param.ExplorationQueryParam = filter
 	}
 
-	articles, err := c.service.Articles(param)
-	if err != nil {
-		return fmt.Errorf("controller.ArticleList: %w", err)
+	articles := []entity.ArticleListPage{}
+	if searchErr == "" {
+		articles, err = c.service.Articles(r.Context(), param)
+		if err != nil {
+			return fmt.Errorf("controller.ArticleList: %w", err)
+		}
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
 	}
 
 	var pageComponent templ.Component
 	shouldFullRender := (currentURL.Path != api.ExploreArticleUrl || // from outside of the page
-		currentURL.Path == api.ExploreArticleUrl && c.isAppRequest(r) && searchQuery == "" && lastItem == "") // calling self via navbar
+		currentURL.Path == api.ExploreArticleUrl && c.isAppRequest(r) && searchQuery == "" && lastItem == "" && firstItem == "") // calling self via navbar
 	if shouldFullRender {
-		pageComponent = page.ArticleList(articles)
+		pageComponent = page.ArticleList(articles, searchErr)
+	} else if searchErr != "" {
+		pageComponent = page.SearchError(searchErr)
 	} else {
 		pageComponent = page.Articles(articles)
 	}
+	meta := c.explorationMeta(r, "Articles")
 
-	if !c.isAppRequest(r) {
-		if err := c.serveWithBase(pageComponent, w, r); err != nil {
-			return fmt.Errorf("controller.ArticleList: %w", err)
-		}
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
 		return fmt.Errorf("controller.ArticleList: %w", err)
 	}
 	return nil
@@ -100,13 +82,18 @@ func (c Controller) ArticleList(w http.ResponseWriter, r *http.Request) error {
 func (c Controller) ProjectList(w http.ResponseWriter, r *http.Request) error {
 	currentURL, err := url.Parse(r.Header.Get("Hx-Current-URL"))
 	if err != nil {
-		return fmt.Errorf("controller.ArticleList: %w", err)
+		return fmt.Errorf("controller.ProjectList: %w", err)
 	}
 	urlQuery := r.URL.Query()
 
-	searchQuery := strings.ToLower(urlQuery.Get("search"))
+	searchQuery := urlQuery.Get("search")
 	lastItem := urlQuery.Get("last")
-	param := persistence.ProjectsQueryParam{Last: lastItem}
+	firstItem := urlQuery.Get("before")
+	param := persistence.ProjectsQueryParam{Last: lastItem, Before: firstItem}
+	if c.listLimit > 0 {
+		param.Limit = c.listLimit
+	}
+	searchErr := ""
 	if searchQuery != "" {
 		if sLimit := urlQuery.Get("limit"); sLimit != "" {
 			nLimit, err := strconv.ParseInt(sLimit, 10, strconv.IntSize)
@@ -118,47 +105,38 @@ func (c Controller) ProjectList(w http.ResponseWriter, r *http.Request) error {
 			param.Limit = int(nLimit)
 		}
 
-		re, err := regexp.Compile(fmt.Sprintf("(%s)+", reProject))
-		if err != nil {
+		filter, err := search.Projects(searchQuery)
+		if msg, ok := searchError(err); ok {
+			searchErr = msg
+		} else if err != nil {
 			return fmt.Errorf("controller.ProjectList: %w", err)
 		}
-		for _, t := range re.FindAll([]byte(searchQuery), -1) {
-			token := string(t)
-			if p := reTagOpPrefix; strings.HasPrefix(token, p) {
-				value := strings.ReplaceAll(strings.TrimPrefix(token, p), "_", " ")
-				for _, v := range strings.Split(value, ",") {
-					param.Tag = append(param.Tag, v)
-				}
-			} else if p := reSerieOpPrefix; strings.HasPrefix(token, p) {
-				value := strings.ReplaceAll(strings.TrimPrefix(token, p), "_", " ")
-				for _, v := range strings.Split(value, ",") {
-					param.Serie = append(param.Serie, v)
-				}
-			} else {
-				// Deal with non-operator thing
-			}
-		}
+		param.ExplorationQueryParam = filter
 	}
 
-	projects, err := c.service.Projects(param)
-	if err != nil {
-		return fmt.Errorf("controller.ProjectList: %w", err)
+	projects := []entity.ProjectListPage{}
+	if searchErr == "" {
+		projects, err = c.service.Projects(r.Context(), param)
+		if err != nil {
+			return fmt.Errorf("controller.ProjectList: %w", err)
+		}
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
 	}
 
 	var pageComponent templ.Component
 	shouldFullRender := (currentURL.Path != api.ExploreProjectUrl || // from outside of the page
-		currentURL.Path == api.ExploreProjectUrl && c.isAppRequest(r) && searchQuery == "" && lastItem == "") // calling self via navbar
+		currentURL.Path == api.ExploreProjectUrl && c.isAppRequest(r) && searchQuery == "" && lastItem == "" && firstItem == "") // calling self via navbar
 	if shouldFullRender {
-		pageComponent = page.ProjectList(projects)
+		pageComponent = page.ProjectList(projects, searchErr)
+	} else if searchErr != "" {
+		pageComponent = page.SearchError(searchErr)
 	} else {
 		pageComponent = page.Projects(projects)
 	}
+	meta := c.explorationMeta(r, "Projects")
 
-	if !c.isAppRequest(r) {
-		if err := c.serveWithBase(pageComponent, w, r); err != nil {
-			return fmt.Errorf("controller.ProjectList: %w", err)
-		}
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
 		return fmt.Errorf("controller.ProjectList: %w", err)
 	}
 	return nil
@@ -167,58 +145,61 @@ func (c Controller) ProjectList(w http.ResponseWriter, r *http.Request) error {
 func (c Controller) SerieList(w http.ResponseWriter, r *http.Request) error {
 	currentURL, err := url.Parse(r.Header.Get("Hx-Current-URL"))
 	if err != nil {
-		return fmt.Errorf("controller.ArticleList: %w", err)
+		return fmt.Errorf("controller.SerieList: %w", err)
 	}
 	urlQuery := r.URL.Query()
 
-	searchQuery := strings.ToLower(urlQuery.Get("search"))
+	searchQuery := urlQuery.Get("search")
 	lastItem := urlQuery.Get("last")
-	param := persistence.SerieListQueryParam{Last: lastItem}
+	firstItem := urlQuery.Get("before")
+	param := persistence.SerieListQueryParam{Last: lastItem, Before: firstItem}
+	if c.listLimit > 0 {
+		param.Limit = c.listLimit
+	}
+	searchErr := ""
 	if searchQuery != "" {
 		if sLimit := urlQuery.Get("limit"); sLimit != "" {
 			nLimit, err := strconv.ParseInt(sLimit, 10, strconv.IntSize)
 			if err != nil {
-				return fmt.Errorf("controller.ArticleList: %w", err)
+				return fmt.Errorf("controller.SerieList: %w", err)
 			} else if nLimit < 0 {
 				nLimit = dEFAULT_PAGE_SIZE
 			}
 			param.Limit = int(nLimit)
 		}
 
-		re, err := regexp.Compile(fmt.Sprintf("(%s)+", reSerie))
-		if err != nil {
-			return fmt.Errorf("controller.ArticleList: %w", err)
-		}
-		for _, t := range re.FindAll([]byte(searchQuery), -1) {
-			token := string(t)
-			if p := reTitleOpPrefix; strings.HasPrefix(token, p) {
-				value := strings.ReplaceAll(strings.TrimPrefix(token, p), "_", " ")
-				param.Title = value
-			} else {
-				// Deal with non-operator thing
-			}
+		filter, err := search.Series(searchQuery)
+		if msg, ok := searchError(err); ok {
+			searchErr = msg
+		} else if err != nil {
+			return fmt.Errorf("controller.SerieList: %w", err)
 		}
+		param.SerieExplorationQueryParam = filter
 	}
 
-	serieList, err := c.service.SerieList(param)
-	if err != nil {
-		return fmt.Errorf("controller<Controller.SerieList>: %w", err)
+	serieList := []entity.SerieListPage{}
+	if searchErr == "" {
+		serieList, err = c.service.SerieList(r.Context(), param)
+		if err != nil {
+			return fmt.Errorf("controller<Controller.SerieList>: %w", err)
+		}
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
 	}
 
 	var pageComponent templ.Component
 	shouldFullRender := (currentURL.Path != api.ExploreSeriesUrl || // from outside of the page
-		currentURL.Path == api.ExploreSeriesUrl && c.isAppRequest(r) && searchQuery == "" && lastItem == "") // calling self via navbar
+		currentURL.Path == api.ExploreSeriesUrl && c.isAppRequest(r) && searchQuery == "" && lastItem == "" && firstItem == "") // calling self via navbar
 	if shouldFullRender {
-		pageComponent = page.SerieList(serieList)
+		pageComponent = page.SerieList(serieList, searchErr)
+	} else if searchErr != "" {
+		pageComponent = page.SearchError(searchErr)
 	} else {
 		pageComponent = page.Series(serieList)
 	}
+	meta := c.explorationMeta(r, "Series")
 
-	if !c.isAppRequest(r) {
-		if err := c.serveWithBase(pageComponent, w, r); err != nil {
-			return fmt.Errorf("controller.SerieList: %w", err)
-		}
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
 		return fmt.Errorf("controller.SerieList: %w", err)
 	}
 	return nil
@@ -247,18 +228,29 @@ func (c Controller) TagList(w http.ResponseWriter, r *http.Request) error {
 		param.Limit = int(nLimit)
 	}
 
-	tagStats, err := c.service.Tags(by, param)
+	tagStats, err := c.service.Tags(r.Context(), by, param)
 	if err != nil {
 		return fmt.Errorf("controller.TagList: %w", err)
 	}
 
 	pageComponent := page.Tags(by, tagStats)
-	if !c.isAppRequest(r) {
-		if err := c.serveWithBase(pageComponent, w, r); err != nil {
-			return fmt.Errorf("controller.SerieList: %w", err)
-		}
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
-		return fmt.Errorf("controller.SerieList: %w", err)
+	meta := c.pageMeta(
+		r,
+		"Tags",
+		fmt.Sprintf("Tags of the %ss of %s", by, c.siteName),
+		fmt.Sprintf("%s?by=%s", r.URL.Path, url.QueryEscape(by)),
+		"CollectionPage")
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
+		return fmt.Errorf("controller.TagList: %w", err)
 	}
 	return nil
 }
+
+// Message of a malformed search, to be shown in place of the entries
+func searchError(err error) (string, bool) {
+	var badValues oops.BadValues
+	if !errors.As(err, &badValues) {
+		return "", false
+	}
+	return badValues.Msg, true
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/persistence"
+	"github.com/solsteace/misite/internal/utility/api"
+	"github.com/solsteace/misite/internal/utility/lib/feed"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+// Feed formats, by the name of the route serving them
+var feedFormats = map[string]struct {
+	contentType string
+	write       func(feed.Feed, io.Writer) error
+}{
+	"feed.xml":  {"application/atom+xml; charset=utf-8", feed.Feed.WriteAtom},
+	"rss.xml":   {"application/rss+xml; charset=utf-8", feed.Feed.WriteRss},
+	"feed.json": {"application/feed+json; charset=utf-8", feed.Feed.WriteJson},
+}
+
+func (c Controller) Feed(w http.ResponseWriter, r *http.Request) error {
+	f := feed.Feed{
+		Title:       c.siteName,
+		Description: fmt.Sprintf("Latest articles of %s", c.siteName),
+		Link:        c.siteRoot(r) + api.ExploreArticleUrl}
+	if err := c.serveFeed(w, r, f, persistence.ExplorationQueryParam{}); err != nil {
+		return fmt.Errorf("controller.Feed: %w", err)
+	}
+	return nil
+}
+
+func (c Controller) SerieFeed(w http.ResponseWriter, r *http.Request) error {
+	serieId, found, err := c.resolve(w, r, entity.SlugSerie)
+	if err != nil {
+		return fmt.Errorf("controller.SerieFeed: %w", err)
+	} else if !found {
+		return nil
+	}
+	serie, err := c.service.Serie(r.Context(), serieId)
+	if err != nil {
+		return fmt.Errorf("controller.SerieFeed: %w", err)
+	}
+
+	f := feed.Feed{
+		Title:       fmt.Sprintf("%s - %s", serie.Name, c.siteName),
+		Description: serie.Description,
+		Link:        fmt.Sprintf("%s/serie/%s", c.siteRoot(r), serie.Slug)}
+	filter := persistence.ExplorationQueryParam{}
+	filter.Include.SerieId = []int{serieId}
+	if err := c.serveFeed(w, r, f, filter); err != nil {
+		return fmt.Errorf("controller.SerieFeed: %w", err)
+	}
+	return nil
+}
+
+func (c Controller) TagFeed(w http.ResponseWriter, r *http.Request) error {
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil {
+		return fmt.Errorf("controller.TagFeed: %w", oops.NotFound{Err: err})
+	}
+	tag, err := c.service.Tag(r.Context(), name)
+	if err != nil {
+		return fmt.Errorf("controller.TagFeed: %w", err)
+	}
+
+	f := feed.Feed{
+		Title:       fmt.Sprintf("%s on %s", tag.Name, c.siteName),
+		Description: fmt.Sprintf("Latest articles of %s tagged %s", c.siteName, tag.Name),
+		Link:        c.siteRoot(r) + tagSearchUrl(api.ExploreArticleUrl, tag.Name)}
+	filter := persistence.ExplorationQueryParam{}
+	filter.Include.Tag = [][]string{{strings.ToLower(tag.Name)}}
+	if err := c.serveFeed(w, r, f, filter); err != nil {
+		return fmt.Errorf("controller.TagFeed: %w", err)
+	}
+	return nil
+}
+
+// Fills `f` with the latest created articles matching `filter`, then serves
+// it in the format named by the last segment of the path
+func (c Controller) serveFeed(
+	w http.ResponseWriter,
+	r *http.Request,
+	f feed.Feed,
+	filter persistence.ExplorationQueryParam,
+) error {
+	format, ok := feedFormats[path.Base(r.URL.Path)]
+	if !ok {
+		return fmt.Errorf("controller.serveFeed: %w", oops.NotFound{})
+	}
+
+	filter.Sort = persistence.SortCreated
+	articles, err := c.service.Articles(r.Context(), persistence.ArticlesQueryParam{
+		Limit:                 fEED_SIZE,
+		ExplorationQueryParam: filter,
+		WithContent:           true})
+	if err != nil {
+		return fmt.Errorf("controller.serveFeed: %w", err)
+	}
+
+	root := c.siteRoot(r)
+	f.Author = c.siteName
+	f.Self = root + r.URL.Path
+	for _, a := range articles {
+		entry := feed.Entry{
+			Link:      fmt.Sprintf("%s/article/%s", root, a.Slug),
+			Title:     a.Title,
+			Summary:   a.Subtitle,
+			Content:   a.Content,
+			Published: a.CreatedAt,
+			Updated:   a.UpdatedAt}
+		for _, t := range a.Tag {
+			entry.Categories = append(entry.Categories, t.Name)
+		}
+		f.Entries = append(f.Entries, entry)
+	}
+
+	var body bytes.Buffer
+	if err := format.write(f, &body); err != nil {
+		return fmt.Errorf("controller.serveFeed: %w", err)
+	}
+	serveGenerated(w, r, format.contentType, f.Updated(), body.Bytes())
+	return nil
+}
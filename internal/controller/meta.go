@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/solsteace/misite/internal/component"
+	"github.com/solsteace/misite/internal/entity"
+)
+
+// Describes a page found at `path`, as a schema.org `schemaType`
+func (c Controller) pageMeta(
+	r *http.Request,
+	title string,
+	description string,
+	path string,
+	schemaType string,
+) component.Meta {
+	pageUrl := c.siteRoot(r) + path
+	return component.Meta{
+		Title:       title,
+		Description: description,
+		Url:         pageUrl,
+		Type:        "website",
+		JsonLd: map[string]any{
+			"@context":    "https://schema.org",
+			"@type":       schemaType,
+			"name":        title,
+			"description": description,
+			"url":         pageUrl}}
+}
+
+// Describes an exploration page, which is told apart by its search alone
+func (c Controller) explorationMeta(r *http.Request, kind string) component.Meta {
+	path := r.URL.Path
+	description := fmt.Sprintf("%s of %s", kind, c.siteName)
+	if search := r.URL.Query().Get("search"); search != "" {
+		path += "?search=" + url.QueryEscape(search)
+		description = fmt.Sprintf("%s of %s matching `%s`", kind, c.siteName, search)
+	}
+	return c.pageMeta(r, kind, description, path, "CollectionPage")
+}
+
+func (c Controller) articleMeta(r *http.Request, article entity.ArticlePage) component.Meta {
+	meta := c.pageMeta(
+		r, article.Title, article.Subtitle, "/article/"+article.Slug, "BlogPosting")
+	meta.Type = "article"
+	meta.JsonLd["headline"] = article.Title
+	meta.JsonLd["mainEntityOfPage"] = meta.Url
+	meta.JsonLd["datePublished"] = article.CreatedAt
+	meta.JsonLd["dateModified"] = article.UpdatedAt
+	if len(article.Tag) > 0 {
+		var keywords []string
+		for _, t := range article.Tag {
+			keywords = append(keywords, t.Name)
+		}
+		meta.JsonLd["keywords"] = keywords
+	}
+	if article.Serie != nil {
+		meta.JsonLd["isPartOf"] = map[string]any{
+			"@type": "CollectionPage",
+			"name":  article.Serie.Name,
+			"url":   fmt.Sprintf("%s/serie/%s", c.siteRoot(r), article.Serie.Slug)}
+	}
+	return meta
+}
+
+func (c Controller) projectMeta(r *http.Request, project entity.ProjectPage) component.Meta {
+	meta := c.pageMeta(
+		r, project.Name, project.Synopsis, "/project/"+project.Slug, "CreativeWork")
+	meta.JsonLd["dateCreated"] = project.CreatedAt
+	meta.JsonLd["dateModified"] = project.UpdatedAt
+	if len(project.Tag) > 0 {
+		var keywords []string
+		for _, t := range project.Tag {
+			keywords = append(keywords, t.Name)
+		}
+		meta.JsonLd["keywords"] = keywords
+	}
+	return meta
+}
+
+// Describes a serie, along with the articles shown on its page
+func (c Controller) serieMeta(
+	r *http.Request,
+	serie entity.SeriePage,
+	articles []entity.SeriePageArticleList,
+) component.Meta {
+	meta := c.pageMeta(
+		r, serie.Name, serie.Description, "/serie/"+serie.Slug, "CollectionPage")
+	if serie.Thumbnail != "" {
+		meta.Image = c.absoluteUrl(r, serie.Thumbnail)
+		meta.JsonLd["image"] = meta.Image
+	}
+	if len(articles) > 0 {
+		var parts []map[string]any
+		for _, a := range articles {
+			parts = append(parts, map[string]any{
+				"@type":    "BlogPosting",
+				"headline": a.Title,
+				"url":      fmt.Sprintf("%s/article/%s", c.siteRoot(r), a.Slug)})
+		}
+		meta.JsonLd["hasPart"] = parts
+	}
+	return meta
+}
+
+// Makes a link relative to the site root absolute, leaving the others as is
+func (c Controller) absoluteUrl(r *http.Request, link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.IsAbs() {
+		return link
+	}
+	root, err := url.Parse(c.siteRoot(r) + "/")
+	if err != nil {
+		return link
+	}
+	return root.ResolveReference(parsed).String()
+}
@@ -1,11 +1,11 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/solsteace/misite/internal/component/page"
+	"github.com/solsteace/misite/internal/entity"
 	"github.com/solsteace/misite/internal/utility/lib/oops"
 )
 
@@ -13,31 +13,27 @@ func (c Controller) MockSpace(w http.ResponseWriter, r *http.Request) error {
 	urlQuery := r.URL.Query()
 	switch urlQuery.Get("for") {
 	case "article":
-		article, err := c.service.ArticleWritespace()
+		article, err := c.service.ArticleWritespace(r.Context())
 		if err != nil {
 			return fmt.Errorf("controller.Writespace: %w", err)
 		}
 
-		pageComponent := page.Article(article)
-		if !c.isAppRequest(r) {
-			if err := c.serveWithBase(pageComponent, w, r); err != nil {
-				return fmt.Errorf("controller.Writespace: %w", err)
-			}
-		} else if err := pageComponent.Render(context.Background(), w); err != nil {
+		pageComponent := page.Article(article, entity.SeriePart{}, entity.RelatedPage{})
+		meta := c.articleMeta(r, article)
+		meta.NoIndex = true
+		if err := c.servePage(pageComponent, meta, w, r); err != nil {
 			return fmt.Errorf("controller.Writespace: %w", err)
 		}
 		return nil
 	case "project":
-		project, err := c.service.ProjectWritespace()
+		project, err := c.service.ProjectWritespace(r.Context())
 		if err != nil {
 			return fmt.Errorf("controller.Writespace: %w", err)
 		}
-		pageComponent := page.Project(project)
-		if !c.isAppRequest(r) {
-			if err := c.serveWithBase(pageComponent, w, r); err != nil {
-				return fmt.Errorf("controller.Writespace: %w", err)
-			}
-		} else if err := pageComponent.Render(context.Background(), w); err != nil {
+		pageComponent := page.Project(project, entity.RelatedPage{})
+		meta := c.projectMeta(r, project)
+		meta.NoIndex = true
+		if err := c.servePage(pageComponent, meta, w, r); err != nil {
 			return fmt.Errorf("controller.Writespace: %w", err)
 		}
 		return nil
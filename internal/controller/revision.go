@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/solsteace/misite/internal/component/page"
+	"github.com/solsteace/misite/internal/entity"
+)
+
+func (c Controller) ArticleHistory(w http.ResponseWriter, r *http.Request) error {
+	articleId, found, err := c.resolve(w, r, entity.SlugArticle)
+	if err != nil {
+		return fmt.Errorf("controller.ArticleHistory: %w", err)
+	} else if !found {
+		return nil
+	}
+
+	history, err := c.service.ArticleHistory(r.Context(), articleId)
+	if err != nil {
+		return fmt.Errorf("controller.ArticleHistory: %w", err)
+	}
+
+	pageComponent := page.ArticleHistory(history)
+	meta := c.pageMeta(
+		r,
+		fmt.Sprintf("History of %s", history.Title),
+		fmt.Sprintf("Revisions of %s", history.Title),
+		r.URL.Path,
+		"WebPage")
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
+		return fmt.Errorf("controller.ArticleHistory: %w", err)
+	}
+	return nil
+}
+
+// Compares the revisions given by the `from` and `to` URL params
+func (c Controller) ArticleDiff(w http.ResponseWriter, r *http.Request) error {
+	articleId, found, err := c.resolve(w, r, entity.SlugArticle)
+	if err != nil {
+		return fmt.Errorf("controller.ArticleDiff: %w", err)
+	} else if !found {
+		return nil
+	}
+
+	var revisionIds [2]int
+	for idx, raw := range []string{
+		r.URL.Query().Get("from"),
+		r.URL.Query().Get("to"),
+	} {
+		id, err := strconv.ParseInt(raw, 10, strconv.IntSize)
+		if err != nil {
+			if errors.Is(err, strconv.ErrSyntax) {
+				id = -1
+			} else {
+				return fmt.Errorf("controller.ArticleDiff: %w", err)
+			}
+		}
+		revisionIds[idx] = int(id)
+	}
+
+	diff, err := c.service.ArticleDiff(r.Context(), articleId, revisionIds[0], revisionIds[1])
+	if err != nil {
+		return fmt.Errorf("controller.ArticleDiff: %w", err)
+	}
+
+	pageComponent := page.ArticleDiff(diff)
+	meta := c.pageMeta(
+		r,
+		fmt.Sprintf("Changes of %s", diff.History.Title),
+		fmt.Sprintf("Changes between two revisions of %s", diff.History.Title),
+		r.URL.Path,
+		"WebPage")
+	meta.NoIndex = true
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
+		return fmt.Errorf("controller.ArticleDiff: %w", err)
+	}
+	return nil
+}
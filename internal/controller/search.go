@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/solsteace/misite/internal/component/page"
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/search"
+	"github.com/solsteace/misite/internal/utility/api"
+)
+
+// Entries shown of each kind on the global search
+const sEARCH_PREVIEW_SIZE = 5
+
+func (c Controller) Search(w http.ResponseWriter, r *http.Request) error {
+	currentURL, err := url.Parse(r.Header.Get("Hx-Current-URL"))
+	if err != nil {
+		return fmt.Errorf("controller.Search: %w", err)
+	}
+	urlQuery := r.URL.Query()
+
+	searchQuery := urlQuery.Get("search")
+	searchErr := ""
+	result := entity.SearchPage{}
+	if strings.TrimSpace(searchQuery) != "" {
+		param, err := search.Everything(searchQuery)
+		if msg, ok := searchError(err); ok {
+			searchErr = msg
+			w.WriteHeader(http.StatusBadRequest)
+		} else if err != nil {
+			return fmt.Errorf("controller.Search: %w", err)
+		} else {
+			param.Limit = sEARCH_PREVIEW_SIZE
+			if result, err = c.service.Search(r.Context(), param); err != nil {
+				return fmt.Errorf("controller.Search: %w", err)
+			}
+		}
+	}
+
+	var pageComponent templ.Component
+	shouldFullRender := (currentURL.Path != api.Search || // from outside of the page
+		!urlQuery.Has("search")) // calling self via topbar
+	if shouldFullRender {
+		pageComponent = page.Search(searchQuery, result, searchErr)
+	} else {
+		pageComponent = page.SearchResults(searchQuery, result, searchErr)
+	}
+	meta := c.pageMeta(
+		r, "Search", fmt.Sprintf("Search through %s", c.siteName), r.URL.Path, "SearchResultsPage")
+	meta.NoIndex = true
+
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
+		return fmt.Errorf("controller.Search: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/api"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+	"github.com/solsteace/misite/internal/utility/lib/sitemap"
+)
+
+const sitemapContentType = "application/xml; charset=utf-8"
+
+// The whole sitemap, or an index of its parts when it doesn't fit in one
+func (c Controller) Sitemap(w http.ResponseWriter, r *http.Request) error {
+	sitemaps, err := c.sitemaps(r)
+	if err != nil {
+		return fmt.Errorf("controller.Sitemap: %w", err)
+	}
+
+	var body bytes.Buffer
+	var urls []sitemap.Url
+	if len(sitemaps) == 1 {
+		urls = sitemaps[0]
+		err = sitemap.WriteUrlSet(&body, urls)
+	} else {
+		for idx, s := range sitemaps {
+			urls = append(urls, sitemap.Url{
+				Loc:     fmt.Sprintf("%s/sitemap-%d.xml", c.siteRoot(r), idx+1),
+				LastMod: sitemap.LastMod(s)})
+		}
+		err = sitemap.WriteIndex(&body, urls)
+	}
+	if err != nil {
+		return fmt.Errorf("controller.Sitemap: %w", err)
+	}
+	serveGenerated(w, r, sitemapContentType, sitemap.LastMod(urls), body.Bytes())
+	return nil
+}
+
+// A part of the sitemap listed by its index, numbered from 1
+func (c Controller) SitemapPart(w http.ResponseWriter, r *http.Request) error {
+	part, err := strconv.Atoi(chi.URLParam(r, "part"))
+	if err != nil {
+		return fmt.Errorf("controller.SitemapPart: %w", oops.NotFound{Err: err})
+	}
+	sitemaps, err := c.sitemaps(r)
+	if err != nil {
+		return fmt.Errorf("controller.SitemapPart: %w", err)
+	} else if len(sitemaps) == 1 || part < 1 || part > len(sitemaps) {
+		return fmt.Errorf("controller.SitemapPart: %w", oops.NotFound{})
+	}
+
+	var body bytes.Buffer
+	urls := sitemaps[part-1]
+	if err := sitemap.WriteUrlSet(&body, urls); err != nil {
+		return fmt.Errorf("controller.SitemapPart: %w", err)
+	}
+	serveGenerated(w, r, sitemapContentType, sitemap.LastMod(urls), body.Bytes())
+	return nil
+}
+
+// Every page of the site split into sitemaps. The exploration pages are
+// updated along with the latest entry they list
+func (c Controller) sitemaps(r *http.Request) ([][]sitemap.Url, error) {
+	entries, err := c.service.Sitemap(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("controller.sitemaps: %w", err)
+	}
+
+	root := c.siteRoot(r)
+	explorations := map[entity.SitemapKind]*sitemap.Url{
+		entity.SitemapArticle: {Loc: root + api.ExploreArticleUrl},
+		entity.SitemapProject: {Loc: root + api.ExploreProjectUrl},
+		entity.SitemapSerie:   {Loc: root + api.ExploreSeriesUrl}}
+	var entryPages []sitemap.Url
+	for _, e := range entries {
+		var loc string
+		switch e.Kind {
+		case entity.SitemapArticle, entity.SitemapProject, entity.SitemapSerie:
+			loc = fmt.Sprintf("/%s/%s", e.Kind, e.Ref)
+			if page := explorations[e.Kind]; e.UpdatedAt.After(page.LastMod) {
+				page.LastMod = e.UpdatedAt
+			}
+		case entity.SitemapArticleTag:
+			loc = tagSearchUrl(api.ExploreArticleUrl, e.Ref)
+		case entity.SitemapProjectTag:
+			loc = tagSearchUrl(api.ExploreProjectUrl, e.Ref)
+		default:
+			continue
+		}
+		entryPages = append(entryPages, sitemap.Url{Loc: root + loc, LastMod: e.UpdatedAt})
+	}
+
+	pages := []sitemap.Url{
+		{Loc: root + "/"},
+		*explorations[entity.SitemapArticle],
+		*explorations[entity.SitemapProject],
+		*explorations[entity.SitemapSerie]}
+	pages = append(pages, entryPages...)
+	return sitemap.Split(pages), nil
+}
+
+// Crawling rules, pointing crawlers to the sitemap
+func (c Controller) Robots(w http.ResponseWriter, r *http.Request) error {
+	rules := strings.TrimRight(c.robotsRules, "\n")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := fmt.Fprintf(w, "%s\n\nSitemap: %s/sitemap.xml\n", rules, c.siteRoot(r)); err != nil {
+		return fmt.Errorf("controller.Robots: %w", err)
+	}
+	return nil
+}
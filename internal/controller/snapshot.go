@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+// Writes what's stored into `dir` as the data files `Service.Seed` and the
+// `cmd/crud` handlers take, named after the entity they hold. The contents of
+// articles and projects go to `articles/<slug>.html` and
+// `projects/<slug>.html`, referred to by a path starting with `dir`. Entities
+// without any row get no file. Existing files are overwritten
+func (c Controller) Export(ctx context.Context, dir string) error {
+	snapshot, err := c.service.Snapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("controller<Controller.Export>: %w", err)
+	}
+
+	for sub, n := range map[string]int{
+		"articles": len(snapshot.Articles),
+		"projects": len(snapshot.Projects),
+	} {
+		if n == 0 {
+			continue
+		}
+		if err := os.MkdirAll(path.Join(dir, sub), 0o755); err != nil {
+			return fmt.Errorf("controller<Controller.Export>: %w", err)
+		}
+	}
+	for idx, a := range snapshot.Articles {
+		file := path.Join(dir, "articles", a.Slug+".html")
+		if err := os.WriteFile(file, []byte(a.Content), 0o644); err != nil {
+			return fmt.Errorf("controller<Controller.Export>: %w", err)
+		}
+
+		// Tags go along with their ids in their own file, while the serie is
+		// set even when there's none, so the export overrides where it lands
+		a.Content = file
+		a.Tags = nil
+		if a.Serie == nil {
+			a.Serie = &entity.WriteSeriePart{}
+		}
+		snapshot.Articles[idx] = a
+	}
+	for idx, p := range snapshot.Projects {
+		file := path.Join(dir, "projects", p.Slug+".html")
+		if err := os.WriteFile(file, []byte(p.Description), 0o644); err != nil {
+			return fmt.Errorf("controller<Controller.Export>: %w", err)
+		}
+
+		p.Description = file
+		p.Tags = nil
+		if p.Serie == nil {
+			p.Serie = &entity.WriteSeriePart{}
+		}
+		snapshot.Projects[idx] = p
+	}
+
+	files := []struct {
+		name string
+		rows any
+		n    int
+	}{
+		{"writeSerie.json", snapshot.Series, len(snapshot.Series)},
+		{"writeTag.json", snapshot.Tags, len(snapshot.Tags)},
+		{"writeArticle.json", snapshot.Articles, len(snapshot.Articles)},
+		{"writeArticleTag.json", snapshot.ArticleTags, len(snapshot.ArticleTags)},
+		{"writeProject.json", snapshot.Projects, len(snapshot.Projects)},
+		{"writeProjectTag.json", snapshot.ProjectTags, len(snapshot.ProjectTags)},
+		{"writeProjectLink.json", snapshot.ProjectLinks, len(snapshot.ProjectLinks)}}
+	for _, f := range files {
+		if f.n == 0 {
+			continue
+		}
+		if err := writeData(path.Join(dir, f.name), f.rows); err != nil {
+			return fmt.Errorf("controller<Controller.Export>: %w", err)
+		}
+	}
+	return nil
+}
+
+// Writes `rows` in the `{"data": [...]}` layout the handlers decode
+func writeData(file string, rows any) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(struct {
+		Data any `json:"data"`
+	}{rows}); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -1,101 +1,186 @@
 package controller
 
 import (
-	"context"
-	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/solsteace/misite/internal/component/page"
+	"github.com/solsteace/misite/internal/entity"
 	"github.com/solsteace/misite/internal/persistence"
+	"golang.org/x/sync/errgroup"
 )
 
 func (c Controller) Article(w http.ResponseWriter, r *http.Request) error {
-	articleId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, strconv.IntSize)
+	articleId, found, err := c.resolve(w, r, entity.SlugArticle)
 	if err != nil {
-		if errors.Is(err, strconv.ErrSyntax) {
-			articleId = -1
-		} else {
-			return fmt.Errorf("controller.Article: %w", err)
-		}
+		return fmt.Errorf("controller.Article: %w", err)
+	} else if !found {
+		return nil
 	}
 
-	article, err := c.service.Article(int(articleId))
+	var article entity.ArticlePage
+	var part entity.SeriePart
+	group, ctx := errgroup.WithContext(r.Context())
+	group.Go(func() (err error) {
+		article, err = c.service.Article(ctx, articleId)
+		return err
+	})
+	group.Go(func() (err error) {
+		part, err = c.service.ArticleSeriePart(ctx, articleId)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return fmt.Errorf("controller.Article: %w", err)
+	}
+	related, err := c.service.RelatedArticles(r.Context(), article)
 	if err != nil {
 		return fmt.Errorf("controller.Article: %w", err)
 	}
 
-	pageComponent := page.Article(article)
-	if !c.isAppRequest(r) {
-		if err := c.serveWithBase(pageComponent, w, r); err != nil {
-			return fmt.Errorf("controller.Article: %w", err)
-		}
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
+	pageComponent := page.Article(article, part, related)
+	meta := c.articleMeta(r, article)
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
 		return fmt.Errorf("controller.Article: %w", err)
 	}
 	return nil
 }
 
 func (c Controller) Project(w http.ResponseWriter, r *http.Request) error {
-	projectId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, strconv.IntSize)
+	projectId, found, err := c.resolve(w, r, entity.SlugProject)
 	if err != nil {
-		if errors.Is(err, strconv.ErrSyntax) {
-			projectId = -1
-		} else {
-			return fmt.Errorf("controller.Project: %w", err)
-		}
+		return fmt.Errorf("controller.Project: %w", err)
+	} else if !found {
+		return nil
 	}
 
-	project, err := c.service.Project(int(projectId))
+	project, err := c.service.Project(r.Context(), projectId)
+	if err != nil {
+		return fmt.Errorf("controller.Project: %w", err)
+	}
+	related, err := c.service.RelatedProjects(r.Context(), project)
 	if err != nil {
 		return fmt.Errorf("controller.Project: %w", err)
 	}
 
-	pageComponent := page.Project(project)
-	if !c.isAppRequest(r) {
-		if err := c.serveWithBase(pageComponent, w, r); err != nil {
-			return fmt.Errorf("controller.Project: %w", err)
-		}
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
+	pageComponent := page.Project(project, related)
+	meta := c.projectMeta(r, project)
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
 		return fmt.Errorf("controller.Project: %w", err)
 	}
 	return nil
 }
 
 func (c Controller) Serie(w http.ResponseWriter, r *http.Request) error {
-	serieId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, strconv.IntSize)
+	serieId, found, err := c.resolve(w, r, entity.SlugSerie)
+	if err != nil {
+		return fmt.Errorf("controller.Serie: %w", err)
+	} else if !found {
+		return nil
+	}
+
+	// Lists the articles from the first part on, rather than the latest
+	inReadingOrder := r.URL.Query().Get("order") == sERIE_READING_ORDER
+	serieContentParam := persistence.SerieContentQueryParam{
+		Limit:     sERIE_PAGE_SIZE,
+		Ascending: inReadingOrder}
+
+	var serie entity.SeriePage
+	var contents []entity.SerieContentEntry
+	var serieArticles []entity.SeriePageArticleList
+	var serieProjects []entity.SeriePageProjectList
+	group, ctx := errgroup.WithContext(r.Context())
+	group.Go(func() (err error) {
+		serie, err = c.service.Serie(ctx, serieId)
+		return err
+	})
+	if inReadingOrder {
+		group.Go(func() (err error) {
+			contents, err = c.service.SerieContents(ctx, serieId)
+			return err
+		})
+	}
+	group.Go(func() (err error) {
+		serieArticles, err = c.service.SerieArticleList(ctx, serieId, serieContentParam)
+		return err
+	})
+	group.Go(func() (err error) {
+		serieProjects, err = c.service.SerieProjectList(ctx, serieId, serieContentParam)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return fmt.Errorf("controller.Serie: %w", err)
+	}
+
+	pageComponent := page.Serie(serie, serieArticles, serieProjects, contents, inReadingOrder)
+	meta := c.serieMeta(r, serie, serieArticles)
+	if err := c.servePage(pageComponent, meta, w, r); err != nil {
+		return fmt.Errorf("controller.Serie: %w", err)
+	}
+	return nil
+}
+
+// Articles of a serie following the `last` one, for the serie page to load more
+func (c Controller) SerieArticles(w http.ResponseWriter, r *http.Request) error {
+	serieId, found, err := c.resolve(w, r, entity.SlugSerie)
 	if err != nil {
-		if errors.Is(err, strconv.ErrSyntax) {
-			serieId = -1
-		} else {
-			return fmt.Errorf("controller.Serie: %w", err)
-		}
+		return fmt.Errorf("controller.SerieArticles: %w", err)
+	} else if !found {
+		return nil
+	}
+	if !c.isAppRequest(r) { // nothing to append to
+		http.Redirect(w, r, "/serie/"+chi.URLParam(r, "ref"), http.StatusSeeOther)
+		return nil
 	}
 
-	// TODO: use workers
-	serieContentParam := persistence.SerieContentQueryParam{Page: 1, Limit: 10}
-	serie, err := c.service.Serie(int(serieId))
+	urlQuery := r.URL.Query()
+	inReadingOrder := urlQuery.Get("order") == sERIE_READING_ORDER
+	serie, err := c.service.Serie(r.Context(), serieId)
 	if err != nil {
-		return fmt.Errorf("controller<Controller.Serie>; %w", err)
+		return fmt.Errorf("controller.SerieArticles: %w", err)
 	}
-	serieProjects, err := c.service.SerieProjectList(serie.Id, serieContentParam)
+	serieArticles, err := c.service.SerieArticleList(r.Context(), serie.Id, persistence.SerieContentQueryParam{
+		Last:      urlQuery.Get("last"),
+		Limit:     sERIE_PAGE_SIZE,
+		Ascending: inReadingOrder})
 	if err != nil {
-		return fmt.Errorf("controller<Controller.Serie>; %w", err)
+		return fmt.Errorf("controller.SerieArticles: %w", err)
 	}
-	serieArticles, err := c.service.SerieArticleList(serie.Id, serieContentParam)
+
+	pageComponent := page.SerieArticleEntries(serie, serieArticles, inReadingOrder, true)
+	if err := pageComponent.Render(r.Context(), w); err != nil {
+		return fmt.Errorf("controller.SerieArticles: %w", err)
+	}
+	return nil
+}
+
+// Projects of a serie following the `last` one, for the serie page to load more
+func (c Controller) SerieProjects(w http.ResponseWriter, r *http.Request) error {
+	serieId, found, err := c.resolve(w, r, entity.SlugSerie)
 	if err != nil {
-		return fmt.Errorf("controller<Controller.Serie>; %w", err)
+		return fmt.Errorf("controller.SerieProjects: %w", err)
+	} else if !found {
+		return nil
+	}
+	if !c.isAppRequest(r) { // nothing to append to
+		http.Redirect(w, r, "/serie/"+chi.URLParam(r, "ref"), http.StatusSeeOther)
+		return nil
 	}
 
-	pageComponent := page.Serie(serie, serieArticles, serieProjects)
-	if !c.isAppRequest(r) {
-		if err := c.serveWithBase(pageComponent, w, r); err != nil {
-			return fmt.Errorf("controller.Serie: %w", err)
-		}
-	} else if err := pageComponent.Render(context.Background(), w); err != nil {
-		return fmt.Errorf("controller.Serie: %w", err)
+	serie, err := c.service.Serie(r.Context(), serieId)
+	if err != nil {
+		return fmt.Errorf("controller.SerieProjects: %w", err)
+	}
+	serieProjects, err := c.service.SerieProjectList(r.Context(), serie.Id, persistence.SerieContentQueryParam{
+		Last:  r.URL.Query().Get("last"),
+		Limit: sERIE_PAGE_SIZE})
+	if err != nil {
+		return fmt.Errorf("controller.SerieProjects: %w", err)
+	}
+
+	pageComponent := page.SerieProjectEntries(serie, serieProjects, true)
+	if err := pageComponent.Render(r.Context(), w); err != nil {
+		return fmt.Errorf("controller.SerieProjects: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+// Makes the site match the content directory `dir`, laid out as
+//
+//	series/<slug>/serie.json
+//	articles/<slug>/index.md (or index.html), with an optional article.json
+//	projects/<slug>/index.md (or index.html), with an optional project.json
+//
+// The metadata files take the fields the `cmd/crud` data files do. Slugs are
+// given by the folders. Gives the changes made
+func (c Controller) Sync(ctx context.Context, dir string) ([]entity.Change, error) {
+	var source entity.SyncSource
+
+	series, err := syncFolders(path.Join(dir, "series"))
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+	}
+	for _, folder := range series {
+		var sr entity.WriteSerie
+		if err := readMeta(path.Join(dir, "series", folder, "serie.json"), true, &sr); err != nil {
+			return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+		}
+		sr.Slug = folder
+		source.Series = append(source.Series, sr)
+	}
+
+	articles, err := syncFolders(path.Join(dir, "articles"))
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+	}
+	for _, folder := range articles {
+		entryDir := path.Join(dir, "articles", folder)
+		var a entity.WriteArticle
+		if err := readMeta(path.Join(entryDir, "article.json"), false, &a); err != nil {
+			return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+		}
+		if a.Content, err = contentFile(entryDir); err != nil {
+			return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+		}
+		a.Slug = folder
+		source.Articles = append(source.Articles, a)
+	}
+
+	projects, err := syncFolders(path.Join(dir, "projects"))
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+	}
+	for _, folder := range projects {
+		entryDir := path.Join(dir, "projects", folder)
+		var p entity.WriteProject
+		if err := readMeta(path.Join(entryDir, "project.json"), false, &p); err != nil {
+			return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+		}
+		if p.Description, err = contentFile(entryDir); err != nil {
+			return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+		}
+		p.Slug = folder
+		source.Projects = append(source.Projects, p)
+	}
+
+	changes, err := c.service.Sync(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("controller<Controller.Sync>: %w", err)
+	}
+	return changes, nil
+}
+
+// Names of the folders in `dir`, in order. A missing `dir` holds none
+func syncFolders(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var folders []string
+	for _, e := range entries {
+		if e.IsDir() {
+			folders = append(folders, e.Name())
+		}
+	}
+	return folders, nil
+}
+
+func readMeta(file string, required bool, v any) error {
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
+
+// The source of an article or a project, which is either written in Markdown
+// or HTML
+func contentFile(dir string) (string, error) {
+	var found string
+	for _, name := range []string{"index.md", "index.html"} {
+		file := path.Join(dir, name)
+		if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		if found != "" {
+			return "", fmt.Errorf("%s: both index.md and index.html are given", dir)
+		}
+		found = file
+	}
+	if found == "" {
+		return "", fmt.Errorf("%s: missing index.md or index.html", dir)
+	}
+	return found, nil
+}
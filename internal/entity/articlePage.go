@@ -8,6 +8,7 @@ import (
 // The model to show an article on its specification page
 type ArticlePage struct {
 	Id        int
+	Slug      string
 	Title     string
 	Subtitle  string
 	Content   string
@@ -17,6 +18,7 @@ type ArticlePage struct {
 	// an article series that accompanies the project, if any (some kind of devblog, if you will)
 	Serie *struct {
 		Id   int
+		Slug string
 		Name string
 	}
 
@@ -58,14 +60,23 @@ func (a ArticlePage) DisplayTime() string {
 
 type ArticleListPage struct {
 	Id        int
+	Slug      string
 	Title     string
 	Subtitle  string
+	Content   string // only fetched when asked for, as it's not shown on listings
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
+	// how well the entry matches the searched keywords, zero when there's none
+	Relevance float32
+
+	// position of the entry within its listing, to carry on after it
+	Cursor string
+
 	// an article series that accompanies the project, if any (some kind of devblog, if you will)
 	Serie *struct {
 		Id   int
+		Slug string
 		Name string
 	}
 
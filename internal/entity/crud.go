@@ -1,10 +1,45 @@
 package entity
 
+import "time"
+
 type WriteArticle struct {
 	Id       int    `json:"id"`
 	Title    string `json:"title"`
 	Subtitle string `json:"subtitle"`
-	Content  string `json:"content"` // path to HTML file containing the content
+	Content  string `json:"content"` // path to HTML or Markdown file containing the content
+	Message  string `json:"message"` // describes the change, kept along with the revision
+
+	// made from the title when left empty on insert, and kept as it is when
+	// left empty on update. A replaced slug redirects to the new one
+	Slug string `json:"slug"`
+
+	// published right away when both are left empty, or scheduled when only
+	// `PublishAt` is given
+	State     PublicationState `json:"state"`
+	PublishAt time.Time        `json:"publish_at"`
+
+	// kept as they are when left out. Tags are named, and made when missing
+	Thumbnail *string         `json:"thumbnail"`
+	Serie     *WriteSeriePart `json:"serie"`
+	Tags      []string        `json:"tags"`
+
+	// `Serie` laid flat, taken when it's left out. A zero id takes the
+	// article out of its serie
+	SerieId    *int `json:"serie_id"`
+	SerieOrder int  `json:"serie_order"`
+
+	// set to the time of the write when left out, except for `CreatedAt` on
+	// update. Exports give them, so entries carry over their dates
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Places an entry within the serie of the id, or else of the slug. Out of
+// its serie when both are left empty
+type WriteSeriePart struct {
+	Id    int    `json:"id"`
+	Slug  string `json:"slug"`
+	Order int    `json:"order"` // only for articles
 }
 
 type WriteArticleTag struct {
@@ -17,7 +52,23 @@ type WriteProject struct {
 	Id          int    `json:"id"`
 	Name        string `json:"name"`
 	Synopsis    string `json:"synopsis"`
-	Description string `json:"description"` // path to HTML file containing the content
+	Description string `json:"description"` // path to HTML or Markdown file containing the content
+	Message     string `json:"message"`     // describes the change, kept along with the revision
+	Slug        string `json:"slug"`        // like `WriteArticle.Slug`, made from the name
+
+	State     PublicationState `json:"state"` // like `WriteArticle.State`
+	PublishAt time.Time        `json:"publish_at"`
+
+	Thumbnail *string         `json:"thumbnail"` // like `WriteArticle.Thumbnail`
+	Serie     *WriteSeriePart `json:"serie"`     // its devblog, like `WriteArticle.Serie`
+	Tags      []string        `json:"tags"`      // like `WriteArticle.Tags`
+
+	// kept as they are when left out, else the project links to exactly
+	// these, matched by their url. Their ids are ignored
+	Links []WriteProjectLink `json:"links"`
+
+	CreatedAt time.Time `json:"created_at"` // like `WriteArticle.CreatedAt`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type WriteProjectTag struct {
@@ -43,6 +94,19 @@ type WriteSerie struct {
 	Name        string `json:"name"`
 	Thumbnail   string `json:"thumbnail"`
 	Description string `json:"description"`
+	Slug        string `json:"slug"` // like `WriteArticle.Slug`, made from the name
+
+	State     PublicationState `json:"state"` // like `WriteArticle.State`
+	PublishAt time.Time        `json:"publish_at"`
+
+	CreatedAt time.Time `json:"created_at"` // like `WriteArticle.CreatedAt`
+}
+
+// Restores the title and content of an article as they were in a revision
+type RollbackArticle struct {
+	ArticleId  int    `json:"article_id"`
+	RevisionId int    `json:"revision_id"`
+	Message    string `json:"message"`
 }
 
 type DeleteById struct {
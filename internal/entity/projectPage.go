@@ -8,6 +8,7 @@ import (
 // The model to show a project on its specification page
 type ProjectPage struct {
 	Id          int
+	Slug        string
 	Name        string
 	Synopsis    string
 	Description string
@@ -17,6 +18,7 @@ type ProjectPage struct {
 	// an article serie that accompanies the project, if any (some kind of devblog, if you will)
 	Serie *struct {
 		Id   int
+		Slug string
 		Name string
 	}
 	// the associated tags
@@ -64,14 +66,22 @@ func (p ProjectPage) DisplayTime() string {
 
 type ProjectListPage struct {
 	Id        int
+	Slug      string
 	Name      string
 	Synopsis  string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
+	// how well the entry matches the searched keywords, zero when there's none
+	Relevance float32
+
+	// position of the entry within its listing, to carry on after it
+	Cursor string
+
 	// an article serie that accompanies the project, if any (some kind of devblog, if you will)
 	Serie *struct {
 		Id   int
+		Slug string
 		Name string
 	}
 
@@ -0,0 +1,19 @@
+package entity
+
+// Whether an article, project or serie is shown to the public
+type PublicationState string
+
+const (
+	StateDraft     PublicationState = "draft"
+	StateScheduled PublicationState = "scheduled" // goes live at its `publish_at`
+	StatePublished PublicationState = "published"
+	StateArchived  PublicationState = "archived" // unlisted, but still reachable through its link
+)
+
+func (s PublicationState) Valid() bool {
+	switch s {
+	case StateDraft, StateScheduled, StatePublished, StateArchived:
+		return true
+	}
+	return false
+}
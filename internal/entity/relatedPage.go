@@ -0,0 +1,20 @@
+package entity
+
+// Another entry sharing tags or the serie with the one being shown
+type RelatedEntry struct {
+	Id       int
+	Slug     string
+	Title    string // title of an article, or name of a project
+	Synopsis string
+
+	SharedTags int  // tags attached to both entries
+	SameSerie  bool // whether both entries belong to the same serie
+}
+
+// The related panel of an article or project page
+type RelatedPage struct {
+	Entries []RelatedEntry
+
+	// tags of the shown entry, along with how many entries of its kind have them
+	Tag []TagStatPage
+}
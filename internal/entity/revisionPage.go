@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/solsteace/misite/internal/utility/lib/diff"
+)
+
+// A recorded version of the content of an article or project
+type Revision struct {
+	Id        int
+	Title     string // title of an article, or name of a project
+	Content   string // left empty when listing revisions
+	Message   string
+	CreatedAt time.Time
+}
+
+// The revisions of an article, the latest first
+type ArticleHistoryPage struct {
+	Id        int
+	Slug      string
+	Title     string
+	Revisions []Revision
+}
+
+// Changes between two revisions of an article, word by word. The content
+// is compared without its markup
+type ArticleDiffPage struct {
+	History ArticleHistoryPage // to pick other revisions to compare
+	From    Revision
+	To      Revision
+
+	TitleChanges   []diff.Chunk
+	ContentChanges []diff.Chunk
+}
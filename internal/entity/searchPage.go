@@ -0,0 +1,17 @@
+package entity
+
+// The model to show the global search, previewing each kind of entry
+// alongside how many of them matched
+type SearchPage struct {
+	Articles []ArticleListPage
+	NArticle int
+
+	Projects []ProjectListPage
+	NProject int
+
+	Series []SerieListPage
+	NSerie int
+
+	Tags []TagListPage
+	NTag int
+}
@@ -5,6 +5,7 @@ import "time"
 // The model for viewing `serie` entry in `serie` page
 type SeriePage struct {
 	Id          int
+	Slug        string
 	NArticle    int
 	NProject    int
 	Name        string
@@ -16,15 +17,41 @@ type SeriePage struct {
 // ascending order by their appearance on the serie
 type SeriePageArticleList struct {
 	Id        int
+	Slug      string
+	Part      int // 1-based, following the serie order
+	Nth       int // 1-based position within the listing
+	Cursor    string
 	Title     string
 	Synopsis  string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// An entry of the table of contents of a serie
+type SerieContentEntry struct {
+	Id    int
+	Slug  string
+	Part  int // 1-based, following the serie order
+	Title string
+}
+
+// Where an article stands within its serie. The zero value is for articles
+// without a serie
+type SeriePart struct {
+	Part  int // 1-based, following the serie order
+	NPart int
+
+	// the neighbouring parts, if any
+	Previous *SerieContentEntry
+	Next     *SerieContentEntry
+}
+
 // Project associated with the serie
 type SeriePageProjectList struct {
 	Id        int
+	Slug      string
+	Nth       int // 1-based position within the listing
+	Cursor    string
 	Name      string
 	Synopsis  string
 	CreatedAt time.Time
@@ -34,9 +61,13 @@ type SeriePageProjectList struct {
 // The model for viewing `serie` entry in `serie_list` page
 type SerieListPage struct {
 	Id          int
+	Slug        string
 	Name        string
 	Description string
 	CreatedAt   time.Time
+
+	// position of the entry within its listing, to carry on after it
+	Cursor string
 }
 
 // A serie entry is considered new for 5 days after its initial creation
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// The kinds of pages listed on the sitemap. Tags are listed through the
+// searches of the articles or projects having them
+type SitemapKind string
+
+const (
+	SitemapArticle    SitemapKind = "article"
+	SitemapProject    SitemapKind = "project"
+	SitemapSerie      SitemapKind = "serie"
+	SitemapArticleTag SitemapKind = "article_tag"
+	SitemapProjectTag SitemapKind = "project_tag"
+)
+
+type SitemapEntry struct {
+	Kind      SitemapKind
+	Ref       string    // slug of the entry, or name of the tag
+	UpdatedAt time.Time // latest update of the page's content
+}
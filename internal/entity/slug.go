@@ -0,0 +1,10 @@
+package entity
+
+// The kinds of entries reached through a slug, named after their routes
+type SlugKind string
+
+const (
+	SlugArticle SlugKind = "article"
+	SlugProject SlugKind = "project"
+	SlugSerie   SlugKind = "serie"
+)
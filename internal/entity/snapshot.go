@@ -0,0 +1,14 @@
+package entity
+
+// Everything written through `cmd/crud`, as it's currently stored. Articles
+// and projects hold their content itself rather than a path to it, along
+// with the serie they belong to and the names of their tags
+type Snapshot struct {
+	Series       []WriteSerie
+	Articles     []WriteArticle
+	Projects     []WriteProject
+	Tags         []WriteTag
+	ArticleTags  []WriteArticleTag
+	ProjectTags  []WriteProjectTag
+	ProjectLinks []WriteProjectLink
+}
@@ -0,0 +1,32 @@
+package entity
+
+// What a content directory describes, keyed by slugs. Every entry is
+// described whole: anything left out of it is left out of the site too
+type SyncSource struct {
+	Series   []WriteSerie
+	Articles []WriteArticle
+	Projects []WriteProject // along with their links
+}
+
+type ChangeAction string
+
+const (
+	ChangeInsert ChangeAction = "insert"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+)
+
+// A change made to the site, or which a dry run would have made
+type Change struct {
+	Entity string // the table, in singular: `serie`, `article`, `project_link`...
+	Ref    string // slug or name of the entry, else what tells it apart
+	Action ChangeAction
+	Fields []FieldChange // the ones that changed, on update
+}
+
+// Values are written the way they're shown, lists being joined by commas
+type FieldChange struct {
+	Name   string
+	Before string
+	After  string
+}
@@ -10,3 +10,11 @@ type TagStatPage struct {
 	Count int
 	Name  string
 }
+
+type TagListPage struct {
+	Id   int
+	Name string
+
+	NArticle int // articles having the tag
+	NProject int // projects having the tag
+}
@@ -0,0 +1,240 @@
+package migration
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Same bookkeeping table as goose, so databases migrated with it carry on
+const versionTable = "goose_db_version"
+
+// Applies and reverts migrations on a database
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration // sorted by version
+}
+
+func NewMigrator(db *sqlx.DB, migrations []Migration) Migrator {
+	return Migrator{
+		db:         db,
+		migrations: migrations}
+}
+
+// State of a migration on the database
+type Status struct {
+	Migration Migration
+	AppliedAt *time.Time // nil when the migration is still pending
+}
+
+// The version the schema should be at to work with the code
+func (m Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// The version of the latest applied migration, 0 when none is
+func (m Migrator) Version() (int64, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, fmt.Errorf("migration<Migrator.Version>: %w", err)
+	}
+
+	var version int64
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Errors when a migration known to the code hasn't been applied, which
+// includes ones older than the latest applied migration
+func (m Migrator) Verify() error {
+	applied, err := m.applied()
+	if err != nil {
+		return fmt.Errorf("migration<Migrator.Verify>: %w", err)
+	}
+
+	pending := []string{}
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; !ok {
+			pending = append(pending, mg.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf(
+			"migration<Migrator.Verify>: %d migration(s) not applied: %s",
+			len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Applies every pending migration in order, returning the applied ones
+func (m Migrator) Up() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return []Migration{}, fmt.Errorf("migration<Migrator.Up>: %w", err)
+	}
+
+	done := []Migration{}
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok {
+			continue
+		}
+		if err := m.run(mg, mg.Up, true); err != nil {
+			return done, fmt.Errorf("migration<Migrator.Up>: %s: %w", mg.Name, err)
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// Reverts the latest applied migration
+func (m Migrator) Down() (Migration, error) {
+	mg, err := m.current()
+	if err != nil {
+		return Migration{}, fmt.Errorf("migration<Migrator.Down>: %w", err)
+	}
+	if err := m.run(mg, mg.Down, false); err != nil {
+		return Migration{}, fmt.Errorf("migration<Migrator.Down>: %s: %w", mg.Name, err)
+	}
+	return mg, nil
+}
+
+// Reverts, then re-applies the latest applied migration
+func (m Migrator) Redo() (Migration, error) {
+	mg, err := m.current()
+	if err != nil {
+		return Migration{}, fmt.Errorf("migration<Migrator.Redo>: %w", err)
+	}
+	if err := m.run(mg, mg.Down, false); err != nil {
+		return Migration{}, fmt.Errorf("migration<Migrator.Redo>: %s: %w", mg.Name, err)
+	}
+	if err := m.run(mg, mg.Up, true); err != nil {
+		return Migration{}, fmt.Errorf("migration<Migrator.Redo>: %s: %w", mg.Name, err)
+	}
+	return mg, nil
+}
+
+// Lists every known migration alongside when it was applied
+func (m Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return []Status{}, fmt.Errorf("migration<Migrator.Status>: %w", err)
+	}
+
+	status := []Status{}
+	for _, mg := range m.migrations {
+		s := Status{Migration: mg}
+		if appliedAt, ok := applied[mg.Version]; ok {
+			s.AppliedAt = &appliedAt
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// The latest applied migration that is known to the code
+func (m Migrator) current() (Migration, error) {
+	version, err := m.Version()
+	if err != nil {
+		return Migration{}, err
+	} else if version == 0 {
+		return Migration{}, errors.New("no migration has been applied")
+	}
+	for _, mg := range m.migrations {
+		if mg.Version == version {
+			return mg, nil
+		}
+	}
+	return Migration{}, fmt.Errorf("applied version %d has no migration file", version)
+}
+
+// Runs the statements of a migration and records the outcome
+func (m Migrator) run(mg Migration, stmts []string, isUp bool) error {
+	record := func(ex sqlx.Execer) error {
+		var err error
+		if isUp {
+			_, err = ex.Exec(
+				`INSERT INTO `+versionTable+`(version_id, is_applied) VALUES($1, TRUE)`,
+				mg.Version)
+		} else {
+			_, err = ex.Exec(
+				`DELETE FROM `+versionTable+` WHERE version_id = $1`,
+				mg.Version)
+		}
+		return err
+	}
+
+	if mg.NoTx {
+		for _, s := range stmts {
+			if _, err := m.db.Exec(s); err != nil {
+				return err
+			}
+		}
+		return record(m.db)
+	}
+
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, s := range stmts {
+		if _, err := tx.Exec(s); err != nil {
+			return err
+		}
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Applied versions alongside when they were applied. The bookkeeping table
+// is created when it doesn't exist yet
+func (m Migrator) applied() (map[int64]time.Time, error) {
+	query := `
+		CREATE TABLE IF NOT EXISTS ` + versionTable + `(
+			"id" SERIAL PRIMARY KEY,
+			"version_id" BIGINT NOT NULL,
+			"is_applied" BOOLEAN NOT NULL,
+			"tstamp" TIMESTAMP NOT NULL DEFAULT NOW())`
+	if _, err := m.db.Exec(query); err != nil {
+		return map[int64]time.Time{}, err
+	}
+
+	// Older goose releases kept reverted versions around with
+	// `is_applied = FALSE`, so only the latest record of a version counts
+	var rows []struct {
+		VersionId int64        `db:"version_id"`
+		IsApplied bool         `db:"is_applied"`
+		Tstamp    sql.NullTime `db:"tstamp"`
+	}
+	query = `
+		SELECT version_id, is_applied, tstamp
+		FROM ` + versionTable + `
+		ORDER BY id DESC`
+	if err := m.db.Select(&rows, query); err != nil {
+		return map[int64]time.Time{}, err
+	}
+
+	seen := map[int64]struct{}{}
+	applied := map[int64]time.Time{}
+	for _, r := range rows {
+		if _, ok := seen[r.VersionId]; ok {
+			continue
+		}
+		seen[r.VersionId] = struct{}{}
+		if r.IsApplied && r.VersionId > 0 {
+			applied[r.VersionId] = r.Tstamp.Time
+		}
+	}
+	return applied, nil
+}
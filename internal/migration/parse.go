@@ -0,0 +1,177 @@
+package migration
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A schema change, parsed from a goose-annotated SQL file
+type Migration struct {
+	Version int64
+	Name    string // file name, without the directory
+
+	Up   []string // statements to apply the change
+	Down []string // statements to revert the change
+	NoTx bool     // whether the statements should run outside of a transaction
+}
+
+const (
+	annotationPrefix    = "-- +goose "
+	annotationUp        = "Up"
+	annotationDown      = "Down"
+	annotationStmtBegin = "StatementBegin"
+	annotationStmtEnd   = "StatementEnd"
+	annotationNoTx      = "NO TRANSACTION"
+)
+
+// Reads every `<version>_<name>.sql` file in `dir`, sorted by their version
+func Parse(fsys fs.FS, dir string) ([]Migration, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return []Migration{}, fmt.Errorf("migration<Parse>: %w", err)
+	}
+
+	migrations := []Migration{}
+	for _, file := range files {
+		name := path.Base(file)
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return []Migration{}, fmt.Errorf(
+				"migration<Parse>: `%s` doesn't have a version prefix", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return []Migration{}, fmt.Errorf(
+				"migration<Parse>: `%s` doesn't have a version prefix: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return []Migration{}, fmt.Errorf("migration<Parse>: %w", err)
+		}
+		m, err := parseFile(string(content))
+		if err != nil {
+			return []Migration{}, fmt.Errorf("migration<Parse>: %s: %w", name, err)
+		}
+		m.Version = version
+		m.Name = name
+		migrations = append(migrations, m)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	for idx := 1; idx < len(migrations); idx++ {
+		if migrations[idx].Version == migrations[idx-1].Version {
+			return []Migration{}, fmt.Errorf(
+				"migration<Parse>: `%s` and `%s` share the same version",
+				migrations[idx-1].Name, migrations[idx].Name)
+		}
+	}
+	return migrations, nil
+}
+
+// Splits the file into statements the same way goose does: a statement
+// ends at a line ending with `;`, unless it's enclosed within
+// `StatementBegin` and `StatementEnd` annotations
+func parseFile(content string) (Migration, error) {
+	type section int
+	const (
+		sECTION_NONE section = iota
+		sECTION_UP
+		sECTION_DOWN
+	)
+
+	var m Migration
+	var stmt strings.Builder
+	current := sECTION_NONE
+	inBlock := false
+	flush := func() {
+		if s := strings.TrimSpace(stmt.String()); s != "" && !isCommentOnly(s) {
+			switch current {
+			case sECTION_UP:
+				m.Up = append(m.Up, s)
+			case sECTION_DOWN:
+				m.Down = append(m.Down, s)
+			}
+		}
+		stmt.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if annotation, ok := strings.CutPrefix(trimmed, annotationPrefix); ok {
+			switch strings.TrimSpace(annotation) {
+			case annotationUp, annotationDown:
+				if inBlock {
+					return Migration{}, fmt.Errorf(
+						"line %d: section changed within a statement block", lineNo)
+				} else if s := strings.TrimSpace(stmt.String()); s != "" && !isCommentOnly(s) {
+					return Migration{}, fmt.Errorf(
+						"line %d: unterminated statement before the section change", lineNo)
+				}
+				stmt.Reset()
+				current = sECTION_UP
+				if strings.TrimSpace(annotation) == annotationDown {
+					current = sECTION_DOWN
+				}
+			case annotationStmtBegin:
+				if inBlock {
+					return Migration{}, fmt.Errorf("line %d: nested statement block", lineNo)
+				}
+				flush()
+				inBlock = true
+			case annotationStmtEnd:
+				if !inBlock {
+					return Migration{}, fmt.Errorf("line %d: statement block never began", lineNo)
+				}
+				flush()
+				inBlock = false
+			case annotationNoTx:
+				m.NoTx = true
+			default:
+				return Migration{}, fmt.Errorf(
+					"line %d: unknown annotation `%s`", lineNo, trimmed)
+			}
+			continue
+		}
+
+		if current == sECTION_NONE {
+			continue
+		}
+		stmt.WriteString(line)
+		stmt.WriteString("\n")
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Migration{}, err
+	} else if inBlock {
+		return Migration{}, fmt.Errorf("statement block never ended")
+	} else if s := strings.TrimSpace(stmt.String()); s != "" && !isCommentOnly(s) {
+		return Migration{}, fmt.Errorf("unterminated statement at the end of file")
+	}
+	if current == sECTION_NONE {
+		return Migration{}, fmt.Errorf("`%s%s` annotation not found", annotationPrefix, annotationUp)
+	}
+	return m, nil
+}
+
+func isCommentOnly(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
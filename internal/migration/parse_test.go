@@ -0,0 +1,209 @@
+package migration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Migration
+	}{
+		{
+			name: "up and down",
+			content: "-- +goose Up\n" +
+				"CREATE TABLE a (id INT);\n" +
+				"CREATE TABLE b (\n" +
+				"    id INT\n" +
+				");\n" +
+				"\n" +
+				"-- +goose Down\n" +
+				"DROP TABLE b;\n" +
+				"DROP TABLE a;\n",
+			want: Migration{
+				Up: []string{
+					"CREATE TABLE a (id INT);",
+					"CREATE TABLE b (\n    id INT\n);"},
+				Down: []string{"DROP TABLE b;", "DROP TABLE a;"}}},
+		{
+			name: "lines before the first section are ignored",
+			content: "-- Adds a table\n" +
+				"-- +goose Up\n" +
+				"CREATE TABLE a (id INT);\n",
+			want: Migration{Up: []string{"CREATE TABLE a (id INT);"}}},
+		{
+			name: "statement block",
+			content: "-- +goose Up\n" +
+				"-- +goose StatementBegin\n" +
+				"DO $$\n" +
+				"BEGIN\n" +
+				"    UPDATE a SET id = 1;\n" +
+				"END $$;\n" +
+				"-- +goose StatementEnd\n" +
+				"SELECT 1;\n",
+			want: Migration{Up: []string{
+				"DO $$\nBEGIN\n    UPDATE a SET id = 1;\nEND $$;",
+				"SELECT 1;"}}},
+		{
+			name: "no transaction",
+			content: "-- +goose NO TRANSACTION\n" +
+				"-- +goose Up\n" +
+				"CREATE INDEX CONCURRENTLY a_id ON a (id);\n",
+			want: Migration{
+				Up:   []string{"CREATE INDEX CONCURRENTLY a_id ON a (id);"},
+				NoTx: true}},
+		{
+			name: "comments only",
+			content: "-- +goose Up\n" +
+				"-- nothing to do\n" +
+				"-- +goose Down\n" +
+				"-- nor here\n",
+			want: Migration{}},
+		{
+			name: "comments within a statement are kept",
+			content: "-- +goose Up\n" +
+				"-- the table\n" +
+				"CREATE TABLE a (id INT);\n",
+			want: Migration{Up: []string{"-- the table\nCREATE TABLE a (id INT);"}}},
+		{
+			name: "CRLF and trailing spaces",
+			content: "  -- +goose Up  \r\n" +
+				"CREATE TABLE a (id INT);  \r\n",
+			want: Migration{Up: []string{"CREATE TABLE a (id INT);"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFile(tt.content)
+			if err != nil {
+				t.Fatalf("parseFile failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFile\n got: %#v\nwant: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		msg     string // part of the error
+	}{
+		{
+			name:    "missing up",
+			content: "CREATE TABLE a (id INT);\n",
+			msg:     "annotation not found"},
+		{
+			name:    "unknown annotation",
+			content: "-- +goose Sideways\n",
+			msg:     "line 1: unknown annotation"},
+		{
+			name: "nested block",
+			content: "-- +goose Up\n" +
+				"-- +goose StatementBegin\n" +
+				"-- +goose StatementBegin\n",
+			msg: "line 3: nested statement block"},
+		{
+			name: "block never began",
+			content: "-- +goose Up\n" +
+				"-- +goose StatementEnd\n",
+			msg: "line 2: statement block never began"},
+		{
+			name: "block never ended",
+			content: "-- +goose Up\n" +
+				"-- +goose StatementBegin\n" +
+				"SELECT 1;\n",
+			msg: "never ended"},
+		{
+			name: "section changed within a block",
+			content: "-- +goose Up\n" +
+				"-- +goose StatementBegin\n" +
+				"-- +goose Down\n",
+			msg: "line 3: section changed"},
+		{
+			name: "unterminated before the down section",
+			content: "-- +goose Up\n" +
+				"SELECT 1\n" +
+				"-- +goose Down\n",
+			msg: "line 3: unterminated statement"},
+		{
+			name: "unterminated at the end",
+			content: "-- +goose Up\n" +
+				"SELECT 1\n",
+			msg: "end of file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFile(tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("expected an error about %q, got %v", tt.msg, err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	up := func(stmt string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte("-- +goose Up\n" + stmt + "\n")}
+	}
+
+	fsys := fstest.MapFS{
+		"migration/20260102000000_second.sql": up("SELECT 2;"),
+		"migration/9_first.sql":               up("SELECT 1;"),
+		"migration/20260103000000_third.sql":  up("SELECT 3;"),
+		"migration/README.md":                 &fstest.MapFile{Data: []byte("not a migration")},
+	}
+	got, err := Parse(fsys, "migration")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	var names []string
+	for _, m := range got {
+		names = append(names, m.Name)
+	}
+	want := []string{"9_first.sql", "20260102000000_second.sql", "20260103000000_third.sql"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+	if got[0].Version != 9 || !reflect.DeepEqual(got[0].Up, []string{"SELECT 1;"}) {
+		t.Errorf("unexpected first migration: %+v", got[0])
+	}
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		msg  string // part of the error
+	}{
+		{
+			name: "without version",
+			fsys: fstest.MapFS{"migration/first.sql": up("SELECT 1;")},
+			msg:  "doesn't have a version prefix"},
+		{
+			name: "version isn't a number",
+			fsys: fstest.MapFS{"migration/v1_first.sql": up("SELECT 1;")},
+			msg:  "doesn't have a version prefix"},
+		{
+			name: "shared version",
+			fsys: fstest.MapFS{
+				"migration/1_first.sql": up("SELECT 1;"),
+				"migration/1_again.sql": up("SELECT 1;")},
+			msg: "share the same version"},
+		{
+			name: "malformed file",
+			fsys: fstest.MapFS{"migration/1_first.sql": up("SELECT 1")},
+			msg:  "1_first.sql: unterminated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.fsys, "migration")
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("expected an error about %q, got %v", tt.msg, err)
+			}
+		})
+	}
+}
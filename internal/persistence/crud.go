@@ -1,76 +1,221 @@
 package persistence
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/solsteace/misite/internal/entity"
 )
 
-func (p Pg) InsertArticles(articles []entity.WriteArticle, contents []string) error {
+// Every article is written along with its first revision, hence one
+// statement per article
+func (p Pg) InsertArticles(ctx context.Context, articles []entity.WriteArticle, contents []string) ([]int, error) {
 	query := `
-		INSERT INTO articles(
+		WITH
+			inserted AS (
+				INSERT INTO articles(
+					slug,
+					title,
+					subtitle,
+					content,
+					state,
+					publish_at,
+					created_at,
+					updated_at)
+				VALUES(
+					:slug,
+					:title,
+					:subtitle,
+					:content,
+					:state,
+					:publish_at,
+					COALESCE(CAST(:created_at AS TIMESTAMP), LOCALTIMESTAMP),
+					COALESCE(CAST(:updated_at AS TIMESTAMP), LOCALTIMESTAMP))
+				RETURNING id, slug, title, content),
+			reclaimed AS (
+				DELETE FROM slug_redirects
+				WHERE kind = 'article' AND slug IN (SELECT slug FROM inserted))
+		INSERT INTO article_revisions(
+			article_id,
 			title,
-			subtitle,
-			content)
-		VALUES(
-			:title,
-			:subtitle,
-			:content)`
-	rows := make([]any, len(articles))
+			content,
+			message)
+		SELECT id, title, content, :message
+		FROM inserted
+		RETURNING article_id`
+	ids := make([]int, 0, len(articles))
 	for idx, a := range articles {
-		rows[idx] = struct {
+		slug := a.Slug
+		if slug == "" {
+			var err error
+			slug, err = p.freeSlug(ctx, entity.SlugArticle, a.Title, sql.Null[int]{})
+			if err != nil {
+				return nil, fmt.Errorf("persistence<Pg.InsertArticles>: %w", err)
+			}
+		}
+
+		row := struct {
+			Slug     string `db:"slug"`
 			Title    string `db:"title"`
 			Subtitle string `db:"subtitle"`
 			Content  string `db:"content"`
+			Message  string `db:"message"`
+
+			State     entity.PublicationState `db:"state"`
+			PublishAt sql.Null[time.Time]     `db:"publish_at"`
+			CreatedAt sql.Null[time.Time]     `db:"created_at"`
+			UpdatedAt sql.Null[time.Time]     `db:"updated_at"`
 		}{
+			Slug:     slug,
 			Title:    a.Title,
 			Subtitle: a.Subtitle,
-			Content:  contents[idx]}
-	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
-		return fmt.Errorf("persistence<Pg.InsertArticles>: %w", err)
-	}
-	return nil
+			Content:  contents[idx],
+			Message:  a.Message,
+
+			State:     a.State,
+			PublishAt: nullTime(a.PublishAt),
+			CreatedAt: nullTime(a.CreatedAt),
+			UpdatedAt: nullTime(a.UpdatedAt)}
+		id, err := p.namedId(ctx, query, row)
+		if err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertArticles>: %w", err)
+		}
+		ids = append(ids, id)
+		if err := p.illustrateArticle(ctx, id, a.Thumbnail); err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertArticles>: %w", err)
+		} else if err := p.placeArticle(ctx, id, a.Serie); err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertArticles>: %w", err)
+		} else if err := p.tagArticle(ctx, id, a.Tags); err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertArticles>: %w", err)
+		}
+	}
+	return ids, nil
 }
 
-func (p Pg) UpsertArticles(articles []entity.WriteArticle, contents []string) error {
+// Every write records a new revision of the article, even when nothing
+// changed. A replaced slug is kept to redirect to the new one
+func (p Pg) UpsertArticles(ctx context.Context, articles []entity.WriteArticle, contents []string) error {
 	query := `
-		INSERT INTO articles(
-			id,
+		WITH
+			previous AS (
+				SELECT id, slug FROM articles WHERE id = :id),
+			upserted AS (
+				INSERT INTO articles(
+					id,
+					slug,
+					title,
+					subtitle,
+					content,
+					state,
+					publish_at,
+					created_at,
+					updated_at)
+				VALUES(
+					:id,
+					:slug,
+					:title,
+					:subtitle,
+					:content,
+					:state,
+					:publish_at,
+					COALESCE(CAST(:created_at AS TIMESTAMP), LOCALTIMESTAMP),
+					COALESCE(CAST(:updated_at AS TIMESTAMP), LOCALTIMESTAMP))
+				ON CONFLICT(id)
+				DO UPDATE SET
+					slug = CASE WHEN :keep_slug THEN articles.slug ELSE EXCLUDED.slug END,
+					title = EXCLUDED.title,
+					subtitle = EXCLUDED.subtitle,
+					content = EXCLUDED.content,
+					state = CASE WHEN :keep_publication THEN articles.state ELSE EXCLUDED.state END,
+					publish_at = CASE WHEN :keep_publication THEN articles.publish_at ELSE EXCLUDED.publish_at END,
+					created_at = COALESCE(CAST(:created_at AS TIMESTAMP), articles.created_at),
+					updated_at = EXCLUDED.updated_at
+				RETURNING id, slug, title, content),
+			redirected AS (
+				INSERT INTO slug_redirects(kind, slug, target_id)
+				SELECT 'article', previous.slug, upserted.id
+				FROM previous
+				JOIN upserted ON upserted.id = previous.id
+				WHERE previous.slug <> upserted.slug
+				ON CONFLICT(kind, slug)
+				DO UPDATE SET
+					target_id = EXCLUDED.target_id,
+					created_at = EXCLUDED.created_at),
+			reclaimed AS (
+				DELETE FROM slug_redirects
+				WHERE kind = 'article' AND slug IN (SELECT slug FROM upserted))
+		INSERT INTO article_revisions(
+			article_id,
 			title,
-			subtitle,
-			content)
-		VALUES(
-			:id,
-			:title,
-			:subtitle,
-			:content)
-		ON CONFLICT(id)
-		DO UPDATE SET
-			title = EXCLUDED.title,
-			subtitle = EXCLUDED.subtitle,
-			content = EXCLUDED.content`
-	rows := make([]any, len(articles))
+			content,
+			message)
+		SELECT id, title, content, :message
+		FROM upserted
+		RETURNING article_id`
+	if err := p.unplaceArticles(ctx, articles); err != nil {
+		return fmt.Errorf("persistence<Pg.UpsertArticles>: %w", err)
+	}
 	for idx, a := range articles {
-		rows[idx] = struct {
+		slug := a.Slug
+		if slug == "" {
+			var err error
+			slug, err = p.freeSlug(ctx, entity.SlugArticle, a.Title, sql.Null[int]{V: a.Id, Valid: true})
+			if err != nil {
+				return fmt.Errorf("persistence<Pg.UpsertArticles>: %w", err)
+			}
+		}
+
+		state, keepPublication := upsertedState(a.State)
+		row := struct {
 			Id       int    `db:"id"`
+			Slug     string `db:"slug"`
+			KeepSlug bool   `db:"keep_slug"`
 			Title    string `db:"title"`
 			Subtitle string `db:"subtitle"`
 			Content  string `db:"content"`
+			Message  string `db:"message"`
+
+			State           entity.PublicationState `db:"state"`
+			PublishAt       sql.Null[time.Time]     `db:"publish_at"`
+			KeepPublication bool                    `db:"keep_publication"`
+			CreatedAt       sql.Null[time.Time]     `db:"created_at"`
+			UpdatedAt       sql.Null[time.Time]     `db:"updated_at"`
 		}{
 			Id:       a.Id,
+			Slug:     slug,
+			KeepSlug: a.Slug == "",
 			Title:    a.Title,
 			Subtitle: a.Subtitle,
-			Content:  contents[idx]}
-	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
+			Content:  contents[idx],
+			Message:  a.Message,
+
+			State:           state,
+			PublishAt:       nullTime(a.PublishAt),
+			KeepPublication: keepPublication,
+			CreatedAt:       nullTime(a.CreatedAt),
+			UpdatedAt:       nullTime(a.UpdatedAt)}
+		id, err := p.namedId(ctx, query, row)
+		if err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertArticles>: %w", err)
+		}
+		if err := p.illustrateArticle(ctx, id, a.Thumbnail); err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertArticles>: %w", err)
+		} else if err := p.placeArticle(ctx, id, a.Serie); err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertArticles>: %w", err)
+		} else if err := p.tagArticle(ctx, id, a.Tags); err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertArticles>: %w", err)
+		}
+	}
+	if err := p.claimIds(ctx, "articles"); err != nil {
 		return fmt.Errorf("persistence<Pg.UpsertArticles>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) DeleteArticles(articles []entity.DeleteById) error {
+func (p Pg) DeleteArticles(ctx context.Context, articles []entity.DeleteById) error {
 	targets := make([]any, len(articles))
 	for idx, a := range articles {
 		targets[idx] = a.Id
@@ -83,20 +228,21 @@ func (p Pg) DeleteArticles(articles []entity.DeleteById) error {
 		return fmt.Errorf("persistence<Pg.DeleteArticles>: %w", err)
 	}
 
-	if _, err := p.db.Exec(p.db.Rebind(query), args...); err != nil {
+	if _, err := p.conn(ctx).ExecContext(ctx, p.conn(ctx).Rebind(query), args...); err != nil {
 		return fmt.Errorf("persistence<Pg.DeleteArticles>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) InsertArticlesTags(articleTags []entity.WriteArticleTag) error {
+func (p Pg) InsertArticlesTags(ctx context.Context, articleTags []entity.WriteArticleTag) ([]int, error) {
 	query := `
 		INSERT INTO article_tags(
 			article_id,
 			tag_id)
 		VALUES (
 			:article_id,
-			:tag_id)`
+			:tag_id)
+		RETURNING id`
 	rows := make([]any, len(articleTags))
 	for idx, at := range articleTags {
 		rows[idx] = struct {
@@ -106,13 +252,14 @@ func (p Pg) InsertArticlesTags(articleTags []entity.WriteArticleTag) error {
 			ArticleId: at.ArticleId,
 			TagId:     at.TagId}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
-		return fmt.Errorf("persistence<Pg.InsertArticleTags>: %w", err)
+	ids, err := p.namedIds(ctx, query, rows)
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Pg.InsertArticleTags>: %w", err)
 	}
-	return nil
+	return ids, nil
 }
 
-func (p Pg) UpsertArticleTags(articleTags []entity.WriteArticleTag) error {
+func (p Pg) UpsertArticleTags(ctx context.Context, articleTags []entity.WriteArticleTag) error {
 	query := `
 		INSERT INTO article_tags(
 			id,
@@ -137,95 +284,241 @@ func (p Pg) UpsertArticleTags(articleTags []entity.WriteArticleTag) error {
 			ArticleId: at.ArticleId,
 			TagId:     at.TagId}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
+	if _, err := p.conn(ctx).NamedExecContext(ctx, query, rows); err != nil {
+		return fmt.Errorf("persistence<Pg.UpsertArticleTags>: %w", err)
+	}
+	if err := p.claimIds(ctx, "article_tags"); err != nil {
 		return fmt.Errorf("persistence<Pg.UpsertArticleTags>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) DeleteArticleTags(articleTags []entity.DeleteById) error {
+func (p Pg) DeleteArticleTags(ctx context.Context, articleTags []entity.DeleteById) error {
 	targets := make([]any, len(articleTags))
 	for idx, at := range articleTags {
 		targets[idx] = at.Id
 	}
 
 	query, args, err := sqlx.In(
-		`DELETE FROM articles_tags WHERE id IN (?)`,
-		targets...)
+		`DELETE FROM article_tags WHERE id IN (?)`,
+		targets)
 	if err != nil {
 		return fmt.Errorf("persistence<Pg.DeleteArticleTags>: %w", err)
 	}
 
-	if _, err := p.db.Exec(p.db.Rebind(query), args...); err != nil {
+	if _, err := p.conn(ctx).ExecContext(ctx, p.conn(ctx).Rebind(query), args...); err != nil {
 		return fmt.Errorf("persistence<Pg.DeleteArticleTags>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) InsertProjects(projects []entity.WriteProject, contents []string) error {
+// Every project is written along with its first revision, hence one
+// statement per project
+func (p Pg) InsertProjects(ctx context.Context, projects []entity.WriteProject, contents []string) ([]int, error) {
 	query := `
-		INSERT INTO projects(
-			name, 
-			synopsis,
-			description)
-		VALUES(
-			:name,
-			:synopsis,
-			:description)`
-	rows := make([]any, len(projects))
-	for idx, p := range projects {
-		rows[idx] = struct {
+		WITH
+			inserted AS (
+				INSERT INTO projects(
+					slug,
+					name,
+					synopsis,
+					description,
+					state,
+					publish_at,
+					created_at,
+					updated_at)
+				VALUES(
+					:slug,
+					:name,
+					:synopsis,
+					:description,
+					:state,
+					:publish_at,
+					COALESCE(CAST(:created_at AS TIMESTAMP), LOCALTIMESTAMP),
+					COALESCE(CAST(:updated_at AS TIMESTAMP), LOCALTIMESTAMP))
+				RETURNING id, slug, name, description),
+			reclaimed AS (
+				DELETE FROM slug_redirects
+				WHERE kind = 'project' AND slug IN (SELECT slug FROM inserted))
+		INSERT INTO project_revisions(
+			project_id,
+			name,
+			description,
+			message)
+		SELECT id, name, description, :message
+		FROM inserted
+		RETURNING project_id`
+	ids := make([]int, 0, len(projects))
+	for idx, project := range projects {
+		slug := project.Slug
+		if slug == "" {
+			var err error
+			slug, err = p.freeSlug(ctx, entity.SlugProject, project.Name, sql.Null[int]{})
+			if err != nil {
+				return nil, fmt.Errorf("persistence<Pg.InsertProjects>: %w", err)
+			}
+		}
+
+		row := struct {
+			Slug        string `db:"slug"`
 			Name        string `db:"name"`
 			Synopsis    string `db:"synopsis"`
 			Description string `db:"description"`
+			Message     string `db:"message"`
+
+			State     entity.PublicationState `db:"state"`
+			PublishAt sql.Null[time.Time]     `db:"publish_at"`
+			CreatedAt sql.Null[time.Time]     `db:"created_at"`
+			UpdatedAt sql.Null[time.Time]     `db:"updated_at"`
 		}{
-			Name:        p.Name,
-			Synopsis:    p.Synopsis,
-			Description: contents[idx]}
-	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
-		return fmt.Errorf("persistence<Pg.InsertProjects>: %w", err)
-	}
-	return nil
+			Slug:        slug,
+			Name:        project.Name,
+			Synopsis:    project.Synopsis,
+			Description: contents[idx],
+			Message:     project.Message,
+
+			State:     project.State,
+			PublishAt: nullTime(project.PublishAt),
+			CreatedAt: nullTime(project.CreatedAt),
+			UpdatedAt: nullTime(project.UpdatedAt)}
+		id, err := p.namedId(ctx, query, row)
+		if err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertProjects>: %w", err)
+		}
+		ids = append(ids, id)
+		if err := p.illustrateProject(ctx, id, project.Thumbnail); err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertProjects>: %w", err)
+		} else if err := p.placeProject(ctx, id, project.Serie); err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertProjects>: %w", err)
+		} else if err := p.tagProject(ctx, id, project.Tags); err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertProjects>: %w", err)
+		} else if err := p.linkProject(ctx, id, project.Links); err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertProjects>: %w", err)
+		}
+	}
+	return ids, nil
 }
 
-func (p Pg) UpsertProjects(projects []entity.WriteProject, contents []string) error {
+// Every write records a new revision of the project, even when nothing
+// changed. A replaced slug is kept to redirect to the new one
+func (p Pg) UpsertProjects(ctx context.Context, projects []entity.WriteProject, contents []string) error {
 	query := `
-		INSERT INTO projects(
-			id,
-			name, 
-			synopsis,
-			description)
-		VALUES(
-			:id,
-			:name,
-			:synopsis,
-			:description)
-		ON CONFLICT(id)
-		DO UPDATE SET
-			name = EXCLUDED.name,
-			synopsis = EXCLUDED.synopsis,
-			description = EXCLUDED.description`
-	rows := make([]any, len(projects))
-	for idx, p := range projects {
-		rows[idx] = struct {
+		WITH
+			previous AS (
+				SELECT id, slug FROM projects WHERE id = :id),
+			upserted AS (
+				INSERT INTO projects(
+					id,
+					slug,
+					name,
+					synopsis,
+					description,
+					state,
+					publish_at,
+					created_at,
+					updated_at)
+				VALUES(
+					:id,
+					:slug,
+					:name,
+					:synopsis,
+					:description,
+					:state,
+					:publish_at,
+					COALESCE(CAST(:created_at AS TIMESTAMP), LOCALTIMESTAMP),
+					COALESCE(CAST(:updated_at AS TIMESTAMP), LOCALTIMESTAMP))
+				ON CONFLICT(id)
+				DO UPDATE SET
+					slug = CASE WHEN :keep_slug THEN projects.slug ELSE EXCLUDED.slug END,
+					name = EXCLUDED.name,
+					synopsis = EXCLUDED.synopsis,
+					description = EXCLUDED.description,
+					state = CASE WHEN :keep_publication THEN projects.state ELSE EXCLUDED.state END,
+					publish_at = CASE WHEN :keep_publication THEN projects.publish_at ELSE EXCLUDED.publish_at END,
+					created_at = COALESCE(CAST(:created_at AS TIMESTAMP), projects.created_at),
+					updated_at = EXCLUDED.updated_at
+				RETURNING id, slug, name, description),
+			redirected AS (
+				INSERT INTO slug_redirects(kind, slug, target_id)
+				SELECT 'project', previous.slug, upserted.id
+				FROM previous
+				JOIN upserted ON upserted.id = previous.id
+				WHERE previous.slug <> upserted.slug
+				ON CONFLICT(kind, slug)
+				DO UPDATE SET
+					target_id = EXCLUDED.target_id,
+					created_at = EXCLUDED.created_at),
+			reclaimed AS (
+				DELETE FROM slug_redirects
+				WHERE kind = 'project' AND slug IN (SELECT slug FROM upserted))
+		INSERT INTO project_revisions(
+			project_id,
+			name,
+			description,
+			message)
+		SELECT id, name, description, :message
+		FROM upserted
+		RETURNING project_id`
+	for idx, project := range projects {
+		slug := project.Slug
+		if slug == "" {
+			var err error
+			slug, err = p.freeSlug(ctx, entity.SlugProject, project.Name, sql.Null[int]{V: project.Id, Valid: true})
+			if err != nil {
+				return fmt.Errorf("persistence<Pg.UpsertProjects>: %w", err)
+			}
+		}
+
+		state, keepPublication := upsertedState(project.State)
+		row := struct {
 			Id          int    `db:"id"`
+			Slug        string `db:"slug"`
+			KeepSlug    bool   `db:"keep_slug"`
 			Name        string `db:"name"`
 			Synopsis    string `db:"synopsis"`
 			Description string `db:"description"`
+			Message     string `db:"message"`
+
+			State           entity.PublicationState `db:"state"`
+			PublishAt       sql.Null[time.Time]     `db:"publish_at"`
+			KeepPublication bool                    `db:"keep_publication"`
+			CreatedAt       sql.Null[time.Time]     `db:"created_at"`
+			UpdatedAt       sql.Null[time.Time]     `db:"updated_at"`
 		}{
-			Id:          p.Id,
-			Name:        p.Name,
-			Synopsis:    p.Synopsis,
-			Description: contents[idx]}
-	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
+			Id:          project.Id,
+			Slug:        slug,
+			KeepSlug:    project.Slug == "",
+			Name:        project.Name,
+			Synopsis:    project.Synopsis,
+			Description: contents[idx],
+			Message:     project.Message,
+
+			State:           state,
+			PublishAt:       nullTime(project.PublishAt),
+			KeepPublication: keepPublication,
+			CreatedAt:       nullTime(project.CreatedAt),
+			UpdatedAt:       nullTime(project.UpdatedAt)}
+		id, err := p.namedId(ctx, query, row)
+		if err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertProjects>: %w", err)
+		}
+		if err := p.illustrateProject(ctx, id, project.Thumbnail); err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertProjects>: %w", err)
+		} else if err := p.placeProject(ctx, id, project.Serie); err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertProjects>: %w", err)
+		} else if err := p.tagProject(ctx, id, project.Tags); err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertProjects>: %w", err)
+		} else if err := p.linkProject(ctx, id, project.Links); err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertProjects>: %w", err)
+		}
+	}
+	if err := p.claimIds(ctx, "projects"); err != nil {
 		return fmt.Errorf("persistence<Pg.UpsertProjects>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) DeleteProjects(projects []entity.DeleteById) error {
+func (p Pg) DeleteProjects(ctx context.Context, projects []entity.DeleteById) error {
 	targets := make([]any, len(projects))
 	for idx, a := range projects {
 		targets[idx] = a.Id
@@ -238,20 +531,21 @@ func (p Pg) DeleteProjects(projects []entity.DeleteById) error {
 		return fmt.Errorf("persistence<Pg.DeleteProjects>: %w", err)
 	}
 
-	if _, err := p.db.Exec(p.db.Rebind(query), args...); err != nil {
+	if _, err := p.conn(ctx).ExecContext(ctx, p.conn(ctx).Rebind(query), args...); err != nil {
 		return fmt.Errorf("persistence<Pg.DeleteProjects>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) InsertProjectTags(projectTags []entity.WriteProjectTag) error {
+func (p Pg) InsertProjectTags(ctx context.Context, projectTags []entity.WriteProjectTag) ([]int, error) {
 	query := `
 		INSERT INTO project_tags(
 			project_id, 
 			tag_id)
 		VALUES(
 			:project_id,
-			:tag_id)`
+			:tag_id)
+		RETURNING id`
 	rows := make([]any, len(projectTags))
 	for idx, pt := range projectTags {
 		rows[idx] = struct {
@@ -261,13 +555,14 @@ func (p Pg) InsertProjectTags(projectTags []entity.WriteProjectTag) error {
 			ProjectId: pt.ProjectId,
 			TagId:     pt.TagId}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
-		return fmt.Errorf("persistence<Pg.InsertProjectTags>: %w", err)
+	ids, err := p.namedIds(ctx, query, rows)
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Pg.InsertProjectTags>: %w", err)
 	}
-	return nil
+	return ids, nil
 }
 
-func (p Pg) UpsertProjectTags(projectTags []entity.WriteProjectTag) error {
+func (p Pg) UpsertProjectTags(ctx context.Context, projectTags []entity.WriteProjectTag) error {
 	query := `
 		INSERT INTO project_tags(
 			id,
@@ -292,13 +587,16 @@ func (p Pg) UpsertProjectTags(projectTags []entity.WriteProjectTag) error {
 			ProjectId: pt.ProjectId,
 			TagId:     pt.TagId}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
+	if _, err := p.conn(ctx).NamedExecContext(ctx, query, rows); err != nil {
+		return fmt.Errorf("persistence<Pg.UpsertProjectTags>: %w", err)
+	}
+	if err := p.claimIds(ctx, "project_tags"); err != nil {
 		return fmt.Errorf("persistence<Pg.UpsertProjectTags>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) DeleteProjectTags(projectTags []entity.DeleteById) error {
+func (p Pg) DeleteProjectTags(ctx context.Context, projectTags []entity.DeleteById) error {
 	targets := make([]any, len(projectTags))
 	for idx, a := range projectTags {
 		targets[idx] = a.Id
@@ -311,13 +609,13 @@ func (p Pg) DeleteProjectTags(projectTags []entity.DeleteById) error {
 		return fmt.Errorf("persistence<Pg.DeleteProjects>: %w", err)
 	}
 
-	if _, err := p.db.Exec(p.db.Rebind(query), args...); err != nil {
+	if _, err := p.conn(ctx).ExecContext(ctx, p.conn(ctx).Rebind(query), args...); err != nil {
 		return fmt.Errorf("persistence<Pg.DeleteProjects>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) InsertProjectLinks(projectLinks []entity.WriteProjectLink) error {
+func (p Pg) InsertProjectLinks(ctx context.Context, projectLinks []entity.WriteProjectLink) ([]int, error) {
 	query := `
 		INSERT INTO project_links(
 			project_id, 
@@ -326,7 +624,8 @@ func (p Pg) InsertProjectLinks(projectLinks []entity.WriteProjectLink) error {
 		VALUES(
 			:project_id,
 			:display_text,
-			:url)`
+			:url)
+		RETURNING id`
 	rows := make([]any, len(projectLinks))
 	for idx, pl := range projectLinks {
 		rows[idx] = struct {
@@ -338,13 +637,14 @@ func (p Pg) InsertProjectLinks(projectLinks []entity.WriteProjectLink) error {
 			DisplayText: pl.DisplayText,
 			Url:         pl.Url}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
-		return fmt.Errorf("persistence<Pg.InsertProjectLinks>: %w", err)
+	ids, err := p.namedIds(ctx, query, rows)
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Pg.InsertProjectLinks>: %w", err)
 	}
-	return nil
+	return ids, nil
 }
 
-func (p Pg) UpsertProjectLinks(projectLinks []entity.WriteProjectLink) error {
+func (p Pg) UpsertProjectLinks(ctx context.Context, projectLinks []entity.WriteProjectLink) error {
 	query := `
 		INSERT INTO project_links(
 			id,
@@ -374,13 +674,16 @@ func (p Pg) UpsertProjectLinks(projectLinks []entity.WriteProjectLink) error {
 			DisplayText: pl.DisplayText,
 			Url:         pl.Url}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
+	if _, err := p.conn(ctx).NamedExecContext(ctx, query, rows); err != nil {
+		return fmt.Errorf("persistence<Pg.UpsertProjectLinks>: %w", err)
+	}
+	if err := p.claimIds(ctx, "project_links"); err != nil {
 		return fmt.Errorf("persistence<Pg.UpsertProjectLinks>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) DeleteProjectLinks(projectLinks []entity.DeleteById) error {
+func (p Pg) DeleteProjectLinks(ctx context.Context, projectLinks []entity.DeleteById) error {
 	targets := make([]any, len(projectLinks))
 	for idx, a := range projectLinks {
 		targets[idx] = a.Id
@@ -393,29 +696,31 @@ func (p Pg) DeleteProjectLinks(projectLinks []entity.DeleteById) error {
 		return fmt.Errorf("persistence<Pg.DeleteProjectLinks>: %w", err)
 	}
 
-	if _, err := p.db.Exec(p.db.Rebind(query), args...); err != nil {
+	if _, err := p.conn(ctx).ExecContext(ctx, p.conn(ctx).Rebind(query), args...); err != nil {
 		return fmt.Errorf("persistence<Pg.DeleteProjectLinks>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) InsertTags(tags []entity.WriteTag) error {
+func (p Pg) InsertTags(ctx context.Context, tags []entity.WriteTag) ([]int, error) {
 	query := `
 		INSERT INTO tags(name)
-		VALUES(:name)`
+		VALUES(:name)
+		RETURNING id`
 	rows := make([]any, len(tags))
 	for idx, t := range tags {
 		rows[idx] = struct {
 			Name string `db:"name"`
 		}{Name: t.Name}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
-		return fmt.Errorf("persistence<Pg.InsertTags>: %w", err)
+	ids, err := p.namedIds(ctx, query, rows)
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Pg.InsertTags>: %w", err)
 	}
-	return nil
+	return ids, nil
 }
 
-func (p Pg) UpsertTags(tags []entity.WriteTag) error {
+func (p Pg) UpsertTags(ctx context.Context, tags []entity.WriteTag) error {
 	query := `
 		INSERT INTO tags(id, name)
 		VALUES(:id, :name)
@@ -431,13 +736,16 @@ func (p Pg) UpsertTags(tags []entity.WriteTag) error {
 			Id:   t.Id,
 			Name: t.Name}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
+	if _, err := p.conn(ctx).NamedExecContext(ctx, query, rows); err != nil {
+		return fmt.Errorf("persistence<Pg.UpsertTags>: %w", err)
+	}
+	if err := p.claimIds(ctx, "tags"); err != nil {
 		return fmt.Errorf("persistence<Pg.UpsertTags>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) DeleteTags(tags []entity.DeleteById) error {
+func (p Pg) DeleteTags(ctx context.Context, tags []entity.DeleteById) error {
 	targets := make([]any, len(tags))
 	for idx, a := range tags {
 		targets[idx] = a.Id
@@ -450,76 +758,170 @@ func (p Pg) DeleteTags(tags []entity.DeleteById) error {
 		return fmt.Errorf("persistence<Pg.DeleteTags>: %w", err)
 	}
 
-	if _, err := p.db.Exec(p.db.Rebind(query), args...); err != nil {
+	if _, err := p.conn(ctx).ExecContext(ctx, p.conn(ctx).Rebind(query), args...); err != nil {
 		return fmt.Errorf("persistence<Pg.DeleteTags>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) InsertSeries(series []entity.WriteSerie) error {
+// One statement per serie, as each may take over a slug some former one
+// redirects from
+func (p Pg) InsertSeries(ctx context.Context, series []entity.WriteSerie) ([]int, error) {
 	query := `
-		INSERT INTO series(
-			name,
-			thumbnail,
-			description)
-		VALUES(
-			:name,
-			:thumbnail,
-			:description)`
-	rows := make([]any, len(series))
-	for idx, s := range series {
-		rows[idx] = struct {
+		WITH
+			inserted AS (
+				INSERT INTO series(
+					slug,
+					name,
+					thumbnail,
+					description,
+					state,
+					publish_at,
+					created_at)
+				VALUES(
+					:slug,
+					:name,
+					:thumbnail,
+					:description,
+					:state,
+					:publish_at,
+					COALESCE(CAST(:created_at AS TIMESTAMP), LOCALTIMESTAMP))
+				RETURNING id, slug),
+			reclaimed AS (
+				DELETE FROM slug_redirects
+				WHERE kind = 'serie' AND slug IN (SELECT slug FROM inserted))
+		SELECT id FROM inserted`
+	ids := make([]int, 0, len(series))
+	for _, s := range series {
+		slug := s.Slug
+		if slug == "" {
+			var err error
+			slug, err = p.freeSlug(ctx, entity.SlugSerie, s.Name, sql.Null[int]{})
+			if err != nil {
+				return nil, fmt.Errorf("persistence<Pg.InsertSeries>: %w", err)
+			}
+		}
+
+		row := struct {
+			Slug        string `db:"slug"`
 			Name        string `db:"name"`
 			Thumbnail   string `db:"thumbnail"`
 			Description string `db:"description"`
+
+			State     entity.PublicationState `db:"state"`
+			PublishAt sql.Null[time.Time]     `db:"publish_at"`
+			CreatedAt sql.Null[time.Time]     `db:"created_at"`
 		}{
+			Slug:        slug,
 			Name:        s.Name,
 			Thumbnail:   s.Thumbnail,
-			Description: s.Description}
-	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
-		return fmt.Errorf("persistence<Pg.InsertSeries>: %w", err)
-	}
-	return nil
+			Description: s.Description,
+
+			State:     s.State,
+			PublishAt: nullTime(s.PublishAt),
+			CreatedAt: nullTime(s.CreatedAt)}
+		id, err := p.namedId(ctx, query, row)
+		if err != nil {
+			return nil, fmt.Errorf("persistence<Pg.InsertSeries>: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
 }
 
-func (p Pg) UpsertSeries(series []entity.WriteSerie) error {
+// A replaced slug is kept to redirect to the new one
+func (p Pg) UpsertSeries(ctx context.Context, series []entity.WriteSerie) error {
 	query := `
-		INSERT INTO series(
-			id,
-			name,
-			thumbnail,
-			description)
-		VALUES(
-			:id,
-			:name,
-			:thumbnail,
-			:description)
-		ON CONFLICT(id)
-		DO UPDATE SET
-			name = EXCLUDED.name,
-			thumbnail = EXCLUDED.thumbnail,
-			description = EXCLUDED.decsription `
-	rows := make([]any, len(series))
-	for idx, s := range series {
-		rows[idx] = struct {
+		WITH
+			previous AS (
+				SELECT id, slug FROM series WHERE id = :id),
+			upserted AS (
+				INSERT INTO series(
+					id,
+					slug,
+					name,
+					thumbnail,
+					description,
+					state,
+					publish_at,
+					created_at)
+				VALUES(
+					:id,
+					:slug,
+					:name,
+					:thumbnail,
+					:description,
+					:state,
+					:publish_at,
+					COALESCE(CAST(:created_at AS TIMESTAMP), LOCALTIMESTAMP))
+				ON CONFLICT(id)
+				DO UPDATE SET
+					slug = CASE WHEN :keep_slug THEN series.slug ELSE EXCLUDED.slug END,
+					name = EXCLUDED.name,
+					thumbnail = EXCLUDED.thumbnail,
+					description = EXCLUDED.description,
+					state = CASE WHEN :keep_publication THEN series.state ELSE EXCLUDED.state END,
+					publish_at = CASE WHEN :keep_publication THEN series.publish_at ELSE EXCLUDED.publish_at END,
+					created_at = COALESCE(CAST(:created_at AS TIMESTAMP), series.created_at)
+				RETURNING id, slug),
+			redirected AS (
+				INSERT INTO slug_redirects(kind, slug, target_id)
+				SELECT 'serie', previous.slug, upserted.id
+				FROM previous
+				JOIN upserted ON upserted.id = previous.id
+				WHERE previous.slug <> upserted.slug
+				ON CONFLICT(kind, slug)
+				DO UPDATE SET
+					target_id = EXCLUDED.target_id,
+					created_at = EXCLUDED.created_at)
+		DELETE FROM slug_redirects
+		WHERE kind = 'serie' AND slug IN (SELECT slug FROM upserted)`
+	for _, s := range series {
+		slug := s.Slug
+		if slug == "" {
+			var err error
+			slug, err = p.freeSlug(ctx, entity.SlugSerie, s.Name, sql.Null[int]{V: s.Id, Valid: true})
+			if err != nil {
+				return fmt.Errorf("persistence<Pg.UpsertSeries>: %w", err)
+			}
+		}
+
+		state, keepPublication := upsertedState(s.State)
+		row := struct {
 			Id          int    `db:"id"`
+			Slug        string `db:"slug"`
+			KeepSlug    bool   `db:"keep_slug"`
 			Name        string `db:"name"`
 			Thumbnail   string `db:"thumbnail"`
 			Description string `db:"description"`
+
+			State           entity.PublicationState `db:"state"`
+			PublishAt       sql.Null[time.Time]     `db:"publish_at"`
+			KeepPublication bool                    `db:"keep_publication"`
+			CreatedAt       sql.Null[time.Time]     `db:"created_at"`
 		}{
 			Id:          s.Id,
+			Slug:        slug,
+			KeepSlug:    s.Slug == "",
 			Name:        s.Name,
 			Thumbnail:   s.Thumbnail,
-			Description: s.Description}
+			Description: s.Description,
+
+			State:           state,
+			PublishAt:       nullTime(s.PublishAt),
+			KeepPublication: keepPublication,
+			CreatedAt:       nullTime(s.CreatedAt)}
+		if _, err := p.conn(ctx).NamedExecContext(ctx, query, row); err != nil {
+			return fmt.Errorf("persistence<Pg.UpsertSeries>: %w", err)
+		}
 	}
-	if _, err := p.db.NamedExec(query, rows); err != nil {
+	if err := p.claimIds(ctx, "series"); err != nil {
 		return fmt.Errorf("persistence<Pg.UpsertSeries>: %w", err)
 	}
 	return nil
 }
 
-func (p Pg) DeleteSeries(series []entity.DeleteById) error {
+func (p Pg) DeleteSeries(ctx context.Context, series []entity.DeleteById) error {
 	targets := make([]any, len(series))
 	for idx, a := range series {
 		targets[idx] = a.Id
@@ -532,8 +934,46 @@ func (p Pg) DeleteSeries(series []entity.DeleteById) error {
 		return fmt.Errorf("persistence<Pg.DeleteSeries>: %w", err)
 	}
 
-	if _, err := p.db.Exec(p.db.Rebind(query), args...); err != nil {
+	if _, err := p.conn(ctx).ExecContext(ctx, p.conn(ctx).Rebind(query), args...); err != nil {
 		return fmt.Errorf("persistence<Pg.DeleteSeries>: %w", err)
 	}
 	return nil
 }
+
+// Moves the `SERIAL` of `table` past the ids written explicitly, so the
+// next insert doesn't collide with them. It only ever moves forward, as ids
+// of deleted entries shouldn't be handed out again. Sequences ignore
+// rollbacks, hence nothing is done within a transaction that won't commit
+func (p Pg) claimIds(ctx context.Context, table string) error {
+	if t, ok := ctx.Value(pgTxKey{}).(pgTx); ok && t.db == p.db && !t.commit {
+		return nil
+	}
+
+	var sequence string
+	if err := p.conn(ctx).GetContext(ctx, &sequence,
+		`SELECT pg_get_serial_sequence($1, 'id')`, table); err != nil {
+		return err
+	}
+	_, err := p.conn(ctx).ExecContext(ctx, fmt.Sprintf(`
+		SELECT setval('%[1]s',
+			GREATEST(written.id, seq.last_value),
+			seq.is_called OR written.id >= seq.last_value)
+		FROM %[1]s seq, (SELECT MAX(id) AS id FROM %[2]s) written
+		WHERE written.id IS NOT NULL`,
+		sequence, table))
+	return err
+}
+
+// The state an upserted entry is written with. Without one, a stored entry
+// keeps its publication as it is, while a new one is published right away
+func upsertedState(state entity.PublicationState) (entity.PublicationState, bool) {
+	if state == "" {
+		return entity.StatePublished, true
+	}
+	return state, false
+}
+
+// A zero time is stored as NULL
+func nullTime(t time.Time) sql.Null[time.Time] {
+	return sql.Null[time.Time]{V: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,203 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+)
+
+// A database driver recording the statements it's given rather than running
+// them, to check what `Pg` sends to Postgres. Queries give back `rows`, made
+// of a single `value` column unless `columns` are named
+type recorder struct {
+	stmts   []recorded
+	rows    [][]driver.Value
+	columns []string
+}
+
+type recorded struct {
+	query string
+	args  []any
+}
+
+func newRecordedPg() (Pg, *recorder) {
+	r := &recorder{}
+	db := sqlx.NewDb(sql.OpenDB(r), "pgx")
+	db.SetMaxOpenConns(1)
+	return NewPg(db, cursor.NewSealer([]byte("key"))), r
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return recorderConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return nil }
+
+func (r *recorder) record(query string, args []driver.NamedValue) {
+	values := make([]any, len(args))
+	for idx, arg := range args {
+		values[idx] = arg.Value
+	}
+	r.stmts = append(r.stmts, recorded{query: query, args: values})
+}
+
+func (r *recorder) queries() []string {
+	var queries []string
+	for _, stmt := range r.stmts {
+		queries = append(queries, stmt.query)
+	}
+	return queries
+}
+
+type recorderConn struct{ r *recorder }
+
+func (c recorderConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("recorder: statements aren't prepared")
+}
+func (c recorderConn) Close() error { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) {
+	c.r.record("BEGIN", nil)
+	return recorderTx{c.r}, nil
+}
+
+func (c recorderConn) ExecContext(
+	_ context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	c.r.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recorderConn) QueryContext(
+	_ context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	c.r.record(query, args)
+	columns := c.r.columns
+	if len(columns) == 0 {
+		columns = []string{"value"}
+	}
+	return &recorderRows{columns: columns, rows: c.r.rows}, nil
+}
+
+type recorderTx struct{ r *recorder }
+
+func (t recorderTx) Commit() error   { t.r.record("COMMIT", nil); return nil }
+func (t recorderTx) Rollback() error { t.r.record("ROLLBACK", nil); return nil }
+
+type recorderRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *recorderRows) Columns() []string { return r.columns }
+func (r *recorderRows) Close() error      { return nil }
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestPgDeleteByIds(t *testing.T) {
+	tests := []struct {
+		table  string
+		delete func(p Pg, ctx context.Context, rows []entity.DeleteById) error
+	}{
+		{"articles", Pg.DeleteArticles},
+		{"article_tags", Pg.DeleteArticleTags},
+		{"projects", Pg.DeleteProjects},
+		{"project_tags", Pg.DeleteProjectTags},
+		{"project_links", Pg.DeleteProjectLinks},
+		{"tags", Pg.DeleteTags},
+		{"series", Pg.DeleteSeries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			for _, ids := range [][]int{{4}, {1, 2, 3}} {
+				p, r := newRecordedPg()
+				rows := make([]entity.DeleteById, len(ids))
+				wantArgs := make([]any, len(ids))
+				bindvars := make([]string, len(ids))
+				for idx, id := range ids {
+					rows[idx] = entity.DeleteById{Id: id}
+					wantArgs[idx] = int64(id)
+					bindvars[idx] = fmt.Sprintf("$%d", idx+1)
+				}
+				wantQuery := fmt.Sprintf("DELETE FROM %s WHERE id IN (%s)",
+					tt.table, strings.Join(bindvars, ", "))
+
+				if err := tt.delete(p, context.Background(), rows); err != nil {
+					t.Fatalf("deleting %v failed: %v", ids, err)
+				}
+				want := []recorded{{query: wantQuery, args: wantArgs}}
+				if !reflect.DeepEqual(r.stmts, want) {
+					t.Errorf("deleting %v\n got: %+v\nwant: %+v", ids, r.stmts, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPgClaimIds(t *testing.T) {
+	claim := func(ctx context.Context, p Pg) error { return p.claimIds(ctx, "articles") }
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, p Pg) error
+		want []string
+	}{
+		{
+			name: "outside of a transaction",
+			run:  claim,
+			want: []string{"SELECT pg_get_serial_sequence($1, 'id')", "setval"}},
+		{
+			name: "committed",
+			run: func(ctx context.Context, p Pg) error {
+				return p.Atomic(ctx, true, func(ctx context.Context) error { return claim(ctx, p) })
+			},
+			want: []string{"BEGIN", "SELECT pg_get_serial_sequence($1, 'id')", "setval", "COMMIT"}},
+		{
+			name: "dry run",
+			run: func(ctx context.Context, p Pg) error {
+				return p.Atomic(ctx, false, func(ctx context.Context) error {
+					// joins the outer transaction, which won't commit
+					return p.Atomic(ctx, true, func(ctx context.Context) error { return claim(ctx, p) })
+				})
+			},
+			want: []string{"BEGIN", "ROLLBACK"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, r := newRecordedPg()
+			r.rows = [][]driver.Value{{"public.articles_id_seq"}}
+			if err := tt.run(context.Background(), p); err != nil {
+				t.Fatalf("claiming ids failed: %v", err)
+			}
+
+			got := r.queries()
+			for idx, query := range got {
+				if strings.Contains(query, "setval") {
+					if !strings.Contains(query, "FROM public.articles_id_seq seq") ||
+						!strings.Contains(query, "GREATEST(written.id, seq.last_value)") {
+						t.Errorf("the sequence may move backward:\n%s", query)
+					}
+					got[idx] = "setval"
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got statements %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
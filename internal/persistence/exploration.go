@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
@@ -8,96 +9,74 @@ import (
 	"time"
 
 	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
 )
 
 type ArticlesQueryParam struct {
-	Limit int
-	Last  string
-	Tag   []string
-	Serie []string
+	Limit  int
+	Last   string // cursor of the entry to list after
+	Before string // cursor of the entry to list before, paging backward
+	ExplorationQueryParam
+
+	WithContent bool // whether to fetch the content of the articles too
 }
 
-func (p Pg) Articles(param ArticlesQueryParam) ([]entity.ArticleListPage, error) {
+func (p Pg) Articles(ctx context.Context, param ArticlesQueryParam) ([]entity.ArticleListPage, error) {
+	matches, args := exploreArticles.matches(param.ExplorationQueryParam)
+	pos, err := readPosition(p.cursors, param.Last, param.Before, param.Sort)
+	if err != nil {
+		return []entity.ArticleListPage{}, fmt.Errorf("persistence<Pg.Articles>: %w", err)
+	}
+	withinPage, args := pos.where("articles", "title", args)
+	limit := 10
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	args = append(args, limit, param.WithContent)
+
 	query := `
+		WITH matches AS (` + matches + `)
 		SELECT
 			articles.id,
+			articles.slug,
 			articles.title,
 			articles.subtitle,
+			CASE WHEN $` + strconv.Itoa(len(args)) + ` THEN articles.content ELSE '' END AS "content",
 			articles.created_at,
 			articles.updated_at,
+			articles.relevance,
 			tags.id AS "tag.id",
 			tags.name AS "tag.name",
 			series.id AS "serie.id",
+			series.slug AS "serie.slug",
 			series.name AS "serie.name"
 		FROM (
 			SELECT *
-			FROM articles
-			WHERE
-				id > $1 
-				AND updated_at >= $2
-				AND ($4::VARCHAR[] IS NULL
-					OR EXISTS (
-						SELECT 1
-						FROM article_tags
-						JOIN tags ON article_tags.tag_id = tags.id
-						WHERE 
-							article_tags.article_id = articles.id
-							AND LOWER(tags.name) = ANY($4)
-						GROUP BY article_id
-						HAVING COUNT(DISTINCT tag_id) = CARDINALITY($4)))
-				AND ($5::VARCHAR[] IS NULL 
-					OR EXISTS(
-						SELECT 1
-						FROM articles AS temp_articles
-						JOIN series ON series.id = articles.serie_id
-						WHERE
-							temp_articles.id = articles.id
-							AND LOWER(series.name) = ANY($5)))
-			ORDER BY
-				updated_at DESC,
-				id
-			LIMIT $3
+			FROM matches AS articles
+			WHERE ` + withinPage + `
+			ORDER BY ` + pos.fetchOrder(param.Sort, "articles", "title") + `
+			LIMIT $` + strconv.Itoa(len(args)-1) + `
 		) AS articles
 		LEFT JOIN article_tags ON article_tags.article_id = articles.id
 		LEFT JOIN tags ON article_tags.tag_id = tags.id
-		LEFT JOIN series ON articles.serie_id = series.id
-		ORDER BY 
-			articles.updated_at DESC, 
-			id`
-	args := []any{
-		0,               // $1 -> lastId
-		time.Unix(0, 0), // $2 -> lastTime
-		10,              // $3 -> limit
-		nil,             // $4 -> tag filter
-		nil,             // $5 -> serie filter
-	}
-	if tokens := strings.Split(param.Last, "-"); len(tokens) == 2 {
-		if lastId, err := strconv.ParseInt(tokens[1], 10, strconv.IntSize); err == nil {
-			args[0] = lastId
-		}
-		if lastTime, err := strconv.ParseInt(tokens[0], 10, strconv.IntSize); err == nil {
-			args[1] = time.Unix(0, lastTime)
-		}
-	}
-	if param.Limit > 0 {
-		args[2] = param.Limit
-	}
-	if len(param.Tag) > 0 {
-		args[3] = param.Tag
-	}
-	if len(param.Serie) > 0 {
-		args[4] = param.Serie
-	}
+		LEFT JOIN series
+			ON articles.serie_id = series.id
+			AND series.state IN ('published', 'archived')
+		ORDER BY ` + param.Sort.orderBy("articles", "title")
 
 	var rows []struct {
 		Id        int       `db:"id"`
+		Slug      string    `db:"slug"`
 		Title     string    `db:"title"`
 		Subtitle  string    `db:"subtitle"`
+		Content   string    `db:"content"`
 		CreatedAt time.Time `db:"created_at"`
 		UpdatedAt time.Time `db:"updated_at"`
+		Relevance float32   `db:"relevance"`
 
 		Serie struct {
 			Id   sql.Null[int]    `db:"id"`
+			Slug sql.Null[string] `db:"slug"`
 			Name sql.Null[string] `db:"name"`
 		}
 		Tag struct {
@@ -105,7 +84,7 @@ func (p Pg) Articles(param ArticlesQueryParam) ([]entity.ArticleListPage, error)
 			Name sql.Null[string] `db:"name"`
 		}
 	}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.ArticleListPage{}, fmt.Errorf(
 			"persistence<Pg.Articles>: %s", err)
 	} else if len(rows) == 0 {
@@ -118,20 +97,33 @@ func (p Pg) Articles(param ArticlesQueryParam) ([]entity.ArticleListPage, error)
 	for _, r := range rows {
 		if lastArticle == nil || lastArticle.Id != r.Id {
 			insertedTags = map[int]struct{}{}
+			cursor, err := newSortKey(
+				param.Sort, r.Id, r.Title, r.CreatedAt, r.UpdatedAt, r.Relevance,
+			).cursor(p.cursors)
+			if err != nil {
+				return []entity.ArticleListPage{}, fmt.Errorf(
+					"persistence<Pg.Articles>: %w", err)
+			}
 			articles = append(articles, entity.ArticleListPage{
 				Id:        r.Id,
+				Slug:      r.Slug,
 				Title:     r.Title,
 				Subtitle:  r.Subtitle,
+				Content:   r.Content,
 				CreatedAt: r.CreatedAt,
-				UpdatedAt: r.UpdatedAt})
+				UpdatedAt: r.UpdatedAt,
+				Relevance: r.Relevance,
+				Cursor:    cursor})
 			lastArticle = &articles[len(articles)-1]
 		}
 		if r.Serie.Id.Valid {
 			lastArticle.Serie = &struct {
 				Id   int
+				Slug string
 				Name string
 			}{
 				Id:   r.Serie.Id.V,
+				Slug: r.Serie.Slug.V,
 				Name: r.Serie.Name.V}
 		}
 		if r.Tag.Id.Valid {
@@ -146,94 +138,81 @@ func (p Pg) Articles(param ArticlesQueryParam) ([]entity.ArticleListPage, error)
 	return articles, nil
 }
 
+func (p Pg) CountArticles(ctx context.Context, param ExplorationQueryParam) (int, error) {
+	matches, args := exploreArticles.matches(param)
+	query := `
+		WITH matches AS (` + matches + `)
+		SELECT COUNT(*) FROM matches`
+
+	var count int
+	if err := p.conn(ctx).GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("persistence<Pg.CountArticles>: %w", err)
+	}
+	return count, nil
+}
+
 type ProjectsQueryParam struct {
-	Limit int
-	Last  string
-	Tag   []string
-	Serie []string
+	Limit  int
+	Last   string // cursor of the entry to list after
+	Before string // cursor of the entry to list before, paging backward
+	ExplorationQueryParam
 }
 
-func (p Pg) Projects(param ProjectsQueryParam) ([]entity.ProjectListPage, error) {
+func (p Pg) Projects(ctx context.Context, param ProjectsQueryParam) ([]entity.ProjectListPage, error) {
+	matches, args := exploreProjects.matches(param.ExplorationQueryParam)
+	pos, err := readPosition(p.cursors, param.Last, param.Before, param.Sort)
+	if err != nil {
+		return []entity.ProjectListPage{}, fmt.Errorf("persistence<Pg.Projects>: %w", err)
+	}
+	withinPage, args := pos.where("projects", "name", args)
+	limit := 10
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	args = append(args, limit)
+
 	query := `
+		WITH matches AS (` + matches + `)
 		SELECT
-		   	projects.id,
-		   	projects.name,
-		   	projects.synopsis,
-		   	projects.created_at,
-		   	projects.updated_at,
-		   	tags.id AS "tag.id",
-		   	tags.name AS "tag.name",
-		   	series.id AS "serie.id",
-		   	series.name AS "serie.name"
+			projects.id,
+			projects.slug,
+			projects.name,
+			projects.synopsis,
+			projects.created_at,
+			projects.updated_at,
+			projects.relevance,
+			tags.id AS "tag.id",
+			tags.name AS "tag.name",
+			series.id AS "serie.id",
+			series.slug AS "serie.slug",
+			series.name AS "serie.name"
 		FROM (
 			SELECT *
-			FROM projects
-			WHERE
-				id > $1
-				AND updated_at >= $2
-				AND ($4::VARCHAR[] IS NULL
-					OR EXISTS (
-						SELECT 1
-						FROM project_tags
-						JOIN tags ON project_tags.tag_id = tags.id
-						WHERE
-							project_tags.project_id = projects.id
-							AND LOWER(tags.name) = ANY($4)
-						GROUP BY project_id
-						HAVING COUNT(DISTINCT tag_id) = CARDINALITY($4)))
-				AND ($5::VARCHAR[] IS NULL
-					OR EXISTS (
-						SELECT 1
-						FROM projects AS temp_projects
-						JOIN series ON series.id = projects.devblog_serie
-						WHERE 
-							temp_projects.id = projects.id
-							AND LOWER(series.name) = ANY($5)))
-			ORDER BY
-				updated_at DESC,
-				id
-			LIMIT $3
+			FROM matches AS projects
+			WHERE ` + withinPage + `
+			ORDER BY ` + pos.fetchOrder(param.Sort, "projects", "name") + `
+			LIMIT $` + strconv.Itoa(len(args)) + `
 		) AS projects
 		LEFT JOIN project_tags ON project_tags.project_id = projects.id
 		LEFT JOIN tags ON project_tags.tag_id = tags.id
-		LEFT JOIN series ON projects.devblog_serie = series.id
-		ORDER BY 
-			projects.updated_at DESC, 
-			id`
-	args := []any{
-		0,               // $1 -> lastId
-		time.Unix(0, 0), // $2 -> lastTime
-		10,              // $3 -> limit
-		nil,             // $4 -> tagList
-		nil}             // $5 -> serieList
-	if tokens := strings.Split(param.Last, "-"); len(tokens) == 2 {
-		if lastId, err := strconv.ParseInt(tokens[1], 10, strconv.IntSize); err == nil {
-			args[0] = lastId
-		}
-		if lastTime, err := strconv.ParseInt(tokens[0], 10, strconv.IntSize); err == nil {
-			args[1] = time.Unix(0, lastTime)
-		}
-	}
-	if param.Limit > 0 {
-		args[2] = param.Limit
-	}
-	if len(param.Tag) > 0 {
-		args[3] = param.Tag
-	}
-	if len(param.Serie) > 0 {
-		args[4] = param.Serie
-	}
+		LEFT JOIN series
+			ON projects.devblog_serie = series.id
+			AND series.state IN ('published', 'archived')
+		ORDER BY ` + param.Sort.orderBy("projects", "name")
 
 	var rows []struct {
 		Id        int       `db:"id"`
+		Slug      string    `db:"slug"`
 		Name      string    `db:"name"`
 		Thumbnail string    `db:"thumbnail"`
 		Synopsis  string    `db:"synopsis"`
 		CreatedAt time.Time `db:"created_at"`
 		UpdatedAt time.Time `db:"updated_at"`
+		Relevance float32   `db:"relevance"`
 
 		Serie struct {
 			Id   sql.Null[int]    `db:"id"`
+			Slug sql.Null[string] `db:"slug"`
 			Name sql.Null[string] `db:"name"`
 		}
 		Tag struct {
@@ -241,7 +220,7 @@ func (p Pg) Projects(param ProjectsQueryParam) ([]entity.ProjectListPage, error)
 			Name sql.Null[string] `db:"name"`
 		}
 	}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.ProjectListPage{}, fmt.Errorf(
 			"persistence<Pg.Projects>: %w", err)
 	} else if len(rows) == 0 {
@@ -254,12 +233,22 @@ func (p Pg) Projects(param ProjectsQueryParam) ([]entity.ProjectListPage, error)
 	for _, r := range rows {
 		if lastProject == nil || lastProject.Id != r.Id {
 			insertedTag = map[int]struct{}{}
+			cursor, err := newSortKey(
+				param.Sort, r.Id, r.Name, r.CreatedAt, r.UpdatedAt, r.Relevance,
+			).cursor(p.cursors)
+			if err != nil {
+				return []entity.ProjectListPage{}, fmt.Errorf(
+					"persistence<Pg.Projects>: %w", err)
+			}
 			projects = append(projects, entity.ProjectListPage{
 				Id:        r.Id,
+				Slug:      r.Slug,
 				Name:      r.Name,
 				Synopsis:  r.Synopsis,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now()})
+				CreatedAt: r.CreatedAt,
+				UpdatedAt: r.UpdatedAt,
+				Relevance: r.Relevance,
+				Cursor:    cursor})
 			lastProject = &projects[len(projects)-1]
 		}
 		if r.Tag.Id.Valid {
@@ -276,21 +265,36 @@ func (p Pg) Projects(param ProjectsQueryParam) ([]entity.ProjectListPage, error)
 		if r.Serie.Id.Valid && lastProject.Serie == nil {
 			lastProject.Serie = &struct {
 				Id   int
+				Slug string
 				Name string
 			}{
 				r.Serie.Id.V,
+				r.Serie.Slug.V,
 				r.Serie.Name.V}
 		}
 	}
 	return projects, nil
 }
 
+func (p Pg) CountProjects(ctx context.Context, param ExplorationQueryParam) (int, error) {
+	matches, args := exploreProjects.matches(param)
+	query := `
+		WITH matches AS (` + matches + `)
+		SELECT COUNT(*) FROM matches`
+
+	var count int
+	if err := p.conn(ctx).GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("persistence<Pg.CountProjects>: %w", err)
+	}
+	return count, nil
+}
+
 type TagQueryParams struct {
 	Page  int
 	Limit int
 }
 
-func (p Pg) ArticleTags(param TagQueryParams) ([]entity.TagStatPage, error) {
+func (p Pg) ArticleTags(ctx context.Context, param TagQueryParams) ([]entity.TagStatPage, error) {
 	query := `
 		SELECT 
 			tags.id,
@@ -298,6 +302,9 @@ func (p Pg) ArticleTags(param TagQueryParams) ([]entity.TagStatPage, error) {
 			COUNT(article_tags.article_id) AS "count"
 		FROM tags
 		JOIN article_tags ON article_tags.tag_id = tags.id
+		JOIN articles
+			ON article_tags.article_id = articles.id
+			AND articles.state = 'published'
 		GROUP BY tags.id
 		ORDER BY tags.name
 		LIMIT $2 OFFSET $1`
@@ -316,7 +323,7 @@ func (p Pg) ArticleTags(param TagQueryParams) ([]entity.TagStatPage, error) {
 		Name  string `db:"name"`
 		Count int    `db:"count"`
 	}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.TagStatPage{}, fmt.Errorf(
 			"persistence<Pg.ArticleTags>: %w", err)
 	}
@@ -331,7 +338,7 @@ func (p Pg) ArticleTags(param TagQueryParams) ([]entity.TagStatPage, error) {
 	return tagStat, nil
 }
 
-func (p Pg) ProjectTags(param TagQueryParams) ([]entity.TagStatPage, error) {
+func (p Pg) ProjectTags(ctx context.Context, param TagQueryParams) ([]entity.TagStatPage, error) {
 	query := `
 		SELECT
 			tags.id,
@@ -339,6 +346,9 @@ func (p Pg) ProjectTags(param TagQueryParams) ([]entity.TagStatPage, error) {
 			COUNT(project_tags.project_id) AS "count"
 		FROM tags
 		JOIN project_tags ON project_tags.tag_id = tags.id
+		JOIN projects
+			ON project_tags.project_id = projects.id
+			AND projects.state = 'published'
 		GROUP BY tags.id
 		ORDER BY tags.name
 		LIMIT $2 OFFSET $1`
@@ -357,7 +367,7 @@ func (p Pg) ProjectTags(param TagQueryParams) ([]entity.TagStatPage, error) {
 		Name  string `db:"name"`
 		Count int    `db:"count"`
 	}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.TagStatPage{}, fmt.Errorf(
 			"persistence<Pg.ProjectTags>: %w", err)
 	}
@@ -373,55 +383,59 @@ func (p Pg) ProjectTags(param TagQueryParams) ([]entity.TagStatPage, error) {
 }
 
 type SerieListQueryParam struct {
-	Title string
-	Last  string
-	Limit int
+	Last   string // cursor of the entry to list after
+	Before string // cursor of the entry to list before, paging backward
+	Limit  int
+	SerieExplorationQueryParam
 }
 
-func (p Pg) SerieList(param SerieListQueryParam) ([]entity.SerieListPage, error) {
+// Series have neither a relevance nor an update time to be sorted by
+func (param SerieListQueryParam) seriesSort() Sort {
+	if param.Sort == SortRelevance || param.Sort == SortUpdated {
+		return SortCreated
+	}
+	return param.Sort
+}
+
+func (p Pg) SerieList(ctx context.Context, param SerieListQueryParam) ([]entity.SerieListPage, error) {
+	sort := param.seriesSort()
+	matches, args := serieMatches(param.SerieExplorationQueryParam)
+	pos, err := readPosition(p.cursors, param.Last, param.Before, sort)
+	if err != nil {
+		return []entity.SerieListPage{}, fmt.Errorf("persistence<Pg.SerieList>: %w", err)
+	}
+	withinPage, args := pos.where("series", "name", args)
+	limit := 10
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	args = append(args, limit)
+
 	query := `
+		WITH matches AS (` + matches + `)
 		SELECT
 			id,
+			slug,
 			name,
 			description,
 			created_at
-		FROM series
-		WHERE 
-			id > $1 
-			AND created_at >= $2
-			AND LOWER(name) LIKE $4
-		ORDER BY 
-			created_at DESC,
-			id
-		LIMIT $3`
-	args := []any{
-		0,               // $1 -> lastId
-		time.Unix(0, 0), // $2 -> lastTime
-		10,              // $3 -> limit
-		"%%"}            // $4 -> title
-	if tokens := strings.Split(param.Last, "-"); len(tokens) == 2 {
-		if lastId, err := strconv.ParseInt(tokens[1], 10, strconv.IntSize); err == nil {
-			args[0] = lastId
-		}
-		if lastTime, err := strconv.ParseInt(tokens[0], 10, strconv.IntSize); err == nil {
-			args[1] = time.Unix(0, lastTime)
-		}
-	}
-	if param.Limit > 0 {
-		args[2] = param.Limit
-	}
-	if param.Title != "" {
-		args[3] = "%" + param.Title + "%"
-	}
+		FROM (
+			SELECT *
+			FROM matches AS series
+			WHERE ` + withinPage + `
+			ORDER BY ` + pos.fetchOrder(sort, "series", "name") + `
+			LIMIT $` + strconv.Itoa(len(args)) + `
+		) AS series
+		ORDER BY ` + sort.orderBy("series", "name")
 
-	fmt.Println(args)
 	var rows []struct {
 		Id          int       `db:"id"`
+		Slug        string    `db:"slug"`
 		Name        string    `db:"name"`
 		Description string    `db:"description"`
 		CreatedAt   time.Time `db:"created_at"`
 	}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.SerieListPage{}, fmt.Errorf(
 			"persistence<Pg.Series>: %w", err)
 	}
@@ -430,14 +444,352 @@ func (p Pg) SerieList(param SerieListQueryParam) ([]entity.SerieListPage, error)
 	var last *entity.SerieListPage
 	for _, r := range rows {
 		if last == nil || last.Id != r.Id {
+			cursor, err := newSortKey(
+				sort, r.Id, r.Name, r.CreatedAt, time.Time{}, 0,
+			).cursor(p.cursors)
+			if err != nil {
+				return []entity.SerieListPage{}, fmt.Errorf(
+					"persistence<Pg.Series>: %w", err)
+			}
 			sl := entity.SerieListPage{
 				Id:          r.Id,
+				Slug:        r.Slug,
 				Name:        r.Name,
 				Description: r.Description,
-				CreatedAt:   r.CreatedAt}
+				CreatedAt:   r.CreatedAt,
+				Cursor:      cursor}
 			serieList = append(serieList, sl)
 			last = &serieList[len(serieList)-1]
 		}
 	}
 	return serieList, nil
 }
+
+func (p Pg) CountSeries(ctx context.Context, param SerieExplorationQueryParam) (int, error) {
+	matches, args := serieMatches(param)
+	query := `
+		WITH matches AS (` + matches + `)
+		SELECT COUNT(*) FROM matches`
+
+	var count int
+	if err := p.conn(ctx).GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("persistence<Pg.CountSeries>: %w", err)
+	}
+	return count, nil
+}
+
+// Filters of the global search, where a nil filter leaves out its kind of
+// entry
+type SearchQueryParam struct {
+	Limit    int // entries of each kind
+	Articles *ExplorationQueryParam
+	Projects *ExplorationQueryParam
+	Series   *SerieExplorationQueryParam
+	Tags     *TagExplorationQueryParam
+}
+
+type TagListQueryParam struct {
+	Limit int
+	TagExplorationQueryParam
+}
+
+// Tags matching the filters, the most used first
+func (p Pg) TagList(ctx context.Context, param TagListQueryParam) ([]entity.TagListPage, error) {
+	matches, args := tagMatches(param.TagExplorationQueryParam)
+	limit := 10
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	args = append(args, limit)
+
+	query := `
+		WITH matches AS (` + matches + `)
+		SELECT
+			matches.id,
+			matches.name,
+			(SELECT COUNT(*)
+				FROM article_tags
+				JOIN articles ON article_tags.article_id = articles.id
+				WHERE
+					article_tags.tag_id = matches.id
+					AND articles.state = 'published') AS n_article,
+			(SELECT COUNT(*)
+				FROM project_tags
+				JOIN projects ON project_tags.project_id = projects.id
+				WHERE
+					project_tags.tag_id = matches.id
+					AND projects.state = 'published') AS n_project
+		FROM matches
+		ORDER BY
+			n_article + n_project DESC,
+			name
+		LIMIT $` + strconv.Itoa(len(args))
+
+	var rows []struct {
+		Id       int    `db:"id"`
+		Name     string `db:"name"`
+		NArticle int    `db:"n_article"`
+		NProject int    `db:"n_project"`
+	}
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
+		return []entity.TagListPage{}, fmt.Errorf(
+			"persistence<Pg.TagList>: %w", err)
+	}
+
+	tagList := []entity.TagListPage{}
+	for _, r := range rows {
+		tagList = append(tagList, entity.TagListPage{
+			Id:       r.Id,
+			Name:     r.Name,
+			NArticle: r.NArticle,
+			NProject: r.NProject})
+	}
+	return tagList, nil
+}
+
+// The tag named `name`, regardless of its case
+func (p Pg) TagByName(ctx context.Context, name string) (entity.TagListPage, error) {
+	query := `
+		SELECT
+			tags.id,
+			tags.name,
+			(SELECT COUNT(*)
+				FROM article_tags
+				JOIN articles ON article_tags.article_id = articles.id
+				WHERE
+					article_tags.tag_id = tags.id
+					AND articles.state = 'published') AS n_article,
+			(SELECT COUNT(*)
+				FROM project_tags
+				JOIN projects ON project_tags.project_id = projects.id
+				WHERE
+					project_tags.tag_id = tags.id
+					AND projects.state = 'published') AS n_project
+		FROM tags
+		WHERE LOWER(tags.name) = LOWER($1)
+		ORDER BY tags.id
+		LIMIT 1`
+
+	var rows []struct {
+		Id       int    `db:"id"`
+		Name     string `db:"name"`
+		NArticle int    `db:"n_article"`
+		NProject int    `db:"n_project"`
+	}
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, name); err != nil {
+		return entity.TagListPage{}, fmt.Errorf("persistence<Pg.TagByName>: %w", err)
+	} else if len(rows) == 0 {
+		return entity.TagListPage{}, fmt.Errorf("persistence<Pg.TagByName>: %w", oops.NotFound{})
+	}
+	return entity.TagListPage{
+		Id:       rows[0].Id,
+		Name:     rows[0].Name,
+		NArticle: rows[0].NArticle,
+		NProject: rows[0].NProject}, nil
+}
+
+func (p Pg) CountTags(ctx context.Context, param TagExplorationQueryParam) (int, error) {
+	matches, args := tagMatches(param)
+	query := `
+		WITH matches AS (` + matches + `)
+		SELECT COUNT(*) FROM matches`
+
+	var count int
+	if err := p.conn(ctx).GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("persistence<Pg.CountTags>: %w", err)
+	}
+	return count, nil
+}
+
+// A table explored through `ExplorationQueryParam`
+type explorable struct {
+	table       string
+	tagTable    string // joins the tags to the entries
+	tagColumn   string // column of `tagTable` referencing the entry
+	serieColumn string
+}
+
+var (
+	exploreArticles = explorable{
+		table:       "articles",
+		tagTable:    "article_tags",
+		tagColumn:   "article_id",
+		serieColumn: "serie_id"}
+	exploreProjects = explorable{
+		table:       "projects",
+		tagTable:    "project_tags",
+		tagColumn:   "project_id",
+		serieColumn: "devblog_serie"}
+)
+
+// Selects the published entries satisfying the filters alongside their
+// `relevance`, using the first 9 returned arguments
+func (e explorable) matches(param ExplorationQueryParam) (string, []any) {
+	args := []any{
+		nil, // $1 -> tag filter
+		nil, // $2 -> tag filter groups
+		nil, // $3 -> serie filter
+		nil, // $4 -> keywords
+		nil, // $5 -> excluded tags
+		nil, // $6 -> excluded series
+		nil, // $7 -> created from
+		nil, // $8 -> created until
+		nil, // $9 -> serie ids filter
+	}
+	if tags, groups := param.includedTags(); len(tags) > 0 {
+		args[0] = tags
+		args[1] = groups
+	}
+	if len(param.Include.Serie) > 0 {
+		args[2] = param.Include.Serie
+	}
+	if len(param.Include.Keyword) > 0 || len(param.Exclude.Keyword) > 0 {
+		args[3] = webSearchQuery(param.Include.Keyword, param.Exclude.Keyword)
+	}
+	if len(param.Exclude.Tag) > 0 {
+		args[4] = param.Exclude.Tag
+	}
+	if len(param.Exclude.Serie) > 0 {
+		args[5] = param.Exclude.Serie
+	}
+	args[6], args[7] = param.Created.args()
+	if len(param.Include.SerieId) > 0 {
+		args[8] = param.Include.SerieId
+	}
+
+	query := strings.NewReplacer(
+		"{table}", e.table,
+		"{tagTable}", e.tagTable,
+		"{tagColumn}", e.tagColumn,
+		"{serieColumn}", e.serieColumn,
+	).Replace(`
+		SELECT
+			{table}.*,
+			COALESCE(
+				ts_rank(search_vector, websearch_to_tsquery('english', $4)),
+				0) AS relevance
+		FROM {table}
+		WHERE
+			{table}.state = 'published'
+			AND ($4::TEXT IS NULL
+				OR search_vector @@ websearch_to_tsquery('english', $4))
+			AND ($1::VARCHAR[] IS NULL
+				OR (
+					SELECT COUNT(DISTINCT wanted.tag_group)
+					FROM UNNEST($1::VARCHAR[], $2::INT[]) AS wanted(name, tag_group)
+					JOIN tags ON LOWER(tags.name) = wanted.name
+					JOIN {tagTable} ON {tagTable}.tag_id = tags.id
+					WHERE {tagTable}.{tagColumn} = {table}.id
+				) = (
+					SELECT COUNT(DISTINCT tag_group)
+					FROM UNNEST($2::INT[]) AS tag_group))
+			AND ($3::VARCHAR[] IS NULL
+				OR EXISTS(
+					SELECT 1
+					FROM series
+					WHERE
+						series.id = {table}.{serieColumn}
+						AND LOWER(series.name) = ANY($3)))
+			AND ($5::VARCHAR[] IS NULL
+				OR NOT EXISTS (
+					SELECT 1
+					FROM {tagTable}
+					JOIN tags ON {tagTable}.tag_id = tags.id
+					WHERE
+						{tagTable}.{tagColumn} = {table}.id
+						AND LOWER(tags.name) = ANY($5)))
+			AND ($6::VARCHAR[] IS NULL
+				OR NOT EXISTS (
+					SELECT 1
+					FROM series
+					WHERE
+						series.id = {table}.{serieColumn}
+						AND LOWER(series.name) = ANY($6)))
+			AND ($7::TIMESTAMP IS NULL OR {table}.created_at >= $7)
+			AND ($8::TIMESTAMP IS NULL OR {table}.created_at < $8)
+			AND ($9::INT[] IS NULL OR {table}.{serieColumn} = ANY($9))`)
+	return query, args
+}
+
+// Selects the published series satisfying the filters, using the first 4
+// returned arguments
+func serieMatches(param SerieExplorationQueryParam) (string, []any) {
+	args := []any{
+		[]string{}, // $1 -> included names
+		[]string{}, // $2 -> excluded names
+		nil,        // $3 -> created from
+		nil,        // $4 -> created until
+	}
+	if len(param.Include.Name) > 0 {
+		args[0] = containsPatterns(param.Include.Name)
+	}
+	if len(param.Exclude.Name) > 0 {
+		args[1] = containsPatterns(param.Exclude.Name)
+	}
+	args[2], args[3] = param.Created.args()
+
+	query := `
+		SELECT *
+		FROM series
+		WHERE
+			state = 'published'
+			AND LOWER(name) LIKE ALL($1)
+			AND NOT LOWER(name) LIKE ANY($2)
+			AND ($3::TIMESTAMP IS NULL OR created_at >= $3)
+			AND ($4::TIMESTAMP IS NULL OR created_at < $4)`
+	return query, args
+}
+
+// Selects the tags satisfying the filters, using the first 2 returned
+// arguments
+func tagMatches(param TagExplorationQueryParam) (string, []any) {
+	args := []any{
+		[]string{}, // $1 -> included names
+		[]string{}, // $2 -> excluded names
+	}
+	if len(param.Include.Name) > 0 {
+		args[0] = containsPatterns(param.Include.Name)
+	}
+	if len(param.Exclude.Name) > 0 {
+		args[1] = containsPatterns(param.Exclude.Name)
+	}
+
+	query := `
+		SELECT *
+		FROM tags
+		WHERE
+			LOWER(name) LIKE ALL($1)
+			AND NOT LOWER(name) LIKE ANY($2)`
+	return query, args
+}
+
+// Turns values into `LIKE` patterns matching strings that contain them
+func containsPatterns(values []string) []string {
+	escape := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	var patterns []string
+	for _, v := range values {
+		patterns = append(patterns, "%"+escape.Replace(v)+"%")
+	}
+	return patterns
+}
+
+// Turns keywords into a `websearch_to_tsquery` input where every included
+// keyword must match and none of the excluded may. Keywords with spaces are
+// searched as phrases
+func webSearchQuery(include, exclude []string) string {
+	var terms []string
+	term := func(k string) string {
+		k = strings.ReplaceAll(k, `"`, "")
+		if strings.ContainsRune(k, ' ') {
+			k = `"` + k + `"`
+		}
+		return k
+	}
+	for _, k := range include {
+		terms = append(terms, term(k))
+	}
+	for _, k := range exclude {
+		terms = append(terms, "-"+term(k))
+	}
+	return strings.Join(terms, " ")
+}
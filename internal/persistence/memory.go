@@ -0,0 +1,292 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+)
+
+// An in-memory store mirroring the schema `Pg` works with. Meant for demo
+// runs and tests where a live Postgres isn't available, so it favors
+// simplicity over speed: every query is a scan over the rows.
+type Memory struct {
+	mu      *sync.RWMutex
+	state   *memState
+	cursors cursor.Sealer // seals the cursors of listings
+}
+
+func NewMemory(cursors cursor.Sealer) Memory {
+	return Memory{
+		mu:      &sync.RWMutex{},
+		state:   newMemState(),
+		cursors: cursors}
+}
+
+type memArticle struct {
+	Id         int
+	Slug       string
+	Title      string
+	Subtitle   string
+	Content    string
+	Thumbnail  string
+	SerieId    sql.Null[int]
+	SerieOrder sql.Null[int]
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	State      entity.PublicationState
+	PublishAt  sql.Null[time.Time]
+}
+
+type memProject struct {
+	Id           int
+	Slug         string
+	DevblogSerie sql.Null[int]
+	Name         string
+	Thumbnail    sql.Null[string]
+	Synopsis     string
+	Description  string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	State        entity.PublicationState
+	PublishAt    sql.Null[time.Time]
+}
+
+type memSerie struct {
+	Id          int
+	Slug        string
+	Name        string
+	Thumbnail   string
+	Description string
+	CreatedAt   time.Time
+	State       entity.PublicationState
+	PublishAt   sql.Null[time.Time]
+}
+
+type memTag struct {
+	Id   int
+	Name string
+}
+
+type memArticleTag struct {
+	Id        int
+	ArticleId int
+	TagId     int
+}
+
+type memProjectTag struct {
+	Id        int
+	ProjectId int
+	TagId     int
+}
+
+type memProjectLink struct {
+	Id          int
+	ProjectId   int
+	DisplayText string
+	Url         string
+}
+
+// Whether an entry shows up in listings, counts and searches
+func listed(state entity.PublicationState) bool {
+	return state == entity.StatePublished
+}
+
+// Whether the page of an entry can be reached through its link
+func reachable(state entity.PublicationState) bool {
+	return state == entity.StatePublished || state == entity.StateArchived
+}
+
+// A row of `article_revisions` or `project_revisions`, whose owner is the
+// article or project it was recorded for
+type memRevision struct {
+	Id        int
+	OwnerId   int
+	Title     string
+	Content   string
+	Message   string
+	CreatedAt time.Time
+}
+
+// Tables of the store, keyed by their primary key
+type memState struct {
+	articles     map[int]memArticle
+	projects     map[int]memProject
+	series       map[int]memSerie
+	tags         map[int]memTag
+	articleTags  map[int]memArticleTag
+	projectTags  map[int]memProjectTag
+	projectLinks map[int]memProjectLink
+
+	articleRevisions map[int]memRevision
+	projectRevisions map[int]memRevision
+
+	// `slug_redirects`, leading former slugs to the id of their entry
+	slugRedirects map[memSlug]int
+
+	// next value of each table's `SERIAL` id
+	serial map[string]int
+}
+
+func newMemState() *memState {
+	return &memState{
+		articles:     map[int]memArticle{},
+		projects:     map[int]memProject{},
+		series:       map[int]memSerie{},
+		tags:         map[int]memTag{},
+		articleTags:  map[int]memArticleTag{},
+		projectTags:  map[int]memProjectTag{},
+		projectLinks: map[int]memProjectLink{},
+
+		articleRevisions: map[int]memRevision{},
+		projectRevisions: map[int]memRevision{},
+		slugRedirects:    map[memSlug]int{},
+		serial:           map[string]int{}}
+}
+
+func (s *memState) clone() *memState {
+	return &memState{
+		articles:     maps.Clone(s.articles),
+		projects:     maps.Clone(s.projects),
+		series:       maps.Clone(s.series),
+		tags:         maps.Clone(s.tags),
+		articleTags:  maps.Clone(s.articleTags),
+		projectTags:  maps.Clone(s.projectTags),
+		projectLinks: maps.Clone(s.projectLinks),
+
+		articleRevisions: maps.Clone(s.articleRevisions),
+		projectRevisions: maps.Clone(s.projectRevisions),
+		slugRedirects:    maps.Clone(s.slugRedirects),
+		serial:           maps.Clone(s.serial)}
+}
+
+// Returns the next id of `table`, just like `SERIAL` would
+func (s *memState) nextId(table string) int {
+	s.serial[table]++
+	return s.serial[table]
+}
+
+// Keeps the `SERIAL` of `table` ahead of an explicitly given id, like
+// `Pg.claimIds`
+func (s *memState) claimId(table string, id int) {
+	if id > s.serial[table] {
+		s.serial[table] = id
+	}
+}
+
+// Reads from the store while holding the read lock. Every read is a quick
+// scan, so the context is only checked before starting
+func (m Memory) view(ctx context.Context, fx func(s *memState) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if draft, ok := m.draft(ctx); ok {
+		return fx(draft)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return fx(m.state)
+}
+
+// Applies `fx` to the store as a single statement would: either every
+// change is kept, or none when `fx` fails
+func (m Memory) mutate(ctx context.Context, fx func(s *memState) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if draft, ok := m.draft(ctx); ok {
+		next := draft.clone()
+		if err := fx(next); err != nil {
+			return err
+		}
+		*draft = *next
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	draft := m.state.clone()
+	if err := fx(draft); err != nil {
+		return err
+	}
+	*m.state = *draft
+	return nil
+}
+
+type memTxKey struct{}
+
+// A transaction carried by the context: the draft its writes go to, and the
+// state it's drafted from
+type memTx struct {
+	state *memState
+	draft *memState
+}
+
+func (m Memory) draft(ctx context.Context) (*memState, bool) {
+	t, ok := ctx.Value(memTxKey{}).(memTx)
+	if !ok || t.state != m.state {
+		return nil, false
+	}
+	return t.draft, true
+}
+
+// Runs `fx` the way `Pg.Atomic` does. The store stays locked until `fx` is
+// done, so the transaction is the only one at a time
+func (m Memory) Atomic(ctx context.Context, commit bool, fx func(ctx context.Context) error) error {
+	if _, ok := m.draft(ctx); ok {
+		return fx(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	draft := m.state.clone()
+	if err := fx(context.WithValue(ctx, memTxKey{}, memTx{state: m.state, draft: draft})); err != nil {
+		return fmt.Errorf("persistence<Memory.Atomic>: %w", err)
+	}
+	if commit {
+		*m.state = *draft
+	}
+	return nil
+}
+
+// A rough stand-in of Postgres' full-text search: every keyword should
+// appear within the fields, where the earlier fields weigh more. Gives
+// whether all of them matched and the relevance
+func memRelevance(keywords []string, fields ...string) (float32, bool) {
+	weights := []float32{1, 0.4, 0.2, 0.1}
+	var relevance float32
+	for _, k := range keywords {
+		k = strings.ToLower(k)
+		matched := false
+		for idx, f := range fields {
+			if n := strings.Count(strings.ToLower(f), k); n > 0 {
+				matched = true
+				relevance += weights[min(idx, len(weights)-1)] * float32(n)
+			}
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+	return relevance, true
+}
+
+// Current time in the precision of a Postgres `TIMESTAMP`
+func memNow() time.Time {
+	return time.Now().Round(0).Truncate(time.Microsecond)
+}
+
+// The time `t` as a Postgres `TIMESTAMP` would keep it, or `now` when it's
+// zero
+func memTimeOr(t time.Time, now time.Time) time.Time {
+	if t.IsZero() {
+		return now
+	}
+	return t.Round(0).Truncate(time.Microsecond)
+}
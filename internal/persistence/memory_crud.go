@@ -0,0 +1,631 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+func (m Memory) InsertArticles(ctx context.Context, articles []entity.WriteArticle, contents []string) ([]int, error) {
+	var ids []int
+	err := m.mutate(ctx, func(s *memState) error {
+		now := memNow()
+		for idx, a := range articles {
+			id := s.nextId("articles")
+			ids = append(ids, id)
+			slug, err := s.pickSlug(entity.SlugArticle, id, a.Slug, "", a.Title)
+			if err != nil {
+				return err
+			}
+			s.moveSlug(entity.SlugArticle, id, "", slug)
+			s.articles[id] = memArticle{
+				Id:        id,
+				Slug:      slug,
+				Title:     a.Title,
+				Subtitle:  a.Subtitle,
+				Content:   contents[idx],
+				CreatedAt: memTimeOr(a.CreatedAt, now),
+				UpdatedAt: memTimeOr(a.UpdatedAt, now),
+				State:     a.State,
+				PublishAt: nullTime(a.PublishAt)}
+			s.recordArticleRevision(s.articles[id], a.Message, now)
+			s.illustrateArticle(id, a.Thumbnail)
+			if err := s.placeArticle(id, a.Serie); err != nil {
+				return err
+			}
+			s.tagArticle(id, a.Tags)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Memory.InsertArticles>: %w", err)
+	}
+	return ids, nil
+}
+
+func (m Memory) UpsertArticles(ctx context.Context, articles []entity.WriteArticle, contents []string) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		now := memNow()
+		s.unplaceArticles(articles)
+		for idx, a := range articles {
+			row, ok := s.articles[a.Id]
+			if !ok {
+				s.claimId("articles", a.Id)
+				row = memArticle{
+					Id:        a.Id,
+					CreatedAt: now}
+			}
+			slug, err := s.pickSlug(entity.SlugArticle, a.Id, a.Slug, row.Slug, a.Title)
+			if err != nil {
+				return err
+			}
+			s.moveSlug(entity.SlugArticle, a.Id, row.Slug, slug)
+			row.Slug = slug
+			row.Title = a.Title
+			row.Subtitle = a.Subtitle
+			row.Content = contents[idx]
+			if state, keep := upsertedState(a.State); !keep || !ok {
+				row.State = state
+				row.PublishAt = nullTime(a.PublishAt)
+			}
+			row.CreatedAt = memTimeOr(a.CreatedAt, row.CreatedAt)
+			row.UpdatedAt = memTimeOr(a.UpdatedAt, now)
+			s.articles[a.Id] = row
+			s.recordArticleRevision(row, a.Message, now)
+			s.illustrateArticle(a.Id, a.Thumbnail)
+			if err := s.placeArticle(a.Id, a.Serie); err != nil {
+				return err
+			}
+			s.tagArticle(a.Id, a.Tags)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.UpsertArticles>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) DeleteArticles(ctx context.Context, articles []entity.DeleteById) error {
+	if err := m.mutate(ctx, func(s *memState) error {
+		for _, a := range articles {
+			delete(s.articles, a.Id)
+			for id, at := range s.articleTags {
+				if at.ArticleId == a.Id {
+					delete(s.articleTags, id)
+				}
+			}
+			for id, r := range s.articleRevisions {
+				if r.OwnerId == a.Id {
+					delete(s.articleRevisions, id)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("persistence<Memory.DeleteArticles>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) InsertArticlesTags(ctx context.Context, articleTags []entity.WriteArticleTag) ([]int, error) {
+	var ids []int
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, at := range articleTags {
+			row := memArticleTag{
+				Id:        s.nextId("article_tags"),
+				ArticleId: at.ArticleId,
+				TagId:     at.TagId}
+			if err := s.checkArticleTag(row); err != nil {
+				return err
+			}
+			s.articleTags[row.Id] = row
+			ids = append(ids, row.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Memory.InsertArticleTags>: %w", err)
+	}
+	return ids, nil
+}
+
+func (m Memory) UpsertArticleTags(ctx context.Context, articleTags []entity.WriteArticleTag) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, at := range articleTags {
+			s.claimId("article_tags", at.Id)
+			row := memArticleTag{
+				Id:        at.Id,
+				ArticleId: at.ArticleId,
+				TagId:     at.TagId}
+			if err := s.checkArticleTag(row); err != nil {
+				return err
+			}
+			s.articleTags[row.Id] = row
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.UpsertArticleTags>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) DeleteArticleTags(ctx context.Context, articleTags []entity.DeleteById) error {
+	if err := m.mutate(ctx, func(s *memState) error {
+		for _, at := range articleTags {
+			delete(s.articleTags, at.Id)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("persistence<Memory.DeleteArticleTags>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) InsertProjects(ctx context.Context, projects []entity.WriteProject, contents []string) ([]int, error) {
+	var ids []int
+	err := m.mutate(ctx, func(s *memState) error {
+		now := memNow()
+		for idx, p := range projects {
+			id := s.nextId("projects")
+			ids = append(ids, id)
+			slug, err := s.pickSlug(entity.SlugProject, id, p.Slug, "", p.Name)
+			if err != nil {
+				return err
+			}
+			s.moveSlug(entity.SlugProject, id, "", slug)
+			s.projects[id] = memProject{
+				Id:          id,
+				Slug:        slug,
+				Name:        p.Name,
+				Synopsis:    p.Synopsis,
+				Description: contents[idx],
+				CreatedAt:   memTimeOr(p.CreatedAt, now),
+				UpdatedAt:   memTimeOr(p.UpdatedAt, now),
+				State:       p.State,
+				PublishAt:   nullTime(p.PublishAt)}
+			s.recordProjectRevision(s.projects[id], p.Message, now)
+			s.illustrateProject(id, p.Thumbnail)
+			if err := s.placeProject(id, p.Serie); err != nil {
+				return err
+			}
+			s.tagProject(id, p.Tags)
+			s.linkProject(id, p.Links)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Memory.InsertProjects>: %w", err)
+	}
+	return ids, nil
+}
+
+func (m Memory) UpsertProjects(ctx context.Context, projects []entity.WriteProject, contents []string) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		now := memNow()
+		for idx, p := range projects {
+			row, ok := s.projects[p.Id]
+			if !ok {
+				s.claimId("projects", p.Id)
+				row = memProject{
+					Id:        p.Id,
+					CreatedAt: now}
+			}
+			slug, err := s.pickSlug(entity.SlugProject, p.Id, p.Slug, row.Slug, p.Name)
+			if err != nil {
+				return err
+			}
+			s.moveSlug(entity.SlugProject, p.Id, row.Slug, slug)
+			row.Slug = slug
+			row.Name = p.Name
+			row.Synopsis = p.Synopsis
+			row.Description = contents[idx]
+			if state, keep := upsertedState(p.State); !keep || !ok {
+				row.State = state
+				row.PublishAt = nullTime(p.PublishAt)
+			}
+			row.CreatedAt = memTimeOr(p.CreatedAt, row.CreatedAt)
+			row.UpdatedAt = memTimeOr(p.UpdatedAt, now)
+			s.projects[p.Id] = row
+			s.recordProjectRevision(row, p.Message, now)
+			s.illustrateProject(p.Id, p.Thumbnail)
+			if err := s.placeProject(p.Id, p.Serie); err != nil {
+				return err
+			}
+			s.tagProject(p.Id, p.Tags)
+			s.linkProject(p.Id, p.Links)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.UpsertProjects>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) DeleteProjects(ctx context.Context, projects []entity.DeleteById) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, p := range projects {
+			for _, pl := range s.projectLinks {
+				if pl.ProjectId == p.Id {
+					return fmt.Errorf(
+						"project %d is still referenced by project_links", p.Id)
+				}
+			}
+			delete(s.projects, p.Id)
+			for id, r := range s.projectRevisions {
+				if r.OwnerId == p.Id {
+					delete(s.projectRevisions, id)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.DeleteProjects>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) InsertProjectTags(ctx context.Context, projectTags []entity.WriteProjectTag) ([]int, error) {
+	var ids []int
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, pt := range projectTags {
+			row := memProjectTag{
+				Id:        s.nextId("project_tags"),
+				ProjectId: pt.ProjectId,
+				TagId:     pt.TagId}
+			if err := s.checkProjectTag(row); err != nil {
+				return err
+			}
+			s.projectTags[row.Id] = row
+			ids = append(ids, row.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Memory.InsertProjectTags>: %w", err)
+	}
+	return ids, nil
+}
+
+func (m Memory) UpsertProjectTags(ctx context.Context, projectTags []entity.WriteProjectTag) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, pt := range projectTags {
+			s.claimId("project_tags", pt.Id)
+			row := memProjectTag{
+				Id:        pt.Id,
+				ProjectId: pt.ProjectId,
+				TagId:     pt.TagId}
+			if err := s.checkProjectTag(row); err != nil {
+				return err
+			}
+			s.projectTags[row.Id] = row
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.UpsertProjectTags>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) DeleteProjectTags(ctx context.Context, projectTags []entity.DeleteById) error {
+	if err := m.mutate(ctx, func(s *memState) error {
+		for _, pt := range projectTags {
+			delete(s.projectTags, pt.Id)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("persistence<Memory.DeleteProjectTags>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) InsertProjectLinks(ctx context.Context, projectLinks []entity.WriteProjectLink) ([]int, error) {
+	var ids []int
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, pl := range projectLinks {
+			row := memProjectLink{
+				Id:          s.nextId("project_links"),
+				ProjectId:   pl.ProjectId,
+				DisplayText: pl.DisplayText,
+				Url:         pl.Url}
+			if err := s.checkProjectLink(row); err != nil {
+				return err
+			}
+			s.projectLinks[row.Id] = row
+			ids = append(ids, row.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Memory.InsertProjectLinks>: %w", err)
+	}
+	return ids, nil
+}
+
+func (m Memory) UpsertProjectLinks(ctx context.Context, projectLinks []entity.WriteProjectLink) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, pl := range projectLinks {
+			s.claimId("project_links", pl.Id)
+			row := memProjectLink{
+				Id:          pl.Id,
+				ProjectId:   pl.ProjectId,
+				DisplayText: pl.DisplayText,
+				Url:         pl.Url}
+			if err := s.checkProjectLink(row); err != nil {
+				return err
+			}
+			s.projectLinks[row.Id] = row
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.UpsertProjectLinks>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) DeleteProjectLinks(ctx context.Context, projectLinks []entity.DeleteById) error {
+	if err := m.mutate(ctx, func(s *memState) error {
+		for _, pl := range projectLinks {
+			delete(s.projectLinks, pl.Id)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("persistence<Memory.DeleteProjectLinks>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) InsertTags(ctx context.Context, tags []entity.WriteTag) ([]int, error) {
+	var ids []int
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, t := range tags {
+			row := memTag{
+				Id:   s.nextId("tags"),
+				Name: t.Name}
+			if err := s.checkTag(row); err != nil {
+				return err
+			}
+			s.tags[row.Id] = row
+			ids = append(ids, row.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Memory.InsertTags>: %w", err)
+	}
+	return ids, nil
+}
+
+func (m Memory) UpsertTags(ctx context.Context, tags []entity.WriteTag) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, t := range tags {
+			s.claimId("tags", t.Id)
+			row := memTag{
+				Id:   t.Id,
+				Name: t.Name}
+			if err := s.checkTag(row); err != nil {
+				return err
+			}
+			s.tags[row.Id] = row
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.UpsertTags>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) DeleteTags(ctx context.Context, tags []entity.DeleteById) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, t := range tags {
+			for _, pt := range s.projectTags {
+				if pt.TagId == t.Id {
+					return fmt.Errorf(
+						"tag %d is still referenced by project_tags", t.Id)
+				}
+			}
+			delete(s.tags, t.Id)
+			for id, at := range s.articleTags {
+				if at.TagId == t.Id {
+					delete(s.articleTags, id)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.DeleteTags>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) InsertSeries(ctx context.Context, series []entity.WriteSerie) ([]int, error) {
+	var ids []int
+	err := m.mutate(ctx, func(s *memState) error {
+		now := memNow()
+		for _, sr := range series {
+			id := s.nextId("series")
+			ids = append(ids, id)
+			slug, err := s.pickSlug(entity.SlugSerie, id, sr.Slug, "", sr.Name)
+			if err != nil {
+				return err
+			}
+			s.moveSlug(entity.SlugSerie, id, "", slug)
+			row := memSerie{
+				Id:          id,
+				Slug:        slug,
+				Name:        sr.Name,
+				Thumbnail:   sr.Thumbnail,
+				Description: sr.Description,
+				CreatedAt:   memTimeOr(sr.CreatedAt, now),
+				State:       sr.State,
+				PublishAt:   nullTime(sr.PublishAt)}
+			if err := s.checkSerie(row); err != nil {
+				return err
+			}
+			s.series[row.Id] = row
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence<Memory.InsertSeries>: %w", err)
+	}
+	return ids, nil
+}
+
+func (m Memory) UpsertSeries(ctx context.Context, series []entity.WriteSerie) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		now := memNow()
+		for _, sr := range series {
+			row, ok := s.series[sr.Id]
+			if !ok {
+				s.claimId("series", sr.Id)
+				row = memSerie{
+					Id:        sr.Id,
+					CreatedAt: now}
+			}
+			slug, err := s.pickSlug(entity.SlugSerie, sr.Id, sr.Slug, row.Slug, sr.Name)
+			if err != nil {
+				return err
+			}
+			s.moveSlug(entity.SlugSerie, sr.Id, row.Slug, slug)
+			row.Slug = slug
+			row.Name = sr.Name
+			row.Thumbnail = sr.Thumbnail
+			row.Description = sr.Description
+			row.CreatedAt = memTimeOr(sr.CreatedAt, row.CreatedAt)
+			if state, keep := upsertedState(sr.State); !keep || !ok {
+				row.State = state
+				row.PublishAt = nullTime(sr.PublishAt)
+			}
+			if err := s.checkSerie(row); err != nil {
+				return err
+			}
+			s.series[row.Id] = row
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.UpsertSeries>: %w", err)
+	}
+	return nil
+}
+
+func (m Memory) DeleteSeries(ctx context.Context, series []entity.DeleteById) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		for _, sr := range series {
+			for _, a := range s.articles {
+				if a.SerieId.Valid && a.SerieId.V == sr.Id {
+					return fmt.Errorf(
+						"serie %d is still referenced by articles", sr.Id)
+				}
+			}
+			delete(s.series, sr.Id)
+			for id, p := range s.projects {
+				if p.DevblogSerie.Valid && p.DevblogSerie.V == sr.Id {
+					p.DevblogSerie.Valid = false
+					s.projects[id] = p
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.DeleteSeries>: %w", err)
+	}
+	return nil
+}
+
+func (s *memState) recordArticleRevision(a memArticle, message string, at time.Time) {
+	id := s.nextId("article_revisions")
+	s.articleRevisions[id] = memRevision{
+		Id:        id,
+		OwnerId:   a.Id,
+		Title:     a.Title,
+		Content:   a.Content,
+		Message:   message,
+		CreatedAt: at}
+}
+
+func (s *memState) recordProjectRevision(p memProject, message string, at time.Time) {
+	id := s.nextId("project_revisions")
+	s.projectRevisions[id] = memRevision{
+		Id:        id,
+		OwnerId:   p.Id,
+		Title:     p.Name,
+		Content:   p.Description,
+		Message:   message,
+		CreatedAt: at}
+}
+
+// Enforces the foreign keys and `UNIQUE(article_id, tag_id)` of `article_tags`
+func (s *memState) checkArticleTag(row memArticleTag) error {
+	if _, ok := s.articles[row.ArticleId]; !ok {
+		return fmt.Errorf("article %d doesn't exist", row.ArticleId)
+	} else if _, ok := s.tags[row.TagId]; !ok {
+		return fmt.Errorf("tag %d doesn't exist", row.TagId)
+	}
+	for _, at := range s.articleTags {
+		if at.Id != row.Id && at.ArticleId == row.ArticleId && at.TagId == row.TagId {
+			return fmt.Errorf(
+				"tag %d is already attached to article %d", row.TagId, row.ArticleId)
+		}
+	}
+	return nil
+}
+
+// Enforces the foreign key and `UNIQUE(tag_id, project_id)` of `project_tags`
+func (s *memState) checkProjectTag(row memProjectTag) error {
+	if _, ok := s.tags[row.TagId]; !ok {
+		return fmt.Errorf("tag %d doesn't exist", row.TagId)
+	}
+	for _, pt := range s.projectTags {
+		if pt.Id != row.Id && pt.ProjectId == row.ProjectId && pt.TagId == row.TagId {
+			return fmt.Errorf(
+				"tag %d is already attached to project %d", row.TagId, row.ProjectId)
+		}
+	}
+	return nil
+}
+
+// Enforces the foreign key and `UNIQUE(project_id, url)` of `project_links`
+func (s *memState) checkProjectLink(row memProjectLink) error {
+	if _, ok := s.projects[row.ProjectId]; !ok {
+		return fmt.Errorf("project %d doesn't exist", row.ProjectId)
+	}
+	for _, pl := range s.projectLinks {
+		if pl.Id != row.Id && pl.ProjectId == row.ProjectId && pl.Url == row.Url {
+			return fmt.Errorf(
+				"link %q is already attached to project %d", row.Url, row.ProjectId)
+		}
+	}
+	return nil
+}
+
+// Enforces `UNIQUE(name)` of `tags`
+func (s *memState) checkTag(row memTag) error {
+	for _, t := range s.tags {
+		if t.Id != row.Id && t.Name == row.Name {
+			return fmt.Errorf("tag %q already exists", row.Name)
+		}
+	}
+	return nil
+}
+
+// Enforces `UNIQUE(name)` of `series`
+func (s *memState) checkSerie(row memSerie) error {
+	for _, sr := range s.series {
+		if sr.Id != row.Id && sr.Name == row.Name {
+			return fmt.Errorf("serie %q already exists", row.Name)
+		}
+	}
+	return nil
+}
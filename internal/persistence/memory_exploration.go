@@ -0,0 +1,520 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+func (m Memory) Articles(ctx context.Context, param ArticlesQueryParam) ([]entity.ArticleListPage, error) {
+	limit := 10
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	pos, err := readPosition(m.cursors, param.Last, param.Before, param.Sort)
+	if err != nil {
+		return []entity.ArticleListPage{}, fmt.Errorf("persistence<Memory.Articles>: %w", err)
+	}
+
+	articles := []entity.ArticleListPage{}
+	if err := m.view(ctx, func(s *memState) error {
+		var rows []memArticle
+		keys := map[int]sortKey{}
+		relevance := map[int]float32{}
+		for _, a := range s.articles {
+			if !listed(a.State) {
+				continue
+			}
+			r, ok := s.matches(
+				param.ExplorationQueryParam,
+				s.articleTagIds(a.Id),
+				a.SerieId,
+				a.Title, a.Subtitle, a.Content)
+			if !ok || !param.Created.contains(a.CreatedAt) {
+				continue
+			}
+			key := newSortKey(param.Sort, a.Id, a.Title, a.CreatedAt, a.UpdatedAt, r)
+			if !pos.admits(key) {
+				continue
+			}
+			rows = append(rows, a)
+			keys[a.Id] = key
+			relevance[a.Id] = r
+		}
+		slices.SortFunc(rows, func(x, y memArticle) int {
+			return keys[x.Id].compare(keys[y.Id])
+		})
+		rows = nearest(pos, rows, limit)
+
+		for _, r := range rows {
+			cursor, err := keys[r.Id].cursor(m.cursors)
+			if err != nil {
+				return err
+			}
+			article := entity.ArticleListPage{
+				Id:        r.Id,
+				Slug:      r.Slug,
+				Title:     r.Title,
+				Subtitle:  r.Subtitle,
+				CreatedAt: r.CreatedAt,
+				UpdatedAt: r.UpdatedAt,
+				Relevance: relevance[r.Id],
+				Cursor:    cursor}
+			if param.WithContent {
+				article.Content = r.Content
+			}
+			if serie, ok := s.series[r.SerieId.V]; r.SerieId.Valid && ok && reachable(serie.State) {
+				article.Serie = &struct {
+					Id   int
+					Slug string
+					Name string
+				}{
+					Id:   serie.Id,
+					Slug: serie.Slug,
+					Name: serie.Name}
+			}
+			for _, tagId := range s.articleTagIds(r.Id) {
+				article.Tag = append(article.Tag, entity.Tag{
+					Id:   tagId,
+					Name: s.tags[tagId].Name})
+			}
+			articles = append(articles, article)
+		}
+		return nil
+	}); err != nil {
+		return []entity.ArticleListPage{}, fmt.Errorf("persistence<Memory.Articles>: %w", err)
+	}
+	return articles, nil
+}
+
+func (m Memory) CountArticles(ctx context.Context, param ExplorationQueryParam) (int, error) {
+	count := 0
+	if err := m.view(ctx, func(s *memState) error {
+		for _, a := range s.articles {
+			if _, ok := s.matches(
+				param,
+				s.articleTagIds(a.Id),
+				a.SerieId,
+				a.Title, a.Subtitle, a.Content); ok && listed(a.State) && param.Created.contains(a.CreatedAt) {
+				count++
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("persistence<Memory.CountArticles>: %w", err)
+	}
+	return count, nil
+}
+
+func (m Memory) Projects(ctx context.Context, param ProjectsQueryParam) ([]entity.ProjectListPage, error) {
+	limit := 10
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	pos, err := readPosition(m.cursors, param.Last, param.Before, param.Sort)
+	if err != nil {
+		return []entity.ProjectListPage{}, fmt.Errorf("persistence<Memory.Projects>: %w", err)
+	}
+
+	projects := []entity.ProjectListPage{}
+	if err := m.view(ctx, func(s *memState) error {
+		var rows []memProject
+		keys := map[int]sortKey{}
+		relevance := map[int]float32{}
+		for _, p := range s.projects {
+			if !listed(p.State) {
+				continue
+			}
+			r, ok := s.matches(
+				param.ExplorationQueryParam,
+				s.projectTagIds(p.Id),
+				p.DevblogSerie,
+				p.Name, p.Synopsis, p.Description)
+			if !ok || !param.Created.contains(p.CreatedAt) {
+				continue
+			}
+			key := newSortKey(param.Sort, p.Id, p.Name, p.CreatedAt, p.UpdatedAt, r)
+			if !pos.admits(key) {
+				continue
+			}
+			rows = append(rows, p)
+			keys[p.Id] = key
+			relevance[p.Id] = r
+		}
+		slices.SortFunc(rows, func(x, y memProject) int {
+			return keys[x.Id].compare(keys[y.Id])
+		})
+		rows = nearest(pos, rows, limit)
+
+		for _, r := range rows {
+			cursor, err := keys[r.Id].cursor(m.cursors)
+			if err != nil {
+				return err
+			}
+			project := entity.ProjectListPage{
+				Id:        r.Id,
+				Slug:      r.Slug,
+				Name:      r.Name,
+				Synopsis:  r.Synopsis,
+				CreatedAt: r.CreatedAt,
+				UpdatedAt: r.UpdatedAt,
+				Relevance: relevance[r.Id],
+				Cursor:    cursor}
+			if serie, ok := s.series[r.DevblogSerie.V]; r.DevblogSerie.Valid && ok && reachable(serie.State) {
+				project.Serie = &struct {
+					Id   int
+					Slug string
+					Name string
+				}{
+					Id:   serie.Id,
+					Slug: serie.Slug,
+					Name: serie.Name}
+			}
+			for _, tagId := range s.projectTagIds(r.Id) {
+				project.Tag = append(project.Tag, entity.Tag{
+					Id:   tagId,
+					Name: s.tags[tagId].Name})
+			}
+			projects = append(projects, project)
+		}
+		return nil
+	}); err != nil {
+		return []entity.ProjectListPage{}, fmt.Errorf("persistence<Memory.Projects>: %w", err)
+	}
+	return projects, nil
+}
+
+func (m Memory) CountProjects(ctx context.Context, param ExplorationQueryParam) (int, error) {
+	count := 0
+	if err := m.view(ctx, func(s *memState) error {
+		for _, p := range s.projects {
+			if _, ok := s.matches(
+				param,
+				s.projectTagIds(p.Id),
+				p.DevblogSerie,
+				p.Name, p.Synopsis, p.Description); ok && listed(p.State) && param.Created.contains(p.CreatedAt) {
+				count++
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("persistence<Memory.CountProjects>: %w", err)
+	}
+	return count, nil
+}
+
+func (m Memory) ArticleTags(ctx context.Context, param TagQueryParams) ([]entity.TagStatPage, error) {
+	var tagStat []entity.TagStatPage
+	if err := m.view(ctx, func(s *memState) error {
+		count := map[int]int{}
+		for _, at := range s.articleTags {
+			if listed(s.articles[at.ArticleId].State) {
+				count[at.TagId]++
+			}
+		}
+		tagStat = s.tagStats(count, param)
+		return nil
+	}); err != nil {
+		return []entity.TagStatPage{}, fmt.Errorf("persistence<Memory.ArticleTags>: %w", err)
+	}
+	return tagStat, nil
+}
+
+func (m Memory) ProjectTags(ctx context.Context, param TagQueryParams) ([]entity.TagStatPage, error) {
+	var tagStat []entity.TagStatPage
+	if err := m.view(ctx, func(s *memState) error {
+		count := map[int]int{}
+		for _, pt := range s.projectTags {
+			if listed(s.projects[pt.ProjectId].State) {
+				count[pt.TagId]++
+			}
+		}
+		tagStat = s.tagStats(count, param)
+		return nil
+	}); err != nil {
+		return []entity.TagStatPage{}, fmt.Errorf("persistence<Memory.ProjectTags>: %w", err)
+	}
+	return tagStat, nil
+}
+
+func (m Memory) SerieList(ctx context.Context, param SerieListQueryParam) ([]entity.SerieListPage, error) {
+	limit := 10
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	sort := param.seriesSort()
+	pos, err := readPosition(m.cursors, param.Last, param.Before, sort)
+	if err != nil {
+		return []entity.SerieListPage{}, fmt.Errorf("persistence<Memory.SerieList>: %w", err)
+	}
+
+	var serieList []entity.SerieListPage
+	if err := m.view(ctx, func(s *memState) error {
+		var rows []memSerie
+		keys := map[int]sortKey{}
+		for _, sr := range s.series {
+			if !memSerieMatches(sr, param.SerieExplorationQueryParam) {
+				continue
+			}
+			key := newSortKey(sort, sr.Id, sr.Name, sr.CreatedAt, time.Time{}, 0)
+			if !pos.admits(key) {
+				continue
+			}
+			rows = append(rows, sr)
+			keys[sr.Id] = key
+		}
+		slices.SortFunc(rows, func(x, y memSerie) int {
+			return keys[x.Id].compare(keys[y.Id])
+		})
+		rows = nearest(pos, rows, limit)
+
+		for _, r := range rows {
+			cursor, err := keys[r.Id].cursor(m.cursors)
+			if err != nil {
+				return err
+			}
+			serieList = append(serieList, entity.SerieListPage{
+				Id:          r.Id,
+				Slug:        r.Slug,
+				Name:        r.Name,
+				Description: r.Description,
+				CreatedAt:   r.CreatedAt,
+				Cursor:      cursor})
+		}
+		return nil
+	}); err != nil {
+		return []entity.SerieListPage{}, fmt.Errorf("persistence<Memory.SerieList>: %w", err)
+	}
+	return serieList, nil
+}
+
+func (m Memory) CountSeries(ctx context.Context, param SerieExplorationQueryParam) (int, error) {
+	count := 0
+	if err := m.view(ctx, func(s *memState) error {
+		for _, sr := range s.series {
+			if memSerieMatches(sr, param) {
+				count++
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("persistence<Memory.CountSeries>: %w", err)
+	}
+	return count, nil
+}
+
+func (m Memory) TagList(ctx context.Context, param TagListQueryParam) ([]entity.TagListPage, error) {
+	limit := 10
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+
+	tagList := []entity.TagListPage{}
+	if err := m.view(ctx, func(s *memState) error {
+		nArticle, nProject := map[int]int{}, map[int]int{}
+		for _, at := range s.articleTags {
+			if listed(s.articles[at.ArticleId].State) {
+				nArticle[at.TagId]++
+			}
+		}
+		for _, pt := range s.projectTags {
+			if listed(s.projects[pt.ProjectId].State) {
+				nProject[pt.TagId]++
+			}
+		}
+		for _, t := range s.tags {
+			if memTagMatches(t, param.TagExplorationQueryParam) {
+				tagList = append(tagList, entity.TagListPage{
+					Id:       t.Id,
+					Name:     t.Name,
+					NArticle: nArticle[t.Id],
+					NProject: nProject[t.Id]})
+			}
+		}
+		return nil
+	}); err != nil {
+		return []entity.TagListPage{}, fmt.Errorf("persistence<Memory.TagList>: %w", err)
+	}
+	slices.SortFunc(tagList, func(x, y entity.TagListPage) int {
+		if c := (y.NArticle + y.NProject) - (x.NArticle + x.NProject); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Name, y.Name)
+	})
+	return tagList[:min(limit, len(tagList))], nil
+}
+
+func (m Memory) TagByName(ctx context.Context, name string) (entity.TagListPage, error) {
+	var tag entity.TagListPage
+	if err := m.view(ctx, func(s *memState) error {
+		found := false
+		for _, t := range s.tags {
+			if strings.EqualFold(t.Name, name) && (!found || t.Id < tag.Id) {
+				tag = entity.TagListPage{Id: t.Id, Name: t.Name}
+				found = true
+			}
+		}
+		if !found {
+			return oops.NotFound{}
+		}
+
+		for _, at := range s.articleTags {
+			if at.TagId == tag.Id && listed(s.articles[at.ArticleId].State) {
+				tag.NArticle++
+			}
+		}
+		for _, pt := range s.projectTags {
+			if pt.TagId == tag.Id && listed(s.projects[pt.ProjectId].State) {
+				tag.NProject++
+			}
+		}
+		return nil
+	}); err != nil {
+		return entity.TagListPage{}, fmt.Errorf("persistence<Memory.TagByName>: %w", err)
+	}
+	return tag, nil
+}
+
+func (m Memory) CountTags(ctx context.Context, param TagExplorationQueryParam) (int, error) {
+	count := 0
+	if err := m.view(ctx, func(s *memState) error {
+		for _, t := range s.tags {
+			if memTagMatches(t, param) {
+				count++
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("persistence<Memory.CountTags>: %w", err)
+	}
+	return count, nil
+}
+
+// Ids of tags attached to an article, in ascending order
+func (s *memState) articleTagIds(articleId int) []int {
+	var tagIds []int
+	for _, at := range s.articleTags {
+		if at.ArticleId == articleId {
+			tagIds = append(tagIds, at.TagId)
+		}
+	}
+	slices.Sort(tagIds)
+	return tagIds
+}
+
+// Ids of tags attached to a project, in ascending order
+func (s *memState) projectTagIds(projectId int) []int {
+	var tagIds []int
+	for _, pt := range s.projectTags {
+		if pt.ProjectId == projectId {
+			tagIds = append(tagIds, pt.TagId)
+		}
+	}
+	slices.Sort(tagIds)
+	return tagIds
+}
+
+// Tells whether an entry passes the exploration filters, alongside its
+// relevance to the keywords
+func (s *memState) matches(
+	param ExplorationQueryParam,
+	tagIds []int,
+	serieId sql.Null[int],
+	fields ...string,
+) (float32, bool) {
+	tagNames := map[string]struct{}{}
+	for _, id := range tagIds {
+		tagNames[strings.ToLower(s.tags[id].Name)] = struct{}{}
+	}
+	var serieName string
+	if serie, ok := s.series[serieId.V]; serieId.Valid && ok {
+		serieName = strings.ToLower(serie.Name)
+	}
+
+	for _, group := range param.Include.Tag {
+		if !slices.ContainsFunc(group, func(name string) bool {
+			_, ok := tagNames[name]
+			return ok
+		}) {
+			return 0, false
+		}
+	}
+	for _, name := range param.Exclude.Tag {
+		if _, ok := tagNames[name]; ok {
+			return 0, false
+		}
+	}
+	if len(param.Include.Serie) > 0 && !slices.Contains(param.Include.Serie, serieName) {
+		return 0, false
+	} else if len(param.Include.SerieId) > 0 && (!serieId.Valid || !slices.Contains(param.Include.SerieId, serieId.V)) {
+		return 0, false
+	} else if serieName != "" && slices.Contains(param.Exclude.Serie, serieName) {
+		return 0, false
+	}
+	for _, k := range param.Exclude.Keyword {
+		if _, ok := memRelevance([]string{k}, fields...); ok {
+			return 0, false
+		}
+	}
+	return memRelevance(param.Include.Keyword, fields...)
+}
+
+// Pages through the tags having non-zero `count`, sorted by their name
+func (s *memState) tagStats(count map[int]int, param TagQueryParams) []entity.TagStatPage {
+	var tagStat []entity.TagStatPage
+	for id, n := range count {
+		tagStat = append(tagStat, entity.TagStatPage{
+			Id:    id,
+			Name:  s.tags[id].Name,
+			Count: n})
+	}
+	slices.SortFunc(tagStat, func(x, y entity.TagStatPage) int {
+		return strings.Compare(x.Name, y.Name)
+	})
+
+	offset, limit := 0, 10
+	if param.Page > 0 {
+		offset = (param.Page - 1) * param.Limit
+	}
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	if offset >= len(tagStat) {
+		return nil
+	}
+	return tagStat[offset:min(offset+limit, len(tagStat))]
+}
+
+func memSerieMatches(sr memSerie, param SerieExplorationQueryParam) bool {
+	name := strings.ToLower(sr.Name)
+	return listed(sr.State) &&
+		allContained(name, param.Include.Name) &&
+		!anyContained(name, param.Exclude.Name) &&
+		param.Created.contains(sr.CreatedAt)
+}
+
+func memTagMatches(t memTag, param TagExplorationQueryParam) bool {
+	name := strings.ToLower(t.Name)
+	return allContained(name, param.Include.Name) && !anyContained(name, param.Exclude.Name)
+}
+
+func allContained(s string, values []string) bool {
+	for _, v := range values {
+		if !strings.Contains(s, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func anyContained(s string, values []string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.Contains(s, v)
+	})
+}
@@ -0,0 +1,269 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+// A store of articles in every state, created a day apart from Jan 1st, 2026
+// in the order of their id
+func memoryArticles(t *testing.T) Memory {
+	ctx := context.Background()
+	m := NewMemory(cursor.NewSealer([]byte("key")))
+	if err := m.UpsertSeries(ctx, []entity.WriteSerie{
+		{Id: 1, Name: "Go"},
+		{Id: 2, Name: "Rust"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	articles := []entity.WriteArticle{
+		{Title: "Go basics", Subtitle: "Start here", Tags: []string{"go"}, Serie: &entity.WriteSeriePart{Id: 1, Order: 1}},
+		{Title: "SQL joins", Tags: []string{"sql", "go"}},
+		{Title: "Rust intro", Tags: []string{"rust"}},
+		{Title: "Go drafts", Tags: []string{"go"}, State: entity.StateDraft},
+		{Title: "Archived go", Tags: []string{"go"}, State: entity.StateArchived},
+		{Title: "Scheduled go", Tags: []string{"go"}, State: entity.StateScheduled, PublishAt: day(60)},
+		{Title: "Tooling", Tags: []string{"tools"}, Serie: &entity.WriteSeriePart{Id: 2, Order: 1}}}
+	contents := []string{"go go go", "sql and go", "rust", "go", "go", "go", "go tooling"}
+	for idx := range articles {
+		articles[idx].Id = idx + 1
+		articles[idx].CreatedAt = day(idx + 1)
+	}
+	if err := m.UpsertArticles(ctx, articles, contents); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func day(n int) time.Time {
+	return time.Date(2026, 1, n, 0, 0, 0, 0, time.UTC)
+}
+
+func articleIds(articles []entity.ArticleListPage) []int {
+	ids := []int{}
+	for _, a := range articles {
+		ids = append(ids, a.Id)
+	}
+	return ids
+}
+
+func TestMemoryArticlesFilters(t *testing.T) {
+	ctx := context.Background()
+	m := memoryArticles(t)
+	tests := []struct {
+		name  string
+		param func(p *ExplorationQueryParam)
+		want  []int // latest creation first
+	}{
+		{"only published ones", func(p *ExplorationQueryParam) {}, []int{7, 3, 2, 1}},
+		{"a tag", func(p *ExplorationQueryParam) { p.Include.Tag = [][]string{{"go"}} }, []int{2, 1}},
+		{"every tag group", func(p *ExplorationQueryParam) {
+			p.Include.Tag = [][]string{{"go"}, {"sql"}}
+		}, []int{2}},
+		{"any tag of a group", func(p *ExplorationQueryParam) {
+			p.Include.Tag = [][]string{{"sql", "rust"}}
+		}, []int{3, 2}},
+		{"excluded tag", func(p *ExplorationQueryParam) { p.Exclude.Tag = []string{"go"} }, []int{7, 3}},
+		{"keyword", func(p *ExplorationQueryParam) { p.Include.Keyword = []string{"GO"} }, []int{7, 2, 1}},
+		{"every keyword", func(p *ExplorationQueryParam) {
+			p.Include.Keyword = []string{"go", "start"}
+		}, []int{1}},
+		{"excluded keyword", func(p *ExplorationQueryParam) { p.Exclude.Keyword = []string{"sql"} }, []int{7, 3, 1}},
+		{"serie", func(p *ExplorationQueryParam) { p.Include.Serie = []string{"go"} }, []int{1}},
+		{"excluded serie", func(p *ExplorationQueryParam) { p.Exclude.Serie = []string{"go"} }, []int{7, 3, 2}},
+		{"creation", func(p *ExplorationQueryParam) {
+			p.Created = TimeRange{From: day(2), Until: day(7)}
+		}, []int{3, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param := ArticlesQueryParam{}
+			param.Sort = SortCreated
+			tt.param(&param.ExplorationQueryParam)
+			articles, err := m.Articles(ctx, param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := articleIds(articles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got articles %v, want %v", got, tt.want)
+			}
+
+			count, err := m.CountArticles(ctx, param.ExplorationQueryParam)
+			if err != nil {
+				t.Fatal(err)
+			} else if count != len(tt.want) {
+				t.Errorf("counted %d articles, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestMemoryArticlesRelevance(t *testing.T) {
+	m := memoryArticles(t)
+	param := ArticlesQueryParam{}
+	param.Include.Keyword = []string{"go"}
+	articles, err := m.Articles(context.Background(), param)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a match in the title weighs more than several in the content
+	if got := articleIds(articles); len(got) != 3 || got[0] != 1 {
+		t.Fatalf("got articles %v, want article 1 first", got)
+	}
+	for idx := 1; idx < len(articles); idx++ {
+		if articles[idx-1].Relevance < articles[idx].Relevance {
+			t.Errorf("articles aren't ranked by their relevance: %+v", articles)
+		}
+	}
+}
+
+func TestMemoryArticlesPaging(t *testing.T) {
+	ctx := context.Background()
+	m := memoryArticles(t)
+	page := func(last, before string) []entity.ArticleListPage {
+		t.Helper()
+		param := ArticlesQueryParam{Limit: 2, Last: last, Before: before}
+		param.Sort = SortTitle
+		articles, err := m.Articles(ctx, param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return articles
+	}
+
+	// by title: Go basics, Rust intro, SQL joins, Tooling
+	first := page("", "")
+	second := page(first[1].Cursor, "")
+	third := page(second[1].Cursor, "")
+	back := page("", second[0].Cursor)
+	tests := []struct {
+		name string
+		got  []entity.ArticleListPage
+		want []int
+	}{
+		{"first page", first, []int{1, 3}},
+		{"next page", second, []int{2, 7}},
+		{"past the last page", third, []int{}},
+		{"previous page", back, []int{1, 3}},
+		{"before the first page", page("", first[0].Cursor), []int{}},
+	}
+	for _, tt := range tests {
+		if got := articleIds(tt.got); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got articles %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// a page stays put as entries come before it
+	if err := m.UpsertArticles(ctx, []entity.WriteArticle{{Id: 8, Title: "A first one"}}, []string{""}); err != nil {
+		t.Fatal(err)
+	}
+	if got := articleIds(page(first[1].Cursor, "")); !reflect.DeepEqual(got, []int{2, 7}) {
+		t.Errorf("next page after an insert: got articles %v, want [2 7]", got)
+	}
+
+	bad := []struct {
+		name  string
+		param ArticlesQueryParam
+	}{
+		{"both directions", ArticlesQueryParam{Last: first[1].Cursor, Before: first[1].Cursor}},
+		{"malformed cursor", ArticlesQueryParam{Last: "not-a-cursor"}},
+		{"cursor of another sort", ArticlesQueryParam{
+			Last:                  first[1].Cursor,
+			ExplorationQueryParam: ExplorationQueryParam{Sort: SortCreated}}},
+	}
+	for _, tt := range bad {
+		if _, err := m.Articles(ctx, tt.param); !errors.As(err, &oops.BadRequest{}) {
+			t.Errorf("%s: expected a bad request, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestMemoryArticleTags(t *testing.T) {
+	m := memoryArticles(t)
+	tests := []struct {
+		param TagQueryParams
+		want  []entity.TagStatPage
+	}{
+		{
+			// tags of unlisted articles aren't counted
+			param: TagQueryParams{},
+			want: []entity.TagStatPage{
+				{Id: 1, Name: "go", Count: 2},
+				{Id: 3, Name: "rust", Count: 1},
+				{Id: 2, Name: "sql", Count: 1},
+				{Id: 4, Name: "tools", Count: 1}}},
+		{
+			param: TagQueryParams{Page: 2, Limit: 3},
+			want:  []entity.TagStatPage{{Id: 4, Name: "tools", Count: 1}}},
+		{
+			param: TagQueryParams{Page: 3, Limit: 3}},
+	}
+	for _, tt := range tests {
+		got, err := m.ArticleTags(context.Background(), tt.param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("page %+v\n got: %+v\nwant: %+v", tt.param, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryArticlesBySerieId(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(cursor.NewSealer([]byte("key")))
+	// only told apart by their case, as series names are
+	if err := m.UpsertSeries(ctx, []entity.WriteSerie{
+		{Id: 1, Name: "Go", Slug: "go-upper"},
+		{Id: 2, Name: "go", Slug: "go-lower"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.UpsertArticles(ctx, []entity.WriteArticle{
+		{Id: 1, Title: "Upper", Serie: &entity.WriteSeriePart{Id: 1, Order: 1}},
+		{Id: 2, Title: "Lower", Serie: &entity.WriteSeriePart{Id: 2, Order: 1}},
+		{Id: 3, Title: "Loose"}}, []string{"", "", ""}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		serieId []int
+		want    []int
+	}{
+		{nil, []int{1, 2, 3}},
+		{[]int{1}, []int{1}},
+		{[]int{2}, []int{2}},
+		{[]int{1, 2}, []int{1, 2}},
+		{[]int{3}, nil},
+	}
+	for _, tt := range tests {
+		var param ArticlesQueryParam
+		param.Include.SerieId = tt.serieId
+		param.Sort = SortTitle
+		articles, err := m.Articles(ctx, param)
+		if err != nil {
+			t.Fatalf("serie %v: %v", tt.serieId, err)
+		}
+
+		got := map[int]bool{}
+		for _, a := range articles {
+			got[a.Id] = true
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("serie %v: got %+v, want articles %v", tt.serieId, articles, tt.want)
+			continue
+		}
+		for _, id := range tt.want {
+			if !got[id] {
+				t.Errorf("serie %v: missing article %d", tt.serieId, id)
+			}
+		}
+	}
+}
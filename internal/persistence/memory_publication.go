@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+func (m Memory) PublishDue(ctx context.Context) (int, error) {
+	published := 0
+	err := m.mutate(ctx, func(s *memState) error {
+		now := memNow()
+		for id, a := range s.articles {
+			if due(a.State, a.PublishAt, now) {
+				a.State = entity.StatePublished
+				a.CreatedAt, a.UpdatedAt = a.PublishAt.V, a.PublishAt.V
+				s.articles[id] = a
+				published++
+			}
+		}
+		for id, p := range s.projects {
+			if due(p.State, p.PublishAt, now) {
+				p.State = entity.StatePublished
+				p.CreatedAt, p.UpdatedAt = p.PublishAt.V, p.PublishAt.V
+				s.projects[id] = p
+				published++
+			}
+		}
+		for id, sr := range s.series {
+			if due(sr.State, sr.PublishAt, now) {
+				sr.State = entity.StatePublished
+				sr.CreatedAt = sr.PublishAt.V
+				s.series[id] = sr
+				published++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("persistence<Memory.PublishDue>: %w", err)
+	}
+	return published, nil
+}
+
+func (m Memory) UntilNextPublication(ctx context.Context) (time.Duration, bool, error) {
+	var next sql.Null[time.Time]
+	err := m.view(ctx, func(s *memState) error {
+		earliest := func(state entity.PublicationState, publishAt sql.Null[time.Time]) {
+			if state == entity.StateScheduled && publishAt.Valid &&
+				(!next.Valid || publishAt.V.Before(next.V)) {
+				next = publishAt
+			}
+		}
+		for _, a := range s.articles {
+			earliest(a.State, a.PublishAt)
+		}
+		for _, p := range s.projects {
+			earliest(p.State, p.PublishAt)
+		}
+		for _, sr := range s.series {
+			earliest(sr.State, sr.PublishAt)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("persistence<Memory.UntilNextPublication>: %w", err)
+	}
+	return time.Until(next.V), next.Valid, nil
+}
+
+func due(state entity.PublicationState, publishAt sql.Null[time.Time], now time.Time) bool {
+	return state == entity.StateScheduled && publishAt.Valid && !publishAt.V.After(now)
+}
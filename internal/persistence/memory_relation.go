@@ -0,0 +1,198 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+func (s *memState) serieBySlug(slug string) (int, error) {
+	for _, sr := range s.series {
+		if sr.Slug == slug {
+			return sr.Id, nil
+		}
+	}
+	return 0, fmt.Errorf("serie %q doesn't exist: %w", slug, oops.NotFound{})
+}
+
+func (s *memState) serieOf(part entity.WriteSeriePart) (sql.Null[int], error) {
+	if part.Id != 0 {
+		if _, ok := s.series[part.Id]; !ok {
+			return sql.Null[int]{}, fmt.Errorf("serie %d doesn't exist: %w", part.Id, oops.NotFound{})
+		}
+		return sql.Null[int]{V: part.Id, Valid: true}, nil
+	} else if part.Slug != "" {
+		id, err := s.serieBySlug(part.Slug)
+		return sql.Null[int]{V: id, Valid: err == nil}, err
+	}
+	return sql.Null[int]{}, nil
+}
+
+// Like `Pg.placeArticle`, enforcing `UNIQUE(serie_id, serie_order)` too
+func (s *memState) placeArticle(id int, part *entity.WriteSeriePart) error {
+	if part == nil {
+		return nil
+	}
+
+	serieId, err := s.serieOf(*part)
+	if err != nil {
+		return err
+	}
+	if serieId.Valid {
+		for _, a := range s.articles {
+			if a.Id != id && a.SerieId == serieId && a.SerieOrder.V == part.Order {
+				return fmt.Errorf(
+					"order %d of serie %q is already taken by article %d",
+					part.Order, s.series[serieId.V].Slug, a.Id)
+			}
+		}
+	}
+
+	row := s.articles[id]
+	row.SerieId = serieId
+	row.SerieOrder = sql.Null[int]{V: part.Order, Valid: serieId.Valid}
+	s.articles[id] = row
+	return nil
+}
+
+// Like `Pg.unplaceArticles`
+func (s *memState) unplaceArticles(articles []entity.WriteArticle) {
+	for _, a := range articles {
+		if row, ok := s.articles[a.Id]; ok && a.Serie != nil {
+			row.SerieId = sql.Null[int]{}
+			row.SerieOrder = sql.Null[int]{}
+			s.articles[a.Id] = row
+		}
+	}
+}
+
+func (s *memState) placeProject(id int, part *entity.WriteSeriePart) error {
+	if part == nil {
+		return nil
+	}
+
+	serieId, err := s.serieOf(*part)
+	if err != nil {
+		return err
+	}
+	row := s.projects[id]
+	row.DevblogSerie = serieId
+	s.projects[id] = row
+	return nil
+}
+
+func (s *memState) illustrateArticle(id int, thumbnail *string) {
+	if thumbnail == nil {
+		return
+	}
+	row := s.articles[id]
+	row.Thumbnail = *thumbnail
+	s.articles[id] = row
+}
+
+func (s *memState) illustrateProject(id int, thumbnail *string) {
+	if thumbnail == nil {
+		return
+	}
+	row := s.projects[id]
+	row.Thumbnail = sql.Null[string]{V: *thumbnail, Valid: *thumbnail != ""}
+	s.projects[id] = row
+}
+
+// Ids of the tags of `names`, making the missing ones
+func (s *memState) makeTags(names []string) []int {
+	var ids []int
+	for _, name := range names {
+		id := 0
+		for _, t := range s.tags {
+			if t.Name == name {
+				id = t.Id
+				break
+			}
+		}
+		if id == 0 {
+			id = s.nextId("tags")
+			s.tags[id] = memTag{Id: id, Name: name}
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (s *memState) tagArticle(id int, names []string) {
+	if names == nil {
+		return
+	}
+
+	wanted := s.makeTags(names)
+	for rowId, at := range s.articleTags {
+		if at.ArticleId != id {
+			continue
+		} else if idx := slices.Index(wanted, at.TagId); idx >= 0 {
+			wanted = slices.Delete(wanted, idx, idx+1)
+		} else {
+			delete(s.articleTags, rowId)
+		}
+	}
+	for _, tagId := range wanted {
+		rowId := s.nextId("article_tags")
+		s.articleTags[rowId] = memArticleTag{Id: rowId, ArticleId: id, TagId: tagId}
+	}
+}
+
+func (s *memState) tagProject(id int, names []string) {
+	if names == nil {
+		return
+	}
+
+	wanted := s.makeTags(names)
+	for rowId, pt := range s.projectTags {
+		if pt.ProjectId != id {
+			continue
+		} else if idx := slices.Index(wanted, pt.TagId); idx >= 0 {
+			wanted = slices.Delete(wanted, idx, idx+1)
+		} else {
+			delete(s.projectTags, rowId)
+		}
+	}
+	for _, tagId := range wanted {
+		rowId := s.nextId("project_tags")
+		s.projectTags[rowId] = memProjectTag{Id: rowId, ProjectId: id, TagId: tagId}
+	}
+}
+
+func (s *memState) linkProject(id int, links []entity.WriteProjectLink) {
+	if links == nil {
+		return
+	}
+
+	wanted := map[string]string{}
+	for _, l := range links {
+		if _, ok := wanted[l.Url]; !ok {
+			wanted[l.Url] = l.DisplayText
+		}
+	}
+	for rowId, l := range s.projectLinks {
+		if l.ProjectId != id {
+			continue
+		} else if text, ok := wanted[l.Url]; ok {
+			l.DisplayText = text
+			s.projectLinks[rowId] = l
+			delete(wanted, l.Url)
+		} else {
+			delete(s.projectLinks, rowId)
+		}
+	}
+	for _, l := range links {
+		if text, ok := wanted[l.Url]; ok {
+			rowId := s.nextId("project_links")
+			s.projectLinks[rowId] = memProjectLink{Id: rowId, ProjectId: id, DisplayText: text, Url: l.Url}
+			delete(wanted, l.Url)
+		}
+	}
+}
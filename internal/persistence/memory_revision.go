@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+func (m Memory) ArticleHistory(ctx context.Context, id int) (entity.ArticleHistoryPage, error) {
+	var history entity.ArticleHistoryPage
+	err := m.view(ctx, func(s *memState) error {
+		a, ok := s.articles[id]
+		if !ok || !reachable(a.State) {
+			return oops.NotFound{}
+		}
+
+		history = entity.ArticleHistoryPage{Id: a.Id, Slug: a.Slug, Title: a.Title}
+		for _, r := range s.articleRevisions {
+			if r.OwnerId == id {
+				history.Revisions = append(history.Revisions, entity.Revision{
+					Id:        r.Id,
+					Title:     r.Title,
+					Message:   r.Message,
+					CreatedAt: r.CreatedAt})
+			}
+		}
+		slices.SortFunc(history.Revisions, func(x, y entity.Revision) int {
+			return cmp.Compare(y.Id, x.Id)
+		})
+		return nil
+	})
+	if err != nil {
+		return entity.ArticleHistoryPage{}, fmt.Errorf(
+			"persistence<Memory.ArticleHistory>: %w", err)
+	}
+	return history, nil
+}
+
+func (m Memory) ArticleRevision(ctx context.Context, articleId int, revisionId int) (entity.Revision, error) {
+	var revision entity.Revision
+	err := m.view(ctx, func(s *memState) error {
+		r, ok := s.articleRevisions[revisionId]
+		if !ok || r.OwnerId != articleId || !reachable(s.articles[articleId].State) {
+			return oops.NotFound{}
+		}
+		revision = entity.Revision{
+			Id:        r.Id,
+			Title:     r.Title,
+			Content:   r.Content,
+			Message:   r.Message,
+			CreatedAt: r.CreatedAt}
+		return nil
+	})
+	if err != nil {
+		return entity.Revision{}, fmt.Errorf(
+			"persistence<Memory.ArticleRevision>: %w", err)
+	}
+	return revision, nil
+}
+
+func (m Memory) RollbackArticles(ctx context.Context, rollbacks []entity.RollbackArticle) error {
+	err := m.mutate(ctx, func(s *memState) error {
+		now := memNow()
+		for _, rb := range rollbacks {
+			r, ok := s.articleRevisions[rb.RevisionId]
+			a, exists := s.articles[rb.ArticleId]
+			if !ok || !exists || r.OwnerId != rb.ArticleId {
+				return fmt.Errorf(
+					"article %d doesn't have revision %d: %w",
+					rb.ArticleId, rb.RevisionId, oops.NotFound{})
+			}
+			a.Title = r.Title
+			a.Content = r.Content
+			a.UpdatedAt = now
+			s.articles[a.Id] = a
+			s.recordArticleRevision(a, rb.Message, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("persistence<Memory.RollbackArticles>: %w", err)
+	}
+	return nil
+}
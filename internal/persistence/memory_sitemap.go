@@ -0,0 +1,91 @@
+package persistence
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+func (m Memory) Sitemap(ctx context.Context) ([]entity.SitemapEntry, error) {
+	entries := []entity.SitemapEntry{}
+	if err := m.view(ctx, func(s *memState) error {
+		serieUpdates := map[int]time.Time{}
+		for _, sr := range s.series {
+			if reachable(sr.State) {
+				serieUpdates[sr.Id] = sr.CreatedAt
+			}
+		}
+		latest := func(updates map[int]time.Time, key int, at time.Time) {
+			if current, ok := updates[key]; !ok || at.After(current) {
+				updates[key] = at
+			}
+		}
+
+		for _, a := range s.articles {
+			if !reachable(a.State) {
+				continue
+			}
+			entries = append(entries, entity.SitemapEntry{
+				Kind:      entity.SitemapArticle,
+				Ref:       a.Slug,
+				UpdatedAt: a.UpdatedAt})
+			if _, ok := serieUpdates[a.SerieId.V]; a.SerieId.Valid && ok {
+				latest(serieUpdates, a.SerieId.V, a.UpdatedAt)
+			}
+		}
+		for _, p := range s.projects {
+			if !reachable(p.State) {
+				continue
+			}
+			entries = append(entries, entity.SitemapEntry{
+				Kind:      entity.SitemapProject,
+				Ref:       p.Slug,
+				UpdatedAt: p.UpdatedAt})
+			if _, ok := serieUpdates[p.DevblogSerie.V]; p.DevblogSerie.Valid && ok {
+				latest(serieUpdates, p.DevblogSerie.V, p.UpdatedAt)
+			}
+		}
+		for id, at := range serieUpdates {
+			entries = append(entries, entity.SitemapEntry{
+				Kind:      entity.SitemapSerie,
+				Ref:       s.series[id].Slug,
+				UpdatedAt: at})
+		}
+
+		articleTagUpdates, projectTagUpdates := map[int]time.Time{}, map[int]time.Time{}
+		for _, at := range s.articleTags {
+			if a := s.articles[at.ArticleId]; listed(a.State) {
+				latest(articleTagUpdates, at.TagId, a.UpdatedAt)
+			}
+		}
+		for _, pt := range s.projectTags {
+			if p := s.projects[pt.ProjectId]; listed(p.State) {
+				latest(projectTagUpdates, pt.TagId, p.UpdatedAt)
+			}
+		}
+		for id, at := range articleTagUpdates {
+			entries = append(entries, entity.SitemapEntry{
+				Kind:      entity.SitemapArticleTag,
+				Ref:       s.tags[id].Name,
+				UpdatedAt: at})
+		}
+		for id, at := range projectTagUpdates {
+			entries = append(entries, entity.SitemapEntry{
+				Kind:      entity.SitemapProjectTag,
+				Ref:       s.tags[id].Name,
+				UpdatedAt: at})
+		}
+		return nil
+	}); err != nil {
+		return []entity.SitemapEntry{}, fmt.Errorf("persistence<Memory.Sitemap>: %w", err)
+	}
+
+	slices.SortFunc(entries, func(x, y entity.SitemapEntry) int {
+		return cmp.Or(cmp.Compare(x.Kind, y.Kind), cmp.Compare(x.Ref, y.Ref))
+	})
+	return entries, nil
+}
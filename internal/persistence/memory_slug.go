@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+	"github.com/solsteace/misite/internal/utility/lib/slug"
+)
+
+// Primary key of `slug_redirects`
+type memSlug struct {
+	Kind entity.SlugKind
+	Slug string
+}
+
+func (m Memory) ResolveSlug(ctx context.Context, kind entity.SlugKind, ref string) (int, string, error) {
+	var id int
+	var current string
+	err := m.view(ctx, func(s *memState) error {
+		slugs := s.slugs(kind)
+		candidates := []int{}
+		for id, sl := range slugs {
+			if sl == ref {
+				candidates = append(candidates, id)
+			}
+		}
+		if parsed, err := strconv.Atoi(ref); err == nil {
+			candidates = append(candidates, parsed)
+		}
+		if target, ok := s.slugRedirects[memSlug{kind, ref}]; ok {
+			candidates = append(candidates, target)
+		}
+
+		for _, c := range candidates {
+			if sl, ok := slugs[c]; ok && s.reachableEntry(kind, c) {
+				id, current = c, sl
+				return nil
+			}
+		}
+		return oops.NotFound{}
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("persistence<Memory.ResolveSlug>: %w", err)
+	}
+	return id, current, nil
+}
+
+// Slugs of every entry of the kind, by their id
+func (s *memState) slugs(kind entity.SlugKind) map[int]string {
+	slugs := map[int]string{}
+	switch kind {
+	case entity.SlugArticle:
+		for _, a := range s.articles {
+			slugs[a.Id] = a.Slug
+		}
+	case entity.SlugProject:
+		for _, p := range s.projects {
+			slugs[p.Id] = p.Slug
+		}
+	case entity.SlugSerie:
+		for _, sr := range s.series {
+			slugs[sr.Id] = sr.Slug
+		}
+	}
+	return slugs
+}
+
+func (s *memState) reachableEntry(kind entity.SlugKind, id int) bool {
+	switch kind {
+	case entity.SlugArticle:
+		return reachable(s.articles[id].State)
+	case entity.SlugProject:
+		return reachable(s.projects[id].State)
+	case entity.SlugSerie:
+		return reachable(s.series[id].State)
+	}
+	return false
+}
+
+// Picks the slug of the entry being written the way `Pg` does: the `given`
+// one, which must not be taken by another entry, or else its `current` one
+// when it already exists, or else a free one made out of `name`
+func (s *memState) pickSlug(kind entity.SlugKind, id int, given, current, name string) (string, error) {
+	slugs := s.slugs(kind)
+	delete(slugs, id)
+	taken := map[string]struct{}{}
+	for _, sl := range slugs {
+		taken[sl] = struct{}{}
+	}
+
+	switch {
+	case given != "":
+		if _, ok := taken[given]; ok {
+			return "", fmt.Errorf("%s slug %q is already taken", kind, given)
+		}
+		return given, nil
+	case current != "":
+		return current, nil
+	}
+	return slug.Free(slugBase(kind, name), func(sl string) bool {
+		_, ok := taken[sl]
+		return ok
+	}), nil
+}
+
+// Keeps `slug_redirects` in line with an entry whose slug went from
+// `previous` (empty for new entries) to `current`
+func (s *memState) moveSlug(kind entity.SlugKind, id int, previous, current string) {
+	if previous != "" && previous != current {
+		s.slugRedirects[memSlug{kind, previous}] = id
+	}
+	delete(s.slugRedirects, memSlug{kind, current})
+}
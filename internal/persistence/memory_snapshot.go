@@ -0,0 +1,97 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+func (m Memory) Snapshot(ctx context.Context) (entity.Snapshot, error) {
+	snapshot := entity.Snapshot{}
+	if err := m.view(ctx, func(s *memState) error {
+		for _, id := range slices.Sorted(maps.Keys(s.series)) {
+			sr := s.series[id]
+			snapshot.Series = append(snapshot.Series, entity.WriteSerie{
+				Id:          sr.Id,
+				Slug:        sr.Slug,
+				Name:        sr.Name,
+				Thumbnail:   sr.Thumbnail,
+				Description: sr.Description,
+				State:       sr.State,
+				PublishAt:   sr.PublishAt.V,
+				CreatedAt:   sr.CreatedAt})
+		}
+		for _, id := range slices.Sorted(maps.Keys(s.tags)) {
+			t := s.tags[id]
+			snapshot.Tags = append(snapshot.Tags, entity.WriteTag{Id: t.Id, Name: t.Name})
+		}
+		for _, id := range slices.Sorted(maps.Keys(s.articleTags)) {
+			at := s.articleTags[id]
+			snapshot.ArticleTags = append(snapshot.ArticleTags, entity.WriteArticleTag{
+				Id:        at.Id,
+				ArticleId: at.ArticleId,
+				TagId:     at.TagId})
+		}
+		for _, id := range slices.Sorted(maps.Keys(s.projectTags)) {
+			pt := s.projectTags[id]
+			snapshot.ProjectTags = append(snapshot.ProjectTags, entity.WriteProjectTag{
+				Id:        pt.Id,
+				ProjectId: pt.ProjectId,
+				TagId:     pt.TagId})
+		}
+		for _, id := range slices.Sorted(maps.Keys(s.projectLinks)) {
+			pl := s.projectLinks[id]
+			snapshot.ProjectLinks = append(snapshot.ProjectLinks, entity.WriteProjectLink{
+				Id:          pl.Id,
+				ProjectId:   pl.ProjectId,
+				DisplayText: pl.DisplayText,
+				Url:         pl.Url})
+		}
+
+		for _, id := range slices.Sorted(maps.Keys(s.articles)) {
+			a := s.articles[id]
+			article := entity.WriteArticle{
+				Id:        a.Id,
+				Slug:      a.Slug,
+				Title:     a.Title,
+				Subtitle:  a.Subtitle,
+				Content:   a.Content,
+				Thumbnail: &a.Thumbnail,
+				State:     a.State,
+				PublishAt: a.PublishAt.V,
+				Tags:      snapshotArticleTags(snapshot, a.Id),
+				CreatedAt: a.CreatedAt,
+				UpdatedAt: a.UpdatedAt}
+			if sr, ok := s.series[a.SerieId.V]; ok && a.SerieId.Valid {
+				article.Serie = &entity.WriteSeriePart{Slug: sr.Slug, Order: a.SerieOrder.V}
+			}
+			snapshot.Articles = append(snapshot.Articles, article)
+		}
+		for _, id := range slices.Sorted(maps.Keys(s.projects)) {
+			p := s.projects[id]
+			project := entity.WriteProject{
+				Id:          p.Id,
+				Slug:        p.Slug,
+				Name:        p.Name,
+				Synopsis:    p.Synopsis,
+				Description: p.Description,
+				Thumbnail:   &p.Thumbnail.V,
+				State:       p.State,
+				PublishAt:   p.PublishAt.V,
+				Tags:        snapshotProjectTags(snapshot, p.Id),
+				CreatedAt:   p.CreatedAt,
+				UpdatedAt:   p.UpdatedAt}
+			if sr, ok := s.series[p.DevblogSerie.V]; ok && p.DevblogSerie.Valid {
+				project.Serie = &entity.WriteSeriePart{Slug: sr.Slug}
+			}
+			snapshot.Projects = append(snapshot.Projects, project)
+		}
+		return nil
+	}); err != nil {
+		return snapshot, fmt.Errorf("persistence<Memory.Snapshot>: %w", err)
+	}
+	return snapshot, nil
+}
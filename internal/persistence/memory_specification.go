@@ -0,0 +1,475 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+func (m Memory) Article(ctx context.Context, id int) (entity.ArticlePage, error) {
+	var article entity.ArticlePage
+	err := m.view(ctx, func(s *memState) error {
+		a, ok := s.articles[id]
+		if !ok || !reachable(a.State) {
+			return oops.NotFound{}
+		}
+
+		article = entity.ArticlePage{
+			Id:        a.Id,
+			Slug:      a.Slug,
+			Title:     a.Title,
+			Subtitle:  a.Subtitle,
+			Content:   a.Content,
+			CreatedAt: a.CreatedAt,
+			UpdatedAt: a.UpdatedAt}
+		if serie, ok := s.series[a.SerieId.V]; a.SerieId.Valid && ok && reachable(serie.State) {
+			article.Serie = &struct {
+				Id   int
+				Slug string
+				Name string
+			}{
+				Id:   serie.Id,
+				Slug: serie.Slug,
+				Name: serie.Name}
+		}
+		for _, tagId := range s.articleTagIds(a.Id) {
+			article.Tag = append(article.Tag, entity.Tag{
+				Id:   tagId,
+				Name: s.tags[tagId].Name})
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.ArticlePage{}, fmt.Errorf(
+			"persistence<Memory.Article>: %w", err)
+	}
+	return article, nil
+}
+
+func (m Memory) CountArticleMatchingTags(ctx context.Context, tagId []int) ([]entity.Tag, []int, error) {
+	var tags []entity.Tag
+	var count []int
+	if err := m.view(ctx, func(s *memState) error {
+		n := map[int]int{}
+		for _, at := range s.articleTags {
+			if slices.Contains(tagId, at.TagId) && listed(s.articles[at.ArticleId].State) {
+				n[at.TagId]++
+			}
+		}
+		tags, count = s.tagCounts(n)
+		return nil
+	}); err != nil {
+		return []entity.Tag{}, []int{}, fmt.Errorf("persistence<Memory.CountArticleMatchingTags>: %w", err)
+	}
+	return tags, count, nil
+}
+
+func (m Memory) Project(ctx context.Context, id int) (entity.ProjectPage, error) {
+	var project entity.ProjectPage
+	err := m.view(ctx, func(s *memState) error {
+		p, ok := s.projects[id]
+		if !ok || !reachable(p.State) {
+			return oops.NotFound{}
+		}
+
+		project = entity.ProjectPage{
+			Id:          p.Id,
+			Slug:        p.Slug,
+			Name:        p.Name,
+			Synopsis:    p.Synopsis,
+			Description: p.Description,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt}
+		if serie, ok := s.series[p.DevblogSerie.V]; p.DevblogSerie.Valid && ok && reachable(serie.State) {
+			project.Serie = &struct {
+				Id   int
+				Slug string
+				Name string
+			}{
+				Id:   serie.Id,
+				Slug: serie.Slug,
+				Name: serie.Name}
+		}
+		for _, tagId := range s.projectTagIds(p.Id) {
+			project.Tag = append(project.Tag, entity.Tag{
+				Id:   tagId,
+				Name: s.tags[tagId].Name})
+		}
+
+		var links []memProjectLink
+		for _, pl := range s.projectLinks {
+			if pl.ProjectId == p.Id {
+				links = append(links, pl)
+			}
+		}
+		slices.SortFunc(links, func(x, y memProjectLink) int { return x.Id - y.Id })
+		for _, pl := range links {
+			project.Link = append(project.Link, struct {
+				Id          int
+				DisplayText string
+				Url         string
+			}{
+				Id:          pl.Id,
+				DisplayText: pl.DisplayText,
+				Url:         pl.Url})
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.ProjectPage{}, fmt.Errorf(
+			"persistence<Memory.Project>: %w", err)
+	}
+	return project, nil
+}
+
+func (m Memory) CountProjectMatchingTags(ctx context.Context, tagId []int) ([]entity.Tag, []int, error) {
+	var tags []entity.Tag
+	var count []int
+	if err := m.view(ctx, func(s *memState) error {
+		n := map[int]int{}
+		for _, pt := range s.projectTags {
+			if slices.Contains(tagId, pt.TagId) && listed(s.projects[pt.ProjectId].State) {
+				n[pt.TagId]++
+			}
+		}
+		tags, count = s.tagCounts(n)
+		return nil
+	}); err != nil {
+		return []entity.Tag{}, []int{}, fmt.Errorf("persistence<Memory.CountProjectMatchingTags>: %w", err)
+	}
+	return tags, count, nil
+}
+
+func (m Memory) Serie(ctx context.Context, id int) (entity.SeriePage, error) {
+	var serie entity.SeriePage
+	err := m.view(ctx, func(s *memState) error {
+		sr, ok := s.series[id]
+		if !ok || !reachable(sr.State) {
+			return oops.NotFound{}
+		}
+
+		serie = entity.SeriePage{
+			Id:          sr.Id,
+			Slug:        sr.Slug,
+			Name:        sr.Name,
+			Thumbnail:   sr.Thumbnail,
+			Description: sr.Description}
+		for _, a := range s.articles {
+			if a.SerieId.Valid && a.SerieId.V == id && listed(a.State) {
+				serie.NArticle++
+			}
+		}
+		for _, p := range s.projects {
+			if p.DevblogSerie.Valid && p.DevblogSerie.V == id && listed(p.State) {
+				serie.NProject++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.SeriePage{}, fmt.Errorf(
+			"persistence<Memory.Serie>: %w", err)
+	}
+	return serie, nil
+}
+
+func (m Memory) SerieArticleList(ctx context.Context, id int, param SerieContentQueryParam) ([]entity.SeriePageArticleList, error) {
+	if param.Limit < 1 {
+		param.Limit = 10
+	}
+	last, hasLast, err := readSerieContentCursor(m.cursors, param.Last, param.Ascending)
+	if err != nil {
+		return []entity.SeriePageArticleList{}, fmt.Errorf(
+			"persistence<Memory.SerieArticleList>: %w", err)
+	}
+
+	var serieArticles []entity.SeriePageArticleList
+	if err := m.view(ctx, func(s *memState) error {
+		rows := s.serieParts(id)
+		part := map[int]int{}
+		for idx, r := range rows {
+			part[r.Id] = idx + 1
+		}
+		if !param.Ascending {
+			slices.Reverse(rows)
+		}
+		rows = slices.DeleteFunc(rows, func(r memArticle) bool {
+			if !hasLast {
+				return false
+			} else if param.Ascending {
+				return r.SerieOrder.V <= last.Order
+			}
+			return r.SerieOrder.V >= last.Order
+		})
+
+		for idx, r := range rows[:min(param.Limit, len(rows))] {
+			key := serieContentKey{
+				Order:     r.SerieOrder.V,
+				Nth:       last.Nth + idx + 1,
+				Ascending: param.Ascending}
+			cursor, err := key.cursor(m.cursors)
+			if err != nil {
+				return err
+			}
+			serieArticles = append(serieArticles, entity.SeriePageArticleList{
+				Id:        r.Id,
+				Slug:      r.Slug,
+				Part:      part[r.Id],
+				Nth:       key.Nth,
+				Cursor:    cursor,
+				Title:     r.Title,
+				Synopsis:  r.Subtitle,
+				CreatedAt: r.CreatedAt,
+				UpdatedAt: r.UpdatedAt})
+		}
+		return nil
+	}); err != nil {
+		return []entity.SeriePageArticleList{}, fmt.Errorf("persistence<Memory.SerieArticleList>: %w", err)
+	}
+	return serieArticles, nil
+}
+
+func (m Memory) SerieContents(ctx context.Context, id int) ([]entity.SerieContentEntry, error) {
+	var contents []entity.SerieContentEntry
+	if err := m.view(ctx, func(s *memState) error {
+		for idx, a := range s.serieParts(id) {
+			contents = append(contents, entity.SerieContentEntry{
+				Id:    a.Id,
+				Slug:  a.Slug,
+				Part:  idx + 1,
+				Title: a.Title})
+		}
+		return nil
+	}); err != nil {
+		return []entity.SerieContentEntry{}, fmt.Errorf("persistence<Memory.SerieContents>: %w", err)
+	}
+	return contents, nil
+}
+
+func (m Memory) ArticleSeriePart(ctx context.Context, id int) (entity.SeriePart, error) {
+	var part entity.SeriePart
+	if err := m.view(ctx, func(s *memState) error {
+		a, ok := s.articles[id]
+		if !ok || !a.SerieId.Valid {
+			return nil
+		}
+		parts := s.serieParts(a.SerieId.V)
+		idx := slices.IndexFunc(parts, func(p memArticle) bool { return p.Id == id })
+		if idx < 0 {
+			return nil
+		}
+		part = entity.SeriePart{Part: idx + 1, NPart: len(parts)}
+		if idx > 0 {
+			part.Previous = &entity.SerieContentEntry{
+				Id:    parts[idx-1].Id,
+				Slug:  parts[idx-1].Slug,
+				Part:  idx,
+				Title: parts[idx-1].Title}
+		}
+		if idx < len(parts)-1 {
+			part.Next = &entity.SerieContentEntry{
+				Id:    parts[idx+1].Id,
+				Slug:  parts[idx+1].Slug,
+				Part:  idx + 2,
+				Title: parts[idx+1].Title}
+		}
+		return nil
+	}); err != nil {
+		return entity.SeriePart{}, fmt.Errorf("persistence<Memory.ArticleSeriePart>: %w", err)
+	}
+	return part, nil
+}
+
+// Published articles of the serie, in reading order
+func (s *memState) serieParts(serieId int) []memArticle {
+	var parts []memArticle
+	for _, a := range s.articles {
+		if a.SerieId.Valid && a.SerieId.V == serieId && listed(a.State) {
+			parts = append(parts, a)
+		}
+	}
+	slices.SortFunc(parts, func(x, y memArticle) int {
+		return x.SerieOrder.V - y.SerieOrder.V
+	})
+	return parts
+}
+
+func (m Memory) SerieProjectList(ctx context.Context, id int, param SerieContentQueryParam) ([]entity.SeriePageProjectList, error) {
+	if param.Limit < 1 {
+		param.Limit = 10
+	}
+	last, hasLast, err := readSerieContentCursor(m.cursors, param.Last, false)
+	if err != nil {
+		return []entity.SeriePageProjectList{}, fmt.Errorf(
+			"persistence<Memory.SerieProjectList>: %w", err)
+	}
+
+	var serieProjects []entity.SeriePageProjectList
+	if err := m.view(ctx, func(s *memState) error {
+		var rows []memProject
+		for _, p := range s.projects {
+			if p.DevblogSerie.Valid && p.DevblogSerie.V == id && listed(p.State) && (!hasLast || p.Id > last.Order) {
+				rows = append(rows, p)
+			}
+		}
+		slices.SortFunc(rows, func(x, y memProject) int { return x.Id - y.Id })
+
+		for idx, r := range rows[:min(param.Limit, len(rows))] {
+			key := serieContentKey{Order: r.Id, Nth: last.Nth + idx + 1}
+			cursor, err := key.cursor(m.cursors)
+			if err != nil {
+				return err
+			}
+			serieProjects = append(serieProjects, entity.SeriePageProjectList{
+				Id:        r.Id,
+				Slug:      r.Slug,
+				Nth:       key.Nth,
+				Cursor:    cursor,
+				Name:      r.Name,
+				Synopsis:  r.Synopsis,
+				CreatedAt: r.CreatedAt,
+				UpdatedAt: r.UpdatedAt})
+		}
+		return nil
+	}); err != nil {
+		return []entity.SeriePageProjectList{}, fmt.Errorf("persistence<Memory.SerieProjectList>: %w", err)
+	}
+	return serieProjects, nil
+}
+
+func (m Memory) RelatedArticles(ctx context.Context, id int, param RelatedQueryParam) ([]entity.RelatedEntry, error) {
+	if param.Limit < 1 {
+		param.Limit = 5
+	}
+
+	var related []entity.RelatedEntry
+	if err := m.view(ctx, func(s *memState) error {
+		source, ok := s.articles[id]
+		if !ok {
+			return nil
+		}
+		ownTags := s.articleTagIds(id)
+		var candidates []memRelated
+		for _, a := range s.articles {
+			if a.Id == id || !listed(a.State) {
+				continue
+			}
+			candidates = append(candidates, memRelated{
+				entry: entity.RelatedEntry{
+					Id:         a.Id,
+					Slug:       a.Slug,
+					Title:      a.Title,
+					Synopsis:   a.Subtitle,
+					SharedTags: countShared(ownTags, s.articleTagIds(a.Id)),
+					SameSerie:  source.SerieId.Valid && a.SerieId == source.SerieId},
+				updatedAt: a.UpdatedAt})
+		}
+		related = rankRelated(candidates, param.Limit)
+		return nil
+	}); err != nil {
+		return []entity.RelatedEntry{}, fmt.Errorf("persistence<Memory.RelatedArticles>: %w", err)
+	}
+	return related, nil
+}
+
+func (m Memory) RelatedProjects(ctx context.Context, id int, param RelatedQueryParam) ([]entity.RelatedEntry, error) {
+	if param.Limit < 1 {
+		param.Limit = 5
+	}
+
+	var related []entity.RelatedEntry
+	if err := m.view(ctx, func(s *memState) error {
+		source, ok := s.projects[id]
+		if !ok {
+			return nil
+		}
+		ownTags := s.projectTagIds(id)
+		var candidates []memRelated
+		for _, p := range s.projects {
+			if p.Id == id || !listed(p.State) {
+				continue
+			}
+			candidates = append(candidates, memRelated{
+				entry: entity.RelatedEntry{
+					Id:         p.Id,
+					Slug:       p.Slug,
+					Title:      p.Name,
+					Synopsis:   p.Synopsis,
+					SharedTags: countShared(ownTags, s.projectTagIds(p.Id)),
+					SameSerie:  source.DevblogSerie.Valid && p.DevblogSerie == source.DevblogSerie},
+				updatedAt: p.UpdatedAt})
+		}
+		related = rankRelated(candidates, param.Limit)
+		return nil
+	}); err != nil {
+		return []entity.RelatedEntry{}, fmt.Errorf("persistence<Memory.RelatedProjects>: %w", err)
+	}
+	return related, nil
+}
+
+type memRelated struct {
+	entry     entity.RelatedEntry
+	updatedAt time.Time
+}
+
+// Ranks the candidates the way `Pg.RelatedArticles` does, dropping those
+// sharing nothing
+func rankRelated(candidates []memRelated, limit int) []entity.RelatedEntry {
+	score := func(r memRelated) int {
+		if r.entry.SameSerie {
+			return r.entry.SharedTags + 1
+		}
+		return r.entry.SharedTags
+	}
+	candidates = slices.DeleteFunc(candidates, func(r memRelated) bool {
+		return score(r) == 0
+	})
+	slices.SortFunc(candidates, func(x, y memRelated) int {
+		if c := score(y) - score(x); c != 0 {
+			return c
+		} else if c := y.updatedAt.Compare(x.updatedAt); c != 0 {
+			return c
+		}
+		return x.entry.Id - y.entry.Id
+	})
+
+	var related []entity.RelatedEntry
+	for _, r := range candidates[:min(limit, len(candidates))] {
+		related = append(related, r.entry)
+	}
+	return related
+}
+
+func countShared(x, y []int) int {
+	n := 0
+	for _, id := range x {
+		if slices.Contains(y, id) {
+			n++
+		}
+	}
+	return n
+}
+
+// Pairs up tags with their `count`, sorted by the tag name
+func (s *memState) tagCounts(count map[int]int) ([]entity.Tag, []int) {
+	var tags []entity.Tag
+	for id := range count {
+		tags = append(tags, entity.Tag{
+			Id:   id,
+			Name: s.tags[id].Name})
+	}
+	slices.SortFunc(tags, func(x, y entity.Tag) int {
+		return strings.Compare(x.Name, y.Name)
+	})
+
+	var n []int
+	for _, t := range tags {
+		n = append(n, count[t.Id])
+	}
+	return tags, n
+}
@@ -0,0 +1,156 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+)
+
+// Like `Pg`, which lists `state = 'published'` and reaches
+// `state IN ('published', 'archived')`
+func TestListedAndReachable(t *testing.T) {
+	tests := []struct {
+		state     entity.PublicationState
+		listed    bool
+		reachable bool
+	}{
+		{entity.StateDraft, false, false},
+		{entity.StateScheduled, false, false},
+		{entity.StatePublished, true, true},
+		{entity.StateArchived, false, true},
+	}
+	for _, tt := range tests {
+		if got := listed(tt.state); got != tt.listed {
+			t.Errorf("listed(%s) = %t, want %t", tt.state, got, tt.listed)
+		}
+		if got := reachable(tt.state); got != tt.reachable {
+			t.Errorf("reachable(%s) = %t, want %t", tt.state, got, tt.reachable)
+		}
+	}
+}
+
+func TestMemRelevance(t *testing.T) {
+	tests := []struct {
+		name      string
+		keywords  []string
+		fields    []string
+		relevance float32
+		matched   bool
+	}{
+		{"no keyword", nil, []string{"anything"}, 0, true},
+		{"title", []string{"go"}, []string{"Go", "", ""}, 1, true},
+		{"subtitle", []string{"go"}, []string{"", "Go", ""}, 0.4, true},
+		{"content", []string{"go"}, []string{"", "", "Go"}, 0.2, true},
+		{"every occurrence", []string{"go"}, []string{"go, go", "", "go go go"}, 2.6, true},
+		{"every keyword", []string{"go", "sql"}, []string{"Go", "", "SQL"}, 1.2, true},
+		{"a keyword missing", []string{"go", "rust"}, []string{"Go", "", ""}, 0, false},
+		{"past the weights", []string{"go"}, []string{"", "", "", "go", "go"}, 0.2, true},
+	}
+	for _, tt := range tests {
+		relevance, matched := memRelevance(tt.keywords, tt.fields...)
+		if matched != tt.matched || relevance < tt.relevance-1e-6 || relevance > tt.relevance+1e-6 {
+			t.Errorf("%s: got %v %t, want %v %t", tt.name, relevance, matched, tt.relevance, tt.matched)
+		}
+	}
+}
+
+func TestMemoryAtomic(t *testing.T) {
+	ctx := context.Background()
+	fails := errors.New("fails")
+	serie := func(ctx context.Context, m Memory, name string) error {
+		_, err := m.InsertSeries(ctx, []entity.WriteSerie{{Name: name}})
+		return err
+	}
+	tests := []struct {
+		name string
+		run  func(m Memory) error
+		want []string // slugs of the series left
+	}{
+		{
+			name: "committed",
+			run: func(m Memory) error {
+				return m.Atomic(ctx, true, func(ctx context.Context) error { return serie(ctx, m, "Kept") })
+			},
+			want: []string{"first", "kept"}},
+		{
+			name: "dry run",
+			run: func(m Memory) error {
+				return m.Atomic(ctx, false, func(ctx context.Context) error { return serie(ctx, m, "Dropped") })
+			},
+			want: []string{"first"}},
+		{
+			name: "failed",
+			run: func(m Memory) error {
+				return m.Atomic(ctx, true, func(ctx context.Context) error {
+					if err := serie(ctx, m, "Dropped"); err != nil {
+						return err
+					}
+					return fails
+				})
+			},
+			want: []string{"first"}},
+		{
+			name: "nested within a dry run",
+			run: func(m Memory) error {
+				return m.Atomic(ctx, false, func(ctx context.Context) error {
+					return m.Atomic(ctx, true, func(ctx context.Context) error { return serie(ctx, m, "Dropped") })
+				})
+			},
+			want: []string{"first"}},
+		{
+			name: "failed statement",
+			run: func(m Memory) error {
+				return m.Atomic(ctx, true, func(ctx context.Context) error {
+					if err := serie(ctx, m, "Kept"); err != nil {
+						return err
+					}
+					// only the failed statement is undone
+					err := m.UpsertSeries(ctx, []entity.WriteSerie{{Id: 9, Name: "Taken", Slug: "first"}})
+					if err == nil {
+						return errors.New("expected a taken slug to be refused")
+					}
+					return nil
+				})
+			},
+			want: []string{"first", "kept"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemory(cursor.NewSealer([]byte("key")))
+			if err := serie(ctx, m, "First"); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.run(m); err != nil && !errors.Is(err, fails) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			snapshot, err := m.Snapshot(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]bool{}
+			for _, sr := range snapshot.Series {
+				got[sr.Slug] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got series %v, want %v", got, tt.want)
+			}
+			for _, slug := range tt.want {
+				if !got[slug] {
+					t.Errorf("missing serie %q, got %v", slug, got)
+				}
+			}
+
+			// ids drawn by discarded writes are drawn again
+			ids, err := m.InsertSeries(ctx, []entity.WriteSerie{{Name: "Next"}})
+			if err != nil {
+				t.Fatal(err)
+			} else if ids[0] != len(tt.want)+1 {
+				t.Errorf("got id %d for the next serie, want %d", ids[0], len(tt.want)+1)
+			}
+		})
+	}
+}
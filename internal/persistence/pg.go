@@ -1,26 +1,133 @@
 package persistence
 
-import "github.com/jmoiron/sqlx"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+)
 
 type Pg struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	cursors cursor.Sealer // seals the cursors of listings
+}
+
+func NewPg(db *sqlx.DB, cursors cursor.Sealer) Pg {
+	return Pg{db: db, cursors: cursors}
+}
+
+// What queries are run on: the pool, or the transaction begun from it
+type pgConn interface {
+	sqlx.ExtContext
+	BindNamed(query string, arg any) (string, []any, error)
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error)
+}
+
+type pgTxKey struct{}
+
+// A transaction carried by the context, along with the pool it's begun from
+type pgTx struct {
+	db     *sqlx.DB
+	tx     *sqlx.Tx
+	commit bool // whether its writes are kept once it succeeds
+}
+
+// The transaction of `ctx` when there's one begun by `Pg.Atomic`, else the
+// pool
+func (p Pg) conn(ctx context.Context) pgConn {
+	if t, ok := ctx.Value(pgTxKey{}).(pgTx); ok && t.db == p.db {
+		return t.tx
+	}
+	return p.db
 }
 
-func NewPg(db *sqlx.DB) Pg {
-	return Pg{db: db}
+// Runs `fx` in a single transaction, carried by the context it's given. The
+// writes are kept once `fx` succeeds, unless `commit` is false. Called within
+// another transaction, `fx` joins it and the outer call decides
+func (p Pg) Atomic(ctx context.Context, commit bool, fx func(ctx context.Context) error) error {
+	if t, ok := ctx.Value(pgTxKey{}).(pgTx); ok && t.db == p.db {
+		return fx(ctx)
+	}
+
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("persistence<Pg.Atomic>: %w", err)
+	}
+	if err := fx(context.WithValue(ctx, pgTxKey{}, pgTx{db: p.db, tx: tx, commit: commit})); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			err = errors.Join(err, rbErr)
+		}
+		return fmt.Errorf("persistence<Pg.Atomic>: %w", err)
+	}
+
+	if !commit {
+		err = tx.Rollback()
+	} else {
+		err = tx.Commit()
+	}
+	if err != nil {
+		return fmt.Errorf("persistence<Pg.Atomic>: %w", err)
+	}
+	return nil
 }
 
+// Filters shared by exploration queries. An entry should satisfy every
+// include, while matching any of the excludes drops it
 type ExplorationQueryParam struct {
-	Page    int
-	Limit   int
 	Include struct {
 		Keyword []string
-		Tag     []int
-		Serie   []int
+		Tag     [][]string // at least a tag of every group should be attached
+		Serie   []string   // the entry should belong to any of these
+		SerieId []int      // likewise, by their id
 	}
 	Exclude struct {
 		Keyword []string
-		Tag     []int
-		Serie   []int
+		Tag     []string
+		Serie   []string
+	}
+
+	Created TimeRange // when the entry should have been created
+	Sort    Sort
+}
+
+// Flattens the include tag groups into their names and the group each name
+// belongs to, as Postgres doesn't take ragged arrays
+func (param ExplorationQueryParam) includedTags() ([]string, []int) {
+	var names []string
+	var groups []int
+	for idx, group := range param.Include.Tag {
+		for _, name := range group {
+			names = append(names, name)
+			groups = append(groups, idx)
+		}
+	}
+	return names, groups
+}
+
+// Filters of the serie exploration
+type SerieExplorationQueryParam struct {
+	Include struct {
+		Name []string // the name should contain every of these
+	}
+	Exclude struct {
+		Name []string // the name shouldn't contain any of these
+	}
+
+	Created TimeRange // when the serie should have been created
+	Sort    Sort      // sorted by the creation unless by the name
+}
+
+// Filters of tags
+type TagExplorationQueryParam struct {
+	Include struct {
+		Name []string // the name should contain every of these
+	}
+	Exclude struct {
+		Name []string // the name shouldn't contain any of these
 	}
 }
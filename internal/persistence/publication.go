@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Publishes the scheduled articles, projects and series whose time has come,
+// as if they were created right then. Returns how many went live
+func (p Pg) PublishDue(ctx context.Context) (int, error) {
+	query := `
+		WITH
+			due_articles AS (
+				UPDATE articles
+				SET
+					state = 'published',
+					created_at = publish_at,
+					updated_at = publish_at
+				WHERE state = 'scheduled' AND publish_at <= LOCALTIMESTAMP
+				RETURNING id),
+			due_projects AS (
+				UPDATE projects
+				SET
+					state = 'published',
+					created_at = publish_at,
+					updated_at = publish_at
+				WHERE state = 'scheduled' AND publish_at <= LOCALTIMESTAMP
+				RETURNING id),
+			due_series AS (
+				UPDATE series
+				SET
+					state = 'published',
+					created_at = publish_at
+				WHERE state = 'scheduled' AND publish_at <= LOCALTIMESTAMP
+				RETURNING id)
+		SELECT
+			(SELECT COUNT(*) FROM due_articles)
+			+ (SELECT COUNT(*) FROM due_projects)
+			+ (SELECT COUNT(*) FROM due_series)`
+
+	var published int
+	if err := p.conn(ctx).GetContext(ctx, &published, query); err != nil {
+		return 0, fmt.Errorf("persistence<Pg.PublishDue>: %w", err)
+	}
+	return published, nil
+}
+
+// Time left until the next scheduled entry should go live, if there's any.
+// It's measured by the database clock, which `publish_at` follows
+func (p Pg) UntilNextPublication(ctx context.Context) (time.Duration, bool, error) {
+	query := `
+		SELECT EXTRACT(EPOCH FROM MIN(publish_at) - LOCALTIMESTAMP)
+		FROM (
+			SELECT publish_at FROM articles WHERE state = 'scheduled'
+			UNION ALL
+			SELECT publish_at FROM projects WHERE state = 'scheduled'
+			UNION ALL
+			SELECT publish_at FROM series WHERE state = 'scheduled'
+		) AS scheduled`
+
+	var seconds sql.Null[float64]
+	if err := p.conn(ctx).GetContext(ctx, &seconds, query); err != nil {
+		return 0, false, fmt.Errorf("persistence<Pg.UntilNextPublication>: %w", err)
+	} else if !seconds.Valid {
+		return 0, false, nil
+	}
+	return time.Duration(seconds.V * float64(time.Second)), true, nil
+}
@@ -0,0 +1,235 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+// Runs a named `query` returning a single id
+func (p Pg) namedId(ctx context.Context, query string, arg any) (int, error) {
+	bound, args, err := p.conn(ctx).BindNamed(query, arg)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	if err := p.conn(ctx).QueryRowxContext(ctx, bound, args...).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Runs a named batch `query` returning the id of each of `rows`, in their
+// order
+func (p Pg) namedIds(ctx context.Context, query string, rows []any) ([]int, error) {
+	result, err := sqlx.NamedQueryContext(ctx, p.conn(ctx), query, rows)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	ids := make([]int, 0, len(rows))
+	for result.Next() {
+		var id int
+		if err := result.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, result.Err()
+}
+
+func (p Pg) serieBySlug(ctx context.Context, slug string) (int, error) {
+	var id int
+	err := p.conn(ctx).GetContext(ctx, &id, `SELECT id FROM series WHERE slug = $1`, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("serie %q doesn't exist: %w", slug, oops.NotFound{})
+	}
+	return id, err
+}
+
+// The serie of `part`, given by its id or else by its slug. None when both
+// are left empty
+func (p Pg) serieOf(ctx context.Context, part entity.WriteSeriePart) (sql.Null[int], error) {
+	if part.Id != 0 {
+		var id int
+		err := p.conn(ctx).GetContext(ctx, &id, `SELECT id FROM series WHERE id = $1`, part.Id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.Null[int]{}, fmt.Errorf("serie %d doesn't exist: %w", part.Id, oops.NotFound{})
+		}
+		return sql.Null[int]{V: id, Valid: err == nil}, err
+	} else if part.Slug != "" {
+		id, err := p.serieBySlug(ctx, part.Slug)
+		return sql.Null[int]{V: id, Valid: err == nil}, err
+	}
+	return sql.Null[int]{}, nil
+}
+
+// Moves an article into the serie of `part`, or out of its serie. Left as
+// it is without a `part`
+func (p Pg) placeArticle(ctx context.Context, id int, part *entity.WriteSeriePart) error {
+	if part == nil {
+		return nil
+	}
+
+	serieId, err := p.serieOf(ctx, *part)
+	if err != nil {
+		return err
+	}
+	order := sql.Null[int]{V: part.Order, Valid: serieId.Valid}
+	_, err = p.conn(ctx).ExecContext(ctx,
+		`UPDATE articles SET serie_id = $2, serie_order = $3 WHERE id = $1`,
+		id, serieId, order)
+	return err
+}
+
+// Takes the articles about to be placed out of their serie, as
+// `UNIQUE(serie_id, serie_order)` is checked on every row. Placing them one
+// by one then can't run into an order another one of them is leaving, like
+// when two articles swap theirs
+func (p Pg) unplaceArticles(ctx context.Context, articles []entity.WriteArticle) error {
+	var ids []int
+	for _, a := range articles {
+		if a.Serie != nil {
+			ids = append(ids, a.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := p.conn(ctx).ExecContext(ctx,
+		`UPDATE articles SET serie_id = NULL, serie_order = NULL WHERE id = ANY($1::INT[])`,
+		ids)
+	return err
+}
+
+// Like `placeArticle`, for the devblog of a project
+func (p Pg) placeProject(ctx context.Context, id int, part *entity.WriteSeriePart) error {
+	if part == nil {
+		return nil
+	}
+
+	serieId, err := p.serieOf(ctx, *part)
+	if err != nil {
+		return err
+	}
+	_, err = p.conn(ctx).ExecContext(ctx,
+		`UPDATE projects SET devblog_serie = $2 WHERE id = $1`, id, serieId)
+	return err
+}
+
+// Sets the thumbnail of an article. Left as it is without a `thumbnail`
+func (p Pg) illustrateArticle(ctx context.Context, id int, thumbnail *string) error {
+	if thumbnail == nil {
+		return nil
+	}
+	_, err := p.conn(ctx).ExecContext(ctx,
+		`UPDATE articles SET thumbnail = $2 WHERE id = $1`, id, *thumbnail)
+	return err
+}
+
+// Like `illustrateArticle`, where an empty thumbnail is stored as none
+func (p Pg) illustrateProject(ctx context.Context, id int, thumbnail *string) error {
+	if thumbnail == nil {
+		return nil
+	}
+	_, err := p.conn(ctx).ExecContext(ctx,
+		`UPDATE projects SET thumbnail = NULLIF($2, '') WHERE id = $1`, id, *thumbnail)
+	return err
+}
+
+func (p Pg) makeTags(ctx context.Context, names []string) error {
+	_, err := p.conn(ctx).ExecContext(ctx, `
+		INSERT INTO tags(name)
+		SELECT DISTINCT unnest($1::VARCHAR[])
+		ON CONFLICT(name) DO NOTHING`,
+		names)
+	return err
+}
+
+// Attaches exactly the tags of `names` to an article, making the missing
+// ones. Left as they are without `names`
+func (p Pg) tagArticle(ctx context.Context, id int, names []string) error {
+	if names == nil {
+		return nil
+	} else if err := p.makeTags(ctx, names); err != nil {
+		return err
+	}
+
+	query := `
+		WITH
+			wanted AS (
+				SELECT id FROM tags WHERE name = ANY($2::VARCHAR[])),
+			dropped AS (
+				DELETE FROM article_tags
+				WHERE article_id = $1 AND tag_id NOT IN (SELECT id FROM wanted))
+		INSERT INTO article_tags(article_id, tag_id)
+		SELECT $1, id
+		FROM wanted
+		ON CONFLICT(article_id, tag_id) DO NOTHING`
+	_, err := p.conn(ctx).ExecContext(ctx, query, id, names)
+	return err
+}
+
+// Like `tagArticle`, for a project
+func (p Pg) tagProject(ctx context.Context, id int, names []string) error {
+	if names == nil {
+		return nil
+	} else if err := p.makeTags(ctx, names); err != nil {
+		return err
+	}
+
+	query := `
+		WITH
+			wanted AS (
+				SELECT id FROM tags WHERE name = ANY($2::VARCHAR[])),
+			dropped AS (
+				DELETE FROM project_tags
+				WHERE project_id = $1 AND tag_id NOT IN (SELECT id FROM wanted))
+		INSERT INTO project_tags(project_id, tag_id)
+		SELECT $1, id
+		FROM wanted
+		ON CONFLICT(tag_id, project_id) DO NOTHING`
+	_, err := p.conn(ctx).ExecContext(ctx, query, id, names)
+	return err
+}
+
+// Links a project to exactly `links`, matched by their url: the missing ones
+// are added, the others have their text updated and the rest are removed.
+// Left as they are without `links`
+func (p Pg) linkProject(ctx context.Context, id int, links []entity.WriteProjectLink) error {
+	if links == nil {
+		return nil
+	}
+
+	urls := make([]string, len(links))
+	texts := make([]string, len(links))
+	for idx, l := range links {
+		urls[idx] = l.Url
+		texts[idx] = l.DisplayText
+	}
+	query := `
+		WITH
+			wanted AS (
+				SELECT DISTINCT ON (url) url, display_text
+				FROM unnest($2::VARCHAR[], $3::VARCHAR[]) AS given(url, display_text)),
+			dropped AS (
+				DELETE FROM project_links
+				WHERE project_id = $1 AND url NOT IN (SELECT url FROM wanted)),
+			updated AS (
+				UPDATE project_links
+				SET display_text = wanted.display_text
+				FROM wanted
+				WHERE project_links.project_id = $1 AND project_links.url = wanted.url)
+		INSERT INTO project_links(project_id, display_text, url)
+		SELECT $1, display_text, url
+		FROM wanted
+		WHERE url NOT IN (SELECT url FROM project_links WHERE project_id = $1)`
+	_, err := p.conn(ctx).ExecContext(ctx, query, id, urls, texts)
+	return err
+}
@@ -0,0 +1,137 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+func (p Pg) ArticleHistory(ctx context.Context, id int) (entity.ArticleHistoryPage, error) {
+	var article struct {
+		Id    int    `db:"id"`
+		Slug  string `db:"slug"`
+		Title string `db:"title"`
+	}
+	query := `
+		SELECT id, slug, title
+		FROM articles
+		WHERE id = $1 AND state IN ('published', 'archived')`
+	if err := p.conn(ctx).GetContext(ctx, &article, query, id); errors.Is(err, sql.ErrNoRows) {
+		return entity.ArticleHistoryPage{}, fmt.Errorf(
+			"persistence<Pg.ArticleHistory>: %w", oops.NotFound{})
+	} else if err != nil {
+		return entity.ArticleHistoryPage{}, fmt.Errorf("persistence<Pg.ArticleHistory>: %w", err)
+	}
+
+	var rows []struct {
+		Id        int       `db:"id"`
+		Title     string    `db:"title"`
+		Message   string    `db:"message"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	query = `
+		SELECT
+			id,
+			title,
+			message,
+			created_at
+		FROM article_revisions
+		WHERE article_id = $1
+		ORDER BY id DESC`
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, id); err != nil {
+		return entity.ArticleHistoryPage{}, fmt.Errorf("persistence<Pg.ArticleHistory>: %w", err)
+	}
+
+	history := entity.ArticleHistoryPage{
+		Id:        article.Id,
+		Slug:      article.Slug,
+		Title:     article.Title,
+		Revisions: make([]entity.Revision, len(rows))}
+	for idx, r := range rows {
+		history.Revisions[idx] = entity.Revision{
+			Id:        r.Id,
+			Title:     r.Title,
+			Message:   r.Message,
+			CreatedAt: r.CreatedAt}
+	}
+	return history, nil
+}
+
+func (p Pg) ArticleRevision(ctx context.Context, articleId int, revisionId int) (entity.Revision, error) {
+	var row struct {
+		Id        int       `db:"id"`
+		Title     string    `db:"title"`
+		Content   string    `db:"content"`
+		Message   string    `db:"message"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	query := `
+		SELECT
+			article_revisions.id,
+			article_revisions.title,
+			article_revisions.content,
+			article_revisions.message,
+			article_revisions.created_at
+		FROM article_revisions
+		JOIN articles
+			ON articles.id = article_revisions.article_id
+			AND articles.state IN ('published', 'archived')
+		WHERE article_revisions.id = $1 AND article_revisions.article_id = $2`
+	if err := p.conn(ctx).GetContext(ctx, &row, query, revisionId, articleId); errors.Is(err, sql.ErrNoRows) {
+		return entity.Revision{}, fmt.Errorf(
+			"persistence<Pg.ArticleRevision>: %w", oops.NotFound{})
+	} else if err != nil {
+		return entity.Revision{}, fmt.Errorf("persistence<Pg.ArticleRevision>: %w", err)
+	}
+	return entity.Revision{
+		Id:        row.Id,
+		Title:     row.Title,
+		Content:   row.Content,
+		Message:   row.Message,
+		CreatedAt: row.CreatedAt}, nil
+}
+
+// Restores the title and content of each article from one of its revisions.
+// The rollback is itself recorded as a new revision, so it can be undone too
+func (p Pg) RollbackArticles(ctx context.Context, rollbacks []entity.RollbackArticle) error {
+	query := `
+		WITH target AS (
+			SELECT title, content
+			FROM article_revisions
+			WHERE id = $2 AND article_id = $1),
+		restored AS (
+			UPDATE articles
+			SET
+				title = target.title,
+				content = target.content,
+				updated_at = CURRENT_TIMESTAMP
+			FROM target
+			WHERE articles.id = $1
+			RETURNING articles.id, articles.title, articles.content)
+		INSERT INTO article_revisions(
+			article_id,
+			title,
+			content,
+			message)
+		SELECT id, title, content, $3
+		FROM restored`
+	for _, r := range rollbacks {
+		result, err := p.conn(ctx).ExecContext(ctx, query, r.ArticleId, r.RevisionId, r.Message)
+		if err != nil {
+			return fmt.Errorf("persistence<Pg.RollbackArticles>: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("persistence<Pg.RollbackArticles>: %w", err)
+		} else if n == 0 {
+			return fmt.Errorf(
+				"persistence<Pg.RollbackArticles>: article %d doesn't have revision %d: %w",
+				r.ArticleId, r.RevisionId, oops.NotFound{})
+		}
+	}
+	return nil
+}
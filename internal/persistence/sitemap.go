@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+// Every page that can be reached through a link. A serie is updated along
+// with its contents, while a tag is updated along with the listed entries
+// having it
+func (p Pg) Sitemap(ctx context.Context) ([]entity.SitemapEntry, error) {
+	query := `
+		SELECT 'article' AS "kind", slug AS "ref", updated_at
+		FROM articles
+		WHERE state IN ('published', 'archived')
+		UNION ALL
+		SELECT 'project', slug, updated_at
+		FROM projects
+		WHERE state IN ('published', 'archived')
+		UNION ALL
+		SELECT
+			'serie',
+			series.slug,
+			GREATEST(
+				series.created_at,
+				(
+					SELECT MAX(articles.updated_at)
+					FROM articles
+					WHERE
+						articles.serie_id = series.id
+						AND articles.state IN ('published', 'archived')
+				),
+				(
+					SELECT MAX(projects.updated_at)
+					FROM projects
+					WHERE
+						projects.devblog_serie = series.id
+						AND projects.state IN ('published', 'archived')
+				))
+		FROM series
+		WHERE state IN ('published', 'archived')
+		UNION ALL
+		SELECT 'article_tag', tags.name, MAX(articles.updated_at)
+		FROM tags
+		JOIN article_tags ON article_tags.tag_id = tags.id
+		JOIN articles ON articles.id = article_tags.article_id
+		WHERE articles.state = 'published'
+		GROUP BY tags.id
+		UNION ALL
+		SELECT 'project_tag', tags.name, MAX(projects.updated_at)
+		FROM tags
+		JOIN project_tags ON project_tags.tag_id = tags.id
+		JOIN projects ON projects.id = project_tags.project_id
+		WHERE projects.state = 'published'
+		GROUP BY tags.id
+		ORDER BY kind, ref`
+
+	var rows []struct {
+		Kind      entity.SitemapKind `db:"kind"`
+		Ref       string             `db:"ref"`
+		UpdatedAt time.Time          `db:"updated_at"`
+	}
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query); err != nil {
+		return []entity.SitemapEntry{}, fmt.Errorf("persistence<Pg.Sitemap>: %w", err)
+	}
+
+	entries := []entity.SitemapEntry{}
+	for _, r := range rows {
+		entries = append(entries, entity.SitemapEntry{
+			Kind:      r.Kind,
+			Ref:       r.Ref,
+			UpdatedAt: r.UpdatedAt})
+	}
+	return entries, nil
+}
@@ -0,0 +1,100 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+	"github.com/solsteace/misite/internal/utility/lib/slug"
+)
+
+// Tables holding each kind of entry reached through a slug
+var slugTables = map[entity.SlugKind]string{
+	entity.SlugArticle: "articles",
+	entity.SlugProject: "projects",
+	entity.SlugSerie:   "series"}
+
+// Finds the reachable entry `ref` leads to, which is either its slug, its id
+// or one of its former slugs. Gives its id and current slug
+func (p Pg) ResolveSlug(ctx context.Context, kind entity.SlugKind, ref string) (int, string, error) {
+	table, ok := slugTables[kind]
+	if !ok {
+		return 0, "", fmt.Errorf("persistence<Pg.ResolveSlug>: unknown kind %q", kind)
+	}
+
+	var id sql.Null[int]
+	if parsed, err := strconv.Atoi(ref); err == nil {
+		id = sql.Null[int]{V: parsed, Valid: true}
+	}
+	query := strings.ReplaceAll(`
+		SELECT id, slug
+		FROM (
+			SELECT id, slug, state, 0 AS "priority"
+			FROM {table}
+			WHERE slug = $1
+			UNION ALL
+			SELECT id, slug, state, 1
+			FROM {table}
+			WHERE id = $2
+			UNION ALL
+			SELECT {table}.id, {table}.slug, {table}.state, 2
+			FROM slug_redirects
+			JOIN {table} ON {table}.id = slug_redirects.target_id
+			WHERE slug_redirects.kind = $3 AND slug_redirects.slug = $1
+		) AS candidates
+		WHERE state IN ('published', 'archived')
+		ORDER BY priority
+		LIMIT 1`, "{table}", table)
+	args := []any{ref, id, kind}
+
+	var row struct {
+		Id   int    `db:"id"`
+		Slug string `db:"slug"`
+	}
+	if err := p.conn(ctx).GetContext(ctx, &row, query, args...); errors.Is(err, sql.ErrNoRows) {
+		return 0, "", fmt.Errorf("persistence<Pg.ResolveSlug>: %w", oops.NotFound{})
+	} else if err != nil {
+		return 0, "", fmt.Errorf("persistence<Pg.ResolveSlug>: %w", err)
+	}
+	return row.Id, row.Slug, nil
+}
+
+// Makes a slug out of `name` that no other entry of its kind has. `id` is of
+// the entry being written, if it already exists
+func (p Pg) freeSlug(ctx context.Context, kind entity.SlugKind, name string, id sql.Null[int]) (string, error) {
+	base := slugBase(kind, name)
+	query := `
+		SELECT slug
+		FROM ` + slugTables[kind] + `
+		WHERE
+			(slug = $1 OR slug LIKE $1 || '-%')
+			AND id IS DISTINCT FROM $2`
+
+	var rows []string
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, base, id); err != nil {
+		return "", err
+	}
+	taken := map[string]struct{}{}
+	for _, r := range rows {
+		taken[r] = struct{}{}
+	}
+	return slug.Free(base, func(s string) bool {
+		_, ok := taken[s]
+		return ok
+	}), nil
+}
+
+// The slug made out of `name`, prefixed by its kind whenever that alone
+// wouldn't be valid
+func slugBase(kind entity.SlugKind, name string) string {
+	base := slug.Make(name)
+	if !slug.Valid(base) {
+		base = strings.Trim(string(kind)+"-"+base, "-")
+	}
+	return base
+}
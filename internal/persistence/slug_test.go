@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+func TestSlugBase(t *testing.T) {
+	tests := []struct {
+		kind entity.SlugKind
+		name string
+		want string
+	}{
+		{entity.SlugArticle, "Hello, World!", "hello-world"},
+		{entity.SlugArticle, "Crème brûlée", "cr-me-br-l-e"},
+		{entity.SlugArticle, "日本語", "article"},
+		{entity.SlugProject, "2048", "project-2048"},
+		{entity.SlugSerie, "", "serie"},
+	}
+	for _, tt := range tests {
+		if got := slugBase(tt.kind, tt.name); got != tt.want {
+			t.Errorf("slugBase(%s, %q) = %q, want %q", tt.kind, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMemorySlugs(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(cursor.NewSealer([]byte("key")))
+	if err := m.UpsertArticles(ctx, []entity.WriteArticle{
+		{Id: 1, Title: "Hello"},
+		{Id: 2, Title: "Hello!"},
+		{Id: 3, Title: "日本語"},
+		{Id: 4, Title: "こんにちは"},
+		{Id: 5, Title: "Draft", State: entity.StateDraft}},
+		make([]string, 5)); err != nil {
+		t.Fatal(err)
+	}
+	// renamed twice, each former slug still leading to it
+	for _, slug := range []string{"hi", "hey"} {
+		if err := m.UpsertArticles(ctx, []entity.WriteArticle{
+			{Id: 1, Title: "Hello", Slug: slug}}, []string{""}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.UpsertArticles(ctx, []entity.WriteArticle{
+		{Id: 2, Title: "Hello", Slug: "hey"}}, []string{""}); err == nil {
+		t.Error("expected a taken slug to be refused")
+	}
+
+	tests := []struct {
+		ref  string
+		id   int
+		slug string // empty when it leads nowhere
+	}{
+		{"hey", 1, "hey"},
+		{"hi", 1, "hey"},
+		{"hello", 1, "hey"},
+		{"1", 1, "hey"},
+		{"hello-2", 2, "hello-2"},
+		{"2", 2, "hello-2"},
+		{"article", 3, "article"},
+		{"article-2", 4, "article-2"},
+		{"draft", 0, ""},
+		{"5", 0, ""},
+		{"missing", 0, ""},
+	}
+	for _, tt := range tests {
+		id, slug, err := m.ResolveSlug(ctx, entity.SlugArticle, tt.ref)
+		if tt.slug == "" {
+			if !errors.As(err, &oops.NotFound{}) {
+				t.Errorf("%q: expected nothing to be found, got %d %q %v", tt.ref, id, slug, err)
+			}
+			continue
+		}
+		if err != nil || id != tt.id || slug != tt.slug {
+			t.Errorf("%q: got %d %q %v, want %d %q", tt.ref, id, slug, err, tt.id, tt.slug)
+		}
+	}
+
+	// a former slug is given back once taken again
+	if err := m.UpsertArticles(ctx, []entity.WriteArticle{
+		{Id: 2, Title: "Hello", Slug: "hi"}}, []string{""}); err != nil {
+		t.Fatal(err)
+	}
+	if id, slug, err := m.ResolveSlug(ctx, entity.SlugArticle, "hi"); err != nil || id != 2 || slug != "hi" {
+		t.Errorf("reclaimed slug: got %d %q %v, want 2 %q", id, slug, err, "hi")
+	}
+}
+
+func TestPgResolveSlug(t *testing.T) {
+	tests := []struct {
+		ref  string
+		rows [][]driver.Value
+		args []any
+	}{
+		{"hello", [][]driver.Value{{int64(1), "hello"}}, []any{"hello", nil, "article"}},
+		{"12", [][]driver.Value{{int64(12), "hello"}}, []any{"12", int64(12), "article"}},
+		{"missing", nil, []any{"missing", nil, "article"}},
+	}
+	for _, tt := range tests {
+		p, r := newRecordedPg()
+		r.columns = []string{"id", "slug"}
+		r.rows = tt.rows
+		id, slug, err := p.ResolveSlug(context.Background(), entity.SlugArticle, tt.ref)
+		if tt.rows == nil {
+			if !errors.As(err, &oops.NotFound{}) {
+				t.Errorf("%q: expected nothing to be found, got %v", tt.ref, err)
+			}
+		} else if err != nil || id != int(tt.rows[0][0].(int64)) || slug != tt.rows[0][1] {
+			t.Errorf("%q: got %d %q %v, want %v", tt.ref, id, slug, err, tt.rows[0])
+		}
+		if len(r.stmts) != 1 || !reflect.DeepEqual(r.stmts[0].args, tt.args) {
+			t.Errorf("%q: got statements %+v, want args %v", tt.ref, r.stmts, tt.args)
+		}
+	}
+
+	p, r := newRecordedPg()
+	if _, _, err := p.ResolveSlug(context.Background(), entity.SlugKind("tag"), "go"); err == nil {
+		t.Error("expected an unknown kind to be refused")
+	} else if len(r.stmts) > 0 {
+		t.Errorf("expected nothing to be queried, got %+v", r.stmts)
+	}
+}
@@ -0,0 +1,236 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+func (p Pg) Snapshot(ctx context.Context) (entity.Snapshot, error) {
+	snapshot := entity.Snapshot{}
+
+	var series []struct {
+		Id          int                     `db:"id"`
+		Slug        string                  `db:"slug"`
+		Name        string                  `db:"name"`
+		Thumbnail   string                  `db:"thumbnail"`
+		Description string                  `db:"description"`
+		State       entity.PublicationState `db:"state"`
+		PublishAt   sql.Null[time.Time]     `db:"publish_at"`
+		CreatedAt   time.Time               `db:"created_at"`
+	}
+	query := `
+		SELECT id, slug, name, thumbnail, description, state, publish_at, created_at
+		FROM series
+		ORDER BY id`
+	if err := p.conn(ctx).SelectContext(ctx, &series, query); err != nil {
+		return snapshot, fmt.Errorf("persistence<Pg.Snapshot>: %w", err)
+	}
+	for _, sr := range series {
+		snapshot.Series = append(snapshot.Series, entity.WriteSerie{
+			Id:          sr.Id,
+			Slug:        sr.Slug,
+			Name:        sr.Name,
+			Thumbnail:   sr.Thumbnail,
+			Description: sr.Description,
+			State:       sr.State,
+			PublishAt:   sr.PublishAt.V,
+			CreatedAt:   sr.CreatedAt})
+	}
+
+	var tags []struct {
+		Id   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	if err := p.conn(ctx).SelectContext(ctx, &tags, `SELECT id, name FROM tags ORDER BY id`); err != nil {
+		return snapshot, fmt.Errorf("persistence<Pg.Snapshot>: %w", err)
+	}
+	for _, t := range tags {
+		snapshot.Tags = append(snapshot.Tags, entity.WriteTag{Id: t.Id, Name: t.Name})
+	}
+
+	// Both `article_tags` and `project_tags`
+	var entryTags []struct {
+		Id      int `db:"id"`
+		EntryId int `db:"entry_id"`
+		TagId   int `db:"tag_id"`
+	}
+	query = `SELECT id, article_id AS "entry_id", tag_id FROM article_tags ORDER BY id`
+	if err := p.conn(ctx).SelectContext(ctx, &entryTags, query); err != nil {
+		return snapshot, fmt.Errorf("persistence<Pg.Snapshot>: %w", err)
+	}
+	for _, at := range entryTags {
+		snapshot.ArticleTags = append(snapshot.ArticleTags, entity.WriteArticleTag{
+			Id:        at.Id,
+			ArticleId: at.EntryId,
+			TagId:     at.TagId})
+	}
+	entryTags = nil
+	query = `SELECT id, project_id AS "entry_id", tag_id FROM project_tags ORDER BY id`
+	if err := p.conn(ctx).SelectContext(ctx, &entryTags, query); err != nil {
+		return snapshot, fmt.Errorf("persistence<Pg.Snapshot>: %w", err)
+	}
+	for _, pt := range entryTags {
+		snapshot.ProjectTags = append(snapshot.ProjectTags, entity.WriteProjectTag{
+			Id:        pt.Id,
+			ProjectId: pt.EntryId,
+			TagId:     pt.TagId})
+	}
+
+	var links []struct {
+		Id          int    `db:"id"`
+		ProjectId   int    `db:"project_id"`
+		DisplayText string `db:"display_text"`
+		Url         string `db:"url"`
+	}
+	query = `SELECT id, project_id, display_text, url FROM project_links ORDER BY id`
+	if err := p.conn(ctx).SelectContext(ctx, &links, query); err != nil {
+		return snapshot, fmt.Errorf("persistence<Pg.Snapshot>: %w", err)
+	}
+	for _, pl := range links {
+		snapshot.ProjectLinks = append(snapshot.ProjectLinks, entity.WriteProjectLink{
+			Id:          pl.Id,
+			ProjectId:   pl.ProjectId,
+			DisplayText: pl.DisplayText,
+			Url:         pl.Url})
+	}
+
+	var articles []struct {
+		Id         int                     `db:"id"`
+		Slug       string                  `db:"slug"`
+		Title      string                  `db:"title"`
+		Subtitle   string                  `db:"subtitle"`
+		Content    string                  `db:"content"`
+		Thumbnail  string                  `db:"thumbnail"`
+		State      entity.PublicationState `db:"state"`
+		PublishAt  sql.Null[time.Time]     `db:"publish_at"`
+		Serie      sql.Null[string]        `db:"serie"`
+		SerieOrder sql.Null[int]           `db:"serie_order"`
+		CreatedAt  time.Time               `db:"created_at"`
+		UpdatedAt  time.Time               `db:"updated_at"`
+	}
+	query = `
+		SELECT
+			articles.id,
+			articles.slug,
+			articles.title,
+			articles.subtitle,
+			articles.content,
+			articles.thumbnail,
+			articles.state,
+			articles.publish_at,
+			series.slug AS "serie",
+			articles.serie_order,
+			articles.created_at,
+			articles.updated_at
+		FROM articles
+		LEFT JOIN series ON series.id = articles.serie_id
+		ORDER BY articles.id`
+	if err := p.conn(ctx).SelectContext(ctx, &articles, query); err != nil {
+		return snapshot, fmt.Errorf("persistence<Pg.Snapshot>: %w", err)
+	}
+	for _, a := range articles {
+		article := entity.WriteArticle{
+			Id:        a.Id,
+			Slug:      a.Slug,
+			Title:     a.Title,
+			Subtitle:  a.Subtitle,
+			Content:   a.Content,
+			Thumbnail: &a.Thumbnail,
+			State:     a.State,
+			PublishAt: a.PublishAt.V,
+			Tags:      snapshotArticleTags(snapshot, a.Id),
+			CreatedAt: a.CreatedAt,
+			UpdatedAt: a.UpdatedAt}
+		if a.Serie.Valid {
+			article.Serie = &entity.WriteSeriePart{Slug: a.Serie.V, Order: a.SerieOrder.V}
+		}
+		snapshot.Articles = append(snapshot.Articles, article)
+	}
+
+	var projects []struct {
+		Id          int                     `db:"id"`
+		Slug        string                  `db:"slug"`
+		Name        string                  `db:"name"`
+		Synopsis    string                  `db:"synopsis"`
+		Description string                  `db:"description"`
+		Thumbnail   sql.Null[string]        `db:"thumbnail"`
+		State       entity.PublicationState `db:"state"`
+		PublishAt   sql.Null[time.Time]     `db:"publish_at"`
+		Serie       sql.Null[string]        `db:"serie"`
+		CreatedAt   time.Time               `db:"created_at"`
+		UpdatedAt   time.Time               `db:"updated_at"`
+	}
+	query = `
+		SELECT
+			projects.id,
+			projects.slug,
+			projects.name,
+			projects.synopsis,
+			projects.description,
+			projects.thumbnail,
+			projects.state,
+			projects.publish_at,
+			series.slug AS "serie",
+			projects.created_at,
+			projects.updated_at
+		FROM projects
+		LEFT JOIN series ON series.id = projects.devblog_serie
+		ORDER BY projects.id`
+	if err := p.conn(ctx).SelectContext(ctx, &projects, query); err != nil {
+		return snapshot, fmt.Errorf("persistence<Pg.Snapshot>: %w", err)
+	}
+	for _, pr := range projects {
+		project := entity.WriteProject{
+			Id:          pr.Id,
+			Slug:        pr.Slug,
+			Name:        pr.Name,
+			Synopsis:    pr.Synopsis,
+			Description: pr.Description,
+			Thumbnail:   &pr.Thumbnail.V,
+			State:       pr.State,
+			PublishAt:   pr.PublishAt.V,
+			Tags:        snapshotProjectTags(snapshot, pr.Id),
+			CreatedAt:   pr.CreatedAt,
+			UpdatedAt:   pr.UpdatedAt}
+		if pr.Serie.Valid {
+			project.Serie = &entity.WriteSeriePart{Slug: pr.Serie.V}
+		}
+		snapshot.Projects = append(snapshot.Projects, project)
+	}
+	return snapshot, nil
+}
+
+// Names of the tags of an article within `snapshot`, empty rather than nil
+// when it has none
+func snapshotArticleTags(snapshot entity.Snapshot, id int) []string {
+	names := []string{}
+	for _, at := range snapshot.ArticleTags {
+		if at.ArticleId == id {
+			names = append(names, snapshotTagName(snapshot, at.TagId))
+		}
+	}
+	return names
+}
+
+func snapshotProjectTags(snapshot entity.Snapshot, id int) []string {
+	names := []string{}
+	for _, pt := range snapshot.ProjectTags {
+		if pt.ProjectId == id {
+			names = append(names, snapshotTagName(snapshot, pt.TagId))
+		}
+	}
+	return names
+}
+
+func snapshotTagName(snapshot entity.Snapshot, id int) string {
+	for _, t := range snapshot.Tags {
+		if t.Id == id {
+			return t.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,284 @@
+package persistence
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+// Order of exploration entries. The zero value sorts by the relevance to the
+// searched keywords, then by the latest update
+type Sort int
+
+const (
+	SortRelevance Sort = iota
+	SortUpdated        // latest update first
+	SortCreated        // latest creation first
+	SortTitle          // alphabetically, by the title or name
+	SortTitleDesc      // reverse alphabetically, by the title or name
+)
+
+// A time window, where a zero bound is left open
+type TimeRange struct {
+	From  time.Time // inclusive
+	Until time.Time // exclusive
+}
+
+func (r TimeRange) contains(t time.Time) bool {
+	return (r.From.IsZero() || !t.Before(r.From)) &&
+		(r.Until.IsZero() || t.Before(r.Until))
+}
+
+// Bounds as query arguments, where an open bound is NULL
+func (r TimeRange) args() (any, any) {
+	var from, until any
+	if !r.From.IsZero() {
+		from = r.From
+	}
+	if !r.Until.IsZero() {
+		until = r.Until
+	}
+	return from, until
+}
+
+// Values an entry is sorted by, which also marks where a listing stopped.
+// Only the values used by `Sort` are kept
+type sortKey struct {
+	Sort      Sort      `json:"s"`
+	Relevance float32   `json:"r,omitempty"`
+	At        time.Time `json:"t,omitzero"`  // creation or update time, following the sort
+	Title     string    `json:"k,omitempty"` // lowercased
+	Id        int       `json:"i"`
+}
+
+func newSortKey(
+	sort Sort,
+	id int,
+	title string,
+	createdAt time.Time,
+	updatedAt time.Time,
+	relevance float32,
+) sortKey {
+	key := sortKey{Sort: sort, Id: id}
+	switch sort {
+	case SortUpdated:
+		key.At = updatedAt
+	case SortCreated:
+		key.At = createdAt
+	case SortTitle, SortTitleDesc:
+		key.Title = strings.ToLower(title)
+	default:
+		key.Relevance = relevance
+		key.At = updatedAt
+	}
+	return key
+}
+
+// Opaque cursor of the entry, to list the entries around it
+func (k sortKey) cursor(sealer cursor.Sealer) (string, error) {
+	return sealer.Seal(k)
+}
+
+// Negative when the entry keyed by `k` comes before the other one
+func (k sortKey) compare(other sortKey) int {
+	switch k.Sort {
+	case SortUpdated, SortCreated:
+		if c := other.At.Compare(k.At); c != 0 {
+			return c
+		}
+	case SortTitle:
+		if c := strings.Compare(k.Title, other.Title); c != 0 {
+			return c
+		}
+	case SortTitleDesc:
+		if c := strings.Compare(other.Title, k.Title); c != 0 {
+			return c
+		}
+	default:
+		if c := cmp.Compare(other.Relevance, k.Relevance); c != 0 {
+			return c
+		} else if c := other.At.Compare(k.At); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(k.Id, other.Id)
+}
+
+// Values of the key, following the columns of its sort
+func (k sortKey) values() []any {
+	switch k.Sort {
+	case SortUpdated, SortCreated:
+		return []any{k.At, k.Id}
+	case SortTitle, SortTitleDesc:
+		return []any{k.Title, k.Id}
+	}
+	return []any{k.Relevance, k.At, k.Id}
+}
+
+type sortColumn struct {
+	expr string
+	desc bool
+}
+
+// Columns of `table` the sort goes through, whose title is in `title`. The id
+// always comes last to break ties
+func (s Sort) columns(table, title string) []sortColumn {
+	id := sortColumn{expr: table + ".id"}
+	switch s {
+	case SortUpdated:
+		return []sortColumn{{expr: table + ".updated_at", desc: true}, id}
+	case SortCreated:
+		return []sortColumn{{expr: table + ".created_at", desc: true}, id}
+	case SortTitle:
+		return []sortColumn{{expr: fmt.Sprintf("LOWER(%s.%s)", table, title)}, id}
+	case SortTitleDesc:
+		return []sortColumn{{expr: fmt.Sprintf("LOWER(%s.%s)", table, title), desc: true}, id}
+	}
+	return []sortColumn{
+		{expr: table + ".relevance", desc: true},
+		{expr: table + ".updated_at", desc: true},
+		id}
+}
+
+// ORDER BY expressions of the sort on `table`, whose title is in `title`
+func (s Sort) orderBy(table, title string) string {
+	return s.order(table, title, false)
+}
+
+func (s Sort) order(table, title string, reverse bool) string {
+	var exprs []string
+	for _, c := range s.columns(table, title) {
+		if c.desc != reverse {
+			exprs = append(exprs, c.expr+" DESC")
+		} else {
+			exprs = append(exprs, c.expr)
+		}
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// Where a page of a listing begins. Listing after the last seen entry pages
+// forward, while listing before the first seen one pages backward
+type position struct {
+	key      sortKey
+	set      bool
+	backward bool
+}
+
+// Reads the cursor of a page request, which should have been issued under the
+// same sort, as a position within another sort doesn't tell anything about
+// this one. No cursor lists from the start
+func readPosition(sealer cursor.Sealer, last, before string, sort Sort) (position, error) {
+	if last != "" && before != "" {
+		return position{}, oops.BadRequest{
+			Msg: "A page can't be listed both after and before an entry",
+			Err: errors.New("both `last` and `before` cursors are given")}
+	}
+	token, backward := last, false
+	if before != "" {
+		token, backward = before, true
+	} else if token == "" {
+		return position{}, nil
+	}
+
+	var key sortKey
+	if err := sealer.Open(token, &key); err != nil {
+		return position{}, oops.BadRequest{Msg: "The page cursor is malformed", Err: err}
+	} else if key.Sort != sort {
+		return position{}, oops.BadRequest{
+			Msg: "The page cursor belongs to another sort",
+			Err: fmt.Errorf("cursor issued for sort %d while listing by %d", key.Sort, sort)}
+	}
+	return position{key: key, set: true, backward: backward}, nil
+}
+
+// Whether the entry keyed by `key` lies within the page
+func (p position) admits(key sortKey) bool {
+	switch {
+	case !p.set:
+		return true
+	case p.backward:
+		return key.compare(p.key) < 0
+	}
+	return p.key.compare(key) < 0
+}
+
+// Keeps the `limit` entries nearest to the position, out of the sorted ones
+// it admits
+func nearest[T any](p position, sorted []T, limit int) []T {
+	if len(sorted) <= limit {
+		return sorted
+	} else if p.backward {
+		return sorted[len(sorted)-limit:]
+	}
+	return sorted[:limit]
+}
+
+// Condition selecting the entries of `table` within the page, whose cursor
+// values are appended to the query arguments
+func (p position) where(table, title string, args []any) (string, []any) {
+	if !p.set {
+		return "TRUE", args
+	}
+
+	columns := p.key.Sort.columns(table, title)
+	n := len(args)
+	args = append(args, p.key.values()...)
+	var alternatives []string
+	for idx, c := range columns {
+		var conds []string
+		for prev := range idx {
+			conds = append(conds, fmt.Sprintf("%s = $%d", columns[prev].expr, n+prev+1))
+		}
+		op := ">"
+		if c.desc != p.backward {
+			op = "<"
+		}
+		conds = append(conds, fmt.Sprintf("%s %s $%d", c.expr, op, n+idx+1))
+		alternatives = append(alternatives, "("+strings.Join(conds, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// ORDER BY expressions fetching the page, which walks the sort in reverse
+// when paging backward so that a LIMIT keeps the entries nearest to the
+// position. The page should be sorted back with `Sort.orderBy` afterwards
+func (p position) fetchOrder(sort Sort, table, title string) string {
+	return sort.order(table, title, p.backward)
+}
+
+// Position of an entry within the contents of a serie, which are listed by
+// their serie order for articles, or by their id for projects
+type serieContentKey struct {
+	Order     int  `json:"o"`
+	Nth       int  `json:"n"` // 1-based position within the listing
+	Ascending bool `json:"a"`
+}
+
+// Opaque cursor of the entry, to list the entries after it
+func (k serieContentKey) cursor(sealer cursor.Sealer) (string, error) {
+	return sealer.Seal(k)
+}
+
+// Reads the cursor of the last listed serie content, which should have been
+// issued for the same direction. No cursor lists from the start
+func readSerieContentCursor(sealer cursor.Sealer, last string, ascending bool) (serieContentKey, bool, error) {
+	if last == "" {
+		return serieContentKey{}, false, nil
+	}
+
+	var key serieContentKey
+	if err := sealer.Open(last, &key); err != nil {
+		return serieContentKey{}, false, oops.BadRequest{Msg: "The page cursor is malformed", Err: err}
+	} else if key.Ascending != ascending {
+		return serieContentKey{}, false, oops.BadRequest{
+			Msg: "The page cursor belongs to another order",
+			Err: errors.New("cursor issued for the opposite direction")}
+	}
+	return key, true, nil
+}
@@ -0,0 +1,210 @@
+package persistence
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+var (
+	testCreatedAt = time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	testUpdatedAt = time.Date(2026, 4, 2, 12, 30, 0, 123456000, time.UTC)
+)
+
+func TestPositionRoundTrip(t *testing.T) {
+	sealer := cursor.NewSealer([]byte("key"))
+	for _, sort := range []Sort{SortRelevance, SortUpdated, SortCreated, SortTitle, SortTitleDesc} {
+		key := newSortKey(sort, 7, "Hello World", testCreatedAt, testUpdatedAt, 0.5)
+		for _, backward := range []bool{false, true} {
+			sealed, err := key.cursor(sealer)
+			if err != nil {
+				t.Fatalf("sort %d: sealing the cursor failed: %v", sort, err)
+			}
+			last, before := sealed, ""
+			if backward {
+				last, before = "", last
+			}
+			got, err := readPosition(sealer, last, before, sort)
+			if err != nil {
+				t.Fatalf("sort %d: readPosition failed: %v", sort, err)
+			}
+			if !got.set || got.backward != backward {
+				t.Errorf("sort %d: got set=%t backward=%t, want backward=%t",
+					sort, got.set, got.backward, backward)
+			}
+			if !got.key.At.Equal(key.At) {
+				t.Errorf("sort %d: got time %v, want %v", sort, got.key.At, key.At)
+			}
+			got.key.At = key.At
+			if got.key != key {
+				t.Errorf("sort %d: got key %+v, want %+v", sort, got.key, key)
+			}
+		}
+	}
+}
+
+func TestSortKeyCursorError(t *testing.T) {
+	sealer := cursor.NewSealer([]byte("key"))
+	key := newSortKey(SortRelevance, 7, "", testCreatedAt, testUpdatedAt, float32(math.NaN()))
+	if _, err := key.cursor(sealer); err == nil {
+		t.Error("expected a relevance out of JSON to fail sealing")
+	}
+}
+
+func TestReadPosition(t *testing.T) {
+	sealer := cursor.NewSealer([]byte("key"))
+	key := newSortKey(SortUpdated, 7, "", testCreatedAt, testUpdatedAt, 0)
+	updated, err := key.cursor(sealer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := key.cursor(cursor.NewSealer([]byte("other key")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		last    string
+		before  string
+		sort    Sort
+		wantSet bool
+		wantErr bool
+	}{
+		{name: "no cursor", sort: SortUpdated},
+		{name: "after", last: updated, sort: SortUpdated, wantSet: true},
+		{name: "before", before: updated, sort: SortUpdated, wantSet: true},
+		{name: "both", last: updated, before: updated, sort: SortUpdated, wantErr: true},
+		{name: "another sort", last: updated, sort: SortCreated, wantErr: true},
+		{name: "malformed", last: "nope", sort: SortUpdated, wantErr: true},
+		{name: "another key", last: foreign, sort: SortUpdated, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPosition(sealer, tt.last, tt.before, tt.sort)
+			if tt.wantErr {
+				var badRequest oops.BadRequest
+				if !errors.As(err, &badRequest) {
+					t.Errorf("expected a bad request, got %v", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("readPosition failed: %v", err)
+			}
+			if got.set != tt.wantSet {
+				t.Errorf("got set=%t, want %t", got.set, tt.wantSet)
+			}
+		})
+	}
+}
+
+func TestPositionWhere(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     Sort
+		backward bool
+		want     string
+		wantArgs []any
+	}{
+		{
+			name:     "updated",
+			sort:     SortUpdated,
+			want:     "((articles.updated_at < $2) OR (articles.updated_at = $2 AND articles.id > $3))",
+			wantArgs: []any{"prior", testUpdatedAt, 7}},
+		{
+			name:     "updated backward",
+			sort:     SortUpdated,
+			backward: true,
+			want:     "((articles.updated_at > $2) OR (articles.updated_at = $2 AND articles.id < $3))",
+			wantArgs: []any{"prior", testUpdatedAt, 7}},
+		{
+			name:     "created",
+			sort:     SortCreated,
+			want:     "((articles.created_at < $2) OR (articles.created_at = $2 AND articles.id > $3))",
+			wantArgs: []any{"prior", testCreatedAt, 7}},
+		{
+			name: "title",
+			sort: SortTitle,
+			want: "((LOWER(articles.title) > $2) OR " +
+				"(LOWER(articles.title) = $2 AND articles.id > $3))",
+			wantArgs: []any{"prior", "hello world", 7}},
+		{
+			name:     "title descending backward",
+			sort:     SortTitleDesc,
+			backward: true,
+			want: "((LOWER(articles.title) > $2) OR " +
+				"(LOWER(articles.title) = $2 AND articles.id < $3))",
+			wantArgs: []any{"prior", "hello world", 7}},
+		{
+			name: "relevance",
+			sort: SortRelevance,
+			want: "((articles.relevance < $2) OR " +
+				"(articles.relevance = $2 AND articles.updated_at < $3) OR " +
+				"(articles.relevance = $2 AND articles.updated_at = $3 AND articles.id > $4))",
+			wantArgs: []any{"prior", float32(0.5), testUpdatedAt, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := position{
+				key:      newSortKey(tt.sort, 7, "Hello World", testCreatedAt, testUpdatedAt, 0.5),
+				set:      true,
+				backward: tt.backward}
+			got, args := p.where("articles", "title", []any{"prior"})
+			if got != tt.want {
+				t.Errorf("where\n got: %s\nwant: %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args\n got: %v\nwant: %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		got, args := position{}.where("articles", "title", []any{"prior"})
+		if got != "TRUE" || len(args) != 1 {
+			t.Errorf("got %q with %v", got, args)
+		}
+	})
+}
+
+// `admits` should agree with the order `where` selects in
+func TestPositionAdmits(t *testing.T) {
+	earlier := testUpdatedAt.Add(-time.Hour)
+	tests := []struct {
+		name  string
+		sort  Sort
+		key   sortKey // of the entry tested against the position
+		after bool    // whether it comes after the position
+	}{
+		{"updated earlier", SortUpdated, newSortKey(SortUpdated, 1, "", testCreatedAt, earlier, 0), true},
+		{"updated later", SortUpdated, newSortKey(SortUpdated, 1, "", testCreatedAt, earlier.Add(2*time.Hour), 0), false},
+		{"updated tie, greater id", SortUpdated, newSortKey(SortUpdated, 8, "", testCreatedAt, testUpdatedAt, 0), true},
+		{"updated tie, lesser id", SortUpdated, newSortKey(SortUpdated, 6, "", testCreatedAt, testUpdatedAt, 0), false},
+		{"title after", SortTitle, newSortKey(SortTitle, 1, "Zebra", testCreatedAt, testUpdatedAt, 0), true},
+		{"title before, ignoring case", SortTitle, newSortKey(SortTitle, 9, "apple", testCreatedAt, testUpdatedAt, 0), false},
+		{"title descending", SortTitleDesc, newSortKey(SortTitleDesc, 1, "apple", testCreatedAt, testUpdatedAt, 0), true},
+		{"less relevant", SortRelevance, newSortKey(SortRelevance, 1, "", testCreatedAt, testUpdatedAt, 0.25), true},
+		{"more relevant", SortRelevance, newSortKey(SortRelevance, 1, "", testCreatedAt, earlier, 0.75), false},
+		{"same relevance, earlier", SortRelevance, newSortKey(SortRelevance, 1, "", testCreatedAt, earlier, 0.5), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := newSortKey(tt.sort, 7, "Hello World", testCreatedAt, testUpdatedAt, 0.5)
+			forward := position{key: at, set: true}
+			backward := position{key: at, set: true, backward: true}
+			if got := forward.admits(tt.key); got != tt.after {
+				t.Errorf("forward admits = %t, want %t", got, tt.after)
+			}
+			if got := backward.admits(tt.key); got == tt.after {
+				t.Errorf("backward admits = %t, want %t", got, !tt.after)
+			}
+			if got := (position{}).admits(tt.key); !got {
+				t.Error("an unset position should admit every entry")
+			}
+		})
+	}
+}
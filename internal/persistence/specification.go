@@ -1,7 +1,9 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,10 +11,11 @@ import (
 	"github.com/solsteace/misite/internal/utility/lib/oops"
 )
 
-func (p Pg) Article(id int) (entity.ArticlePage, error) {
+func (p Pg) Article(ctx context.Context, id int) (entity.ArticlePage, error) {
 	query := `
 		SELECT
 			articles.id AS "id",
+			articles.slug AS "slug",
 			articles.title AS "title",
 			articles.subtitle AS "subtitle",
 			articles.content AS "content",
@@ -21,16 +24,22 @@ func (p Pg) Article(id int) (entity.ArticlePage, error) {
 			tags.id AS "tag.id",
 			tags.name AS "tag.name",
 			series.id AS "serie.id",
+			series.slug AS "serie.slug",
 			series.name AS "serie.name"
 		FROM articles
-		LEFT JOIN series ON articles.serie_id = series.id
+		LEFT JOIN series
+			ON articles.serie_id = series.id
+			AND series.state IN ('published', 'archived')
 		LEFT JOIN article_tags ON articles.id = article_tags.article_id
 		LEFT JOIN tags ON article_tags.tag_id = tags.id
-		WHERE articles.id = $1`
+		WHERE
+			articles.id = $1
+			AND articles.state IN ('published', 'archived')`
 	args := []any{id}
 
 	var rows []struct {
 		Id        int       `db:"id"`
+		Slug      string    `db:"slug"`
 		Title     string    `db:"title"`
 		Subtitle  string    `db:"subtitle"`
 		Content   string    `db:"content"`
@@ -39,6 +48,7 @@ func (p Pg) Article(id int) (entity.ArticlePage, error) {
 
 		Serie struct {
 			Id   sql.Null[int]    `db:"id"`
+			Slug sql.Null[string] `db:"slug"`
 			Name sql.Null[string] `db:"name"`
 		}
 		Tag struct {
@@ -46,7 +56,7 @@ func (p Pg) Article(id int) (entity.ArticlePage, error) {
 			Name sql.Null[string] `db:"name"`
 		}
 	}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return entity.ArticlePage{}, fmt.Errorf(
 			"persistence<Pg.Article>: %w", err)
 	} else if len(rows) == 0 {
@@ -56,6 +66,7 @@ func (p Pg) Article(id int) (entity.ArticlePage, error) {
 
 	article := entity.ArticlePage{
 		Id:        rows[0].Id,
+		Slug:      rows[0].Slug,
 		Title:     rows[0].Title,
 		Subtitle:  rows[0].Subtitle,
 		Content:   rows[0].Content,
@@ -66,9 +77,11 @@ func (p Pg) Article(id int) (entity.ArticlePage, error) {
 		if r.Serie.Id.Valid {
 			article.Serie = &struct {
 				Id   int
+				Slug string
 				Name string
 			}{
 				Id:   r.Serie.Id.V,
+				Slug: r.Serie.Slug.V,
 				Name: r.Serie.Name.V}
 		}
 		if r.Tag.Id.Valid {
@@ -83,7 +96,7 @@ func (p Pg) Article(id int) (entity.ArticlePage, error) {
 	return article, nil
 }
 
-func (p Pg) CountArticleMatchingTags(tagId []int) ([]entity.Tag, []int, error) {
+func (p Pg) CountArticleMatchingTags(ctx context.Context, tagId []int) ([]entity.Tag, []int, error) {
 	query := `
 		WITH 
 			tag_count_by_article AS (
@@ -91,7 +104,10 @@ func (p Pg) CountArticleMatchingTags(tagId []int) ([]entity.Tag, []int, error) {
 					tag_id AS "id",
 					COUNT(article_id) AS "count"
 				FROM article_tags
-				WHERE article_tags.tag_id = ANY($1::int[])
+				JOIN articles ON article_tags.article_id = articles.id
+				WHERE
+					article_tags.tag_id = ANY($1::int[])
+					AND articles.state = 'published'
 				GROUP BY tag_id)
 		SELECT 
 			tag_count.id,
@@ -107,7 +123,7 @@ func (p Pg) CountArticleMatchingTags(tagId []int) ([]entity.Tag, []int, error) {
 		TagName string `db:"name"`
 	}
 	args := []any{tagId}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.Tag{}, []int{}, fmt.Errorf(
 			"persistence<Pg.CountArticleMatchingTags>: %w", err)
 	}
@@ -123,16 +139,18 @@ func (p Pg) CountArticleMatchingTags(tagId []int) ([]entity.Tag, []int, error) {
 	return tags, count, nil
 }
 
-func (p Pg) Project(id int) (entity.ProjectPage, error) {
+func (p Pg) Project(ctx context.Context, id int) (entity.ProjectPage, error) {
 	query := `
 		SELECT
 			projects.id AS "id",
+			projects.slug AS "slug",
 			projects.name AS "name",
 			projects.synopsis AS "synopsis",
 			projects.description AS "description",
 			projects.created_at AS "created_at",
 			projects.updated_at AS "updated_at",
 			series.id AS "serie.id",
+			series.slug AS "serie.slug",
 			series.name AS "serie.name",
 			tags.id AS "tag.id",
 			tags.name AS "tag.name",
@@ -143,13 +161,18 @@ func (p Pg) Project(id int) (entity.ProjectPage, error) {
 		LEFT JOIN project_links ON project_links.project_id = projects.id
 		LEFT JOIN project_tags ON project_tags.project_id = projects.id
 		LEFT JOIN tags ON tags.id = project_tags.tag_id
-		LEFT JOIN series ON series.id = projects.devblog_serie
-		WHERE projects.id = $1
+		LEFT JOIN series
+			ON series.id = projects.devblog_serie
+			AND series.state IN ('published', 'archived')
+		WHERE
+			projects.id = $1
+			AND projects.state IN ('published', 'archived')
 		ORDER BY projects.id`
 	args := []any{id}
 
 	var rows []struct {
 		Id          int       `db:"id"`
+		Slug        string    `db:"slug"`
 		Name        string    `db:"name"`
 		Synopsis    string    `db:"synopsis"`
 		Description string    `db:"description"`
@@ -158,6 +181,7 @@ func (p Pg) Project(id int) (entity.ProjectPage, error) {
 
 		Serie struct {
 			Id   sql.Null[int]    `db:"id"`
+			Slug sql.Null[string] `db:"slug"`
 			Name sql.Null[string] `db:"name"`
 		}
 		Tag struct {
@@ -170,7 +194,7 @@ func (p Pg) Project(id int) (entity.ProjectPage, error) {
 			Url         sql.Null[string] `db:"url"`
 		}
 	}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return entity.ProjectPage{}, fmt.Errorf(
 			"persistence<pg.Project>: %w", err)
 	} else if len(rows) == 0 {
@@ -181,6 +205,7 @@ func (p Pg) Project(id int) (entity.ProjectPage, error) {
 	projectRow := rows[0]
 	project := entity.ProjectPage{
 		Id:          projectRow.Id,
+		Slug:        projectRow.Slug,
 		Name:        projectRow.Name,
 		Synopsis:    projectRow.Synopsis,
 		Description: projectRow.Description,
@@ -189,9 +214,11 @@ func (p Pg) Project(id int) (entity.ProjectPage, error) {
 	if projectRow.Serie.Id.Valid {
 		project.Serie = &struct {
 			Id   int
+			Slug string
 			Name string
 		}{
 			Id:   projectRow.Serie.Id.V,
+			Slug: projectRow.Serie.Slug.V,
 			Name: projectRow.Serie.Name.V}
 	}
 
@@ -226,7 +253,7 @@ func (p Pg) Project(id int) (entity.ProjectPage, error) {
 	return project, nil
 }
 
-func (p Pg) CountProjectMatchingTags(tagId []int) ([]entity.Tag, []int, error) {
+func (p Pg) CountProjectMatchingTags(ctx context.Context, tagId []int) ([]entity.Tag, []int, error) {
 	query := `
 		WITH 
 			tag_count_by_project AS (
@@ -234,7 +261,10 @@ func (p Pg) CountProjectMatchingTags(tagId []int) ([]entity.Tag, []int, error) {
 					tag_id AS "id",
 					COUNT(project_id) AS "count"
 				FROM project_tags
-				WHERE project_tags.tag_id = ANY($1::int[])
+				JOIN projects ON project_tags.project_id = projects.id
+				WHERE
+					project_tags.tag_id = ANY($1::int[])
+					AND projects.state = 'published'
 				GROUP BY tag_id)
 		SELECT 
 			tag_count.id,
@@ -250,7 +280,7 @@ func (p Pg) CountProjectMatchingTags(tagId []int) ([]entity.Tag, []int, error) {
 		Count   int    `db:"count"`
 		TagName string `db:"name"`
 	}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.Tag{}, []int{}, fmt.Errorf(
 			"persistence<Pg.CountProjectMatchingTags>: %w", err)
 	}
@@ -266,9 +296,10 @@ func (p Pg) CountProjectMatchingTags(tagId []int) ([]entity.Tag, []int, error) {
 	return tags, count, nil
 }
 
-func (p Pg) Serie(id int) (entity.SeriePage, error) {
+func (p Pg) Serie(ctx context.Context, id int) (entity.SeriePage, error) {
 	var row struct {
 		Id          int    `db:"id"`
+		Slug        string `db:"slug"`
 		Name        string `db:"name"`
 		Thumbnail   string `db:"thumbnail"`
 		Description string `db:"description"`
@@ -278,24 +309,36 @@ func (p Pg) Serie(id int) (entity.SeriePage, error) {
 	query := `
 		SELECT
 			series.id,
+			series.slug,
 			series.name,
 			series.thumbnail,
 			series.description,
-			COUNT(DISTINCT articles.id) AS "n_articles",
-			COUNT(DISTINCT projects.id) AS "n_projects"
+			(SELECT COUNT(*)
+				FROM articles
+				WHERE
+					articles.serie_id = series.id
+					AND articles.state = 'published') AS "n_articles",
+			(SELECT COUNT(*)
+				FROM projects
+				WHERE
+					projects.devblog_serie = series.id
+					AND projects.state = 'published') AS "n_projects"
 		FROM series
-		JOIN projects ON projects.devblog_serie = series.id
-		JOIN articles ON articles.serie_id = series.id
-		WHERE series.id = $1
-		GROUP BY series.id`
+		WHERE
+			series.id = $1
+			AND series.state IN ('published', 'archived')`
 	args := []any{id}
-	if err := p.db.Get(&row, query, args...); err != nil {
+	if err := p.conn(ctx).GetContext(ctx, &row, query, args...); errors.Is(err, sql.ErrNoRows) {
+		return entity.SeriePage{}, fmt.Errorf(
+			"persistence<Pg.Serie>: %w", oops.NotFound{})
+	} else if err != nil {
 		return entity.SeriePage{}, fmt.Errorf(
 			"persistence<Pg.Serie>: %w", err)
 	}
 
 	serie := entity.SeriePage{
 		Id:          row.Id,
+		Slug:        row.Slug,
 		Name:        row.Name,
 		Thumbnail:   row.Thumbnail,
 		Description: row.Description,
@@ -305,50 +348,182 @@ func (p Pg) Serie(id int) (entity.SeriePage, error) {
 }
 
 type SerieContentQueryParam struct {
-	Page  int
-	Limit int
+	Last      string // cursor of the entry to list after
+	Limit     int
+	Ascending bool // in reading order, from the first part on. Articles only
+}
+
+// Table of contents of a serie, in reading order
+func (p Pg) SerieContents(ctx context.Context, id int) ([]entity.SerieContentEntry, error) {
+	var rows []struct {
+		Id    int    `db:"id"`
+		Slug  string `db:"slug"`
+		Part  int    `db:"part"`
+		Title string `db:"title"`
+	}
+	query := `
+		SELECT
+			id,
+			slug,
+			ROW_NUMBER() OVER (ORDER BY serie_order) AS "part",
+			title
+		FROM articles
+		WHERE serie_id = $1 AND state = 'published'
+		ORDER BY serie_order`
+	args := []any{id}
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
+		return []entity.SerieContentEntry{}, fmt.Errorf(
+			"persistence<Pg.SerieContents>: %w", err)
+	}
+
+	var contents []entity.SerieContentEntry
+	for _, r := range rows {
+		contents = append(contents, entity.SerieContentEntry{
+			Id:    r.Id,
+			Slug:  r.Slug,
+			Part:  r.Part,
+			Title: r.Title})
+	}
+	return contents, nil
 }
 
-func (p Pg) SerieArticleList(id int, param SerieContentQueryParam) ([]entity.SeriePageArticleList, error) {
+// Position of the article within its serie, along with its neighbouring parts
+func (p Pg) ArticleSeriePart(ctx context.Context, id int) (entity.SeriePart, error) {
+	var row struct {
+		Part          int              `db:"part"`
+		NPart         int              `db:"n_part"`
+		PreviousId    sql.Null[int]    `db:"previous_id"`
+		PreviousSlug  sql.Null[string] `db:"previous_slug"`
+		PreviousTitle sql.Null[string] `db:"previous_title"`
+		NextId        sql.Null[int]    `db:"next_id"`
+		NextSlug      sql.Null[string] `db:"next_slug"`
+		NextTitle     sql.Null[string] `db:"next_title"`
+	}
+	query := `
+		WITH parts AS (
+			SELECT
+				articles.id,
+				ROW_NUMBER() OVER serie AS "part",
+				COUNT(*) OVER () AS "n_part",
+				LAG(articles.id) OVER serie AS "previous_id",
+				LAG(articles.slug) OVER serie AS "previous_slug",
+				LAG(articles.title) OVER serie AS "previous_title",
+				LEAD(articles.id) OVER serie AS "next_id",
+				LEAD(articles.slug) OVER serie AS "next_slug",
+				LEAD(articles.title) OVER serie AS "next_title"
+			FROM articles
+			JOIN articles AS source
+				ON source.serie_id = articles.serie_id
+				AND source.id = $1
+			WHERE articles.state = 'published'
+			WINDOW serie AS (ORDER BY articles.serie_order))
+		SELECT
+			part,
+			n_part,
+			previous_id,
+			previous_slug,
+			previous_title,
+			next_id,
+			next_slug,
+			next_title
+		FROM parts
+		WHERE id = $1`
+	args := []any{id}
+	if err := p.conn(ctx).GetContext(ctx, &row, query, args...); errors.Is(err, sql.ErrNoRows) {
+		return entity.SeriePart{}, nil
+	} else if err != nil {
+		return entity.SeriePart{}, fmt.Errorf(
+			"persistence<Pg.ArticleSeriePart>: %w", err)
+	}
+
+	part := entity.SeriePart{Part: row.Part, NPart: row.NPart}
+	if row.PreviousId.Valid {
+		part.Previous = &entity.SerieContentEntry{
+			Id:    row.PreviousId.V,
+			Slug:  row.PreviousSlug.V,
+			Part:  row.Part - 1,
+			Title: row.PreviousTitle.V}
+	}
+	if row.NextId.Valid {
+		part.Next = &entity.SerieContentEntry{
+			Id:    row.NextId.V,
+			Slug:  row.NextSlug.V,
+			Part:  row.Part + 1,
+			Title: row.NextTitle.V}
+	}
+	return part, nil
+}
+
+func (p Pg) SerieArticleList(ctx context.Context, id int, param SerieContentQueryParam) ([]entity.SeriePageArticleList, error) {
 	if param.Limit < 1 {
 		param.Limit = 10
 	}
-	if param.Page < 1 {
-		param.Page = 1
+	last, hasLast, err := readSerieContentCursor(p.cursors, param.Last, param.Ascending)
+	if err != nil {
+		return []entity.SeriePageArticleList{}, fmt.Errorf(
+			"persistence<Pg.SerieArticleList>: %w", err)
+	}
+
+	afterLast, order := "parts.serie_order < $3", "DESC"
+	if param.Ascending {
+		afterLast, order = "parts.serie_order > $3", "ASC"
+	}
+	if !hasLast {
+		afterLast = "$3::int IS NULL"
 	}
 
 	var rows []struct {
-		Id        int       `db:"id"`
-		Title     string    `db:"title"`
-		Synopsis  string    `db:"synopsis"`
-		CreatedAt time.Time `db:"created_at"`
-		UpdatedAt time.Time `db:"updated_at"`
+		Id         int       `db:"id"`
+		Slug       string    `db:"slug"`
+		Part       int       `db:"part"`
+		SerieOrder int       `db:"serie_order"`
+		Title      string    `db:"title"`
+		Synopsis   string    `db:"synopsis"`
+		CreatedAt  time.Time `db:"created_at"`
+		UpdatedAt  time.Time `db:"updated_at"`
 	}
 	query := `
-		SELECT
-			id,
-			title,
-			subtitle AS "synopsis",
-			created_at,
-			updated_at
-		FROM articles
-		WHERE serie_id = $1
-		ORDER BY serie_order DESC
-		LIMIT $2 OFFSET $3`
-	args := []any{
-		id,
-		param.Limit,
-		(param.Page - 1) * param.Limit}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+		SELECT *
+		FROM (
+			SELECT
+				id,
+				slug,
+				ROW_NUMBER() OVER (ORDER BY serie_order) AS "part",
+				serie_order,
+				title,
+				subtitle AS "synopsis",
+				created_at,
+				updated_at
+			FROM articles
+			WHERE serie_id = $1 AND state = 'published'
+		) AS parts
+		WHERE ` + afterLast + `
+		ORDER BY parts.serie_order ` + order + `
+		LIMIT $2`
+	args := []any{id, param.Limit, sql.Null[int]{V: last.Order, Valid: hasLast}}
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.SeriePageArticleList{}, fmt.Errorf(
 			"persistence<Pg.SerieArticleList>: %w", err)
 	}
 
 	var serieArticles []entity.SeriePageArticleList
-	for _, r := range rows {
+	for idx, r := range rows {
+		key := serieContentKey{
+			Order:     r.SerieOrder,
+			Nth:       last.Nth + idx + 1,
+			Ascending: param.Ascending}
+		cursor, err := key.cursor(p.cursors)
+		if err != nil {
+			return []entity.SeriePageArticleList{}, fmt.Errorf(
+				"persistence<Pg.SerieArticleList>: %w", err)
+		}
 		serieArticles = append(
 			serieArticles, entity.SeriePageArticleList{
 				Id:        r.Id,
+				Slug:      r.Slug,
+				Part:      r.Part,
+				Nth:       key.Nth,
+				Cursor:    cursor,
 				Title:     r.Title,
 				Synopsis:  r.Synopsis,
 				CreatedAt: r.CreatedAt,
@@ -358,15 +533,19 @@ func (p Pg) SerieArticleList(id int, param SerieContentQueryParam) ([]entity.Ser
 	return serieArticles, nil
 }
 
-func (p Pg) SerieProjectList(id int, param SerieContentQueryParam) ([]entity.SeriePageProjectList, error) {
+func (p Pg) SerieProjectList(ctx context.Context, id int, param SerieContentQueryParam) ([]entity.SeriePageProjectList, error) {
 	if param.Limit < 1 {
 		param.Limit = 10
 	}
-	if param.Page < 1 {
-		param.Page = 1
+	last, hasLast, err := readSerieContentCursor(p.cursors, param.Last, false)
+	if err != nil {
+		return []entity.SeriePageProjectList{}, fmt.Errorf(
+			"persistence<Pg.SerieProjectList>: %w", err)
 	}
+
 	var rows []struct {
 		Id        int       `db:"id"`
+		Slug      string    `db:"slug"`
 		Name      string    `db:"name"`
 		Synopsis  string    `db:"synopsis"`
 		CreatedAt time.Time `db:"created_at"`
@@ -375,27 +554,36 @@ func (p Pg) SerieProjectList(id int, param SerieContentQueryParam) ([]entity.Ser
 	query := `
 		SELECT
 			id,
+			slug,
 			name,
 			synopsis,
 			created_at,
 			updated_at
 		FROM projects
-		WHERE devblog_serie = $1
-		LIMIT $2 OFFSET $3`
-	args := []any{
-		id,
-		param.Limit,
-		(param.Page - 1) * param.Limit}
-	if err := p.db.Select(&rows, query, args...); err != nil {
+		WHERE devblog_serie = $1 AND state = 'published'
+			AND ($3::int IS NULL OR id > $3)
+		ORDER BY id
+		LIMIT $2`
+	args := []any{id, param.Limit, sql.Null[int]{V: last.Order, Valid: hasLast}}
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
 		return []entity.SeriePageProjectList{}, fmt.Errorf(
 			"persistence<Pg.SerieProjectList>: %w", err)
 	}
 
 	var serieProjects []entity.SeriePageProjectList
-	for _, r := range rows {
+	for idx, r := range rows {
+		key := serieContentKey{Order: r.Id, Nth: last.Nth + idx + 1}
+		cursor, err := key.cursor(p.cursors)
+		if err != nil {
+			return []entity.SeriePageProjectList{}, fmt.Errorf(
+				"persistence<Pg.SerieProjectList>: %w", err)
+		}
 		serieProjects = append(
 			serieProjects, entity.SeriePageProjectList{
 				Id:        r.Id,
+				Slug:      r.Slug,
+				Nth:       key.Nth,
+				Cursor:    cursor,
 				Name:      r.Name,
 				Synopsis:  r.Synopsis,
 				CreatedAt: r.CreatedAt,
@@ -404,3 +592,143 @@ func (p Pg) SerieProjectList(id int, param SerieContentQueryParam) ([]entity.Ser
 	}
 	return serieProjects, nil
 }
+
+type RelatedQueryParam struct {
+	Limit int
+}
+
+// Other articles ranked by the tags they share with the article, where
+// belonging to the same serie weighs as much as another shared tag
+func (p Pg) RelatedArticles(ctx context.Context, id int, param RelatedQueryParam) ([]entity.RelatedEntry, error) {
+	if param.Limit < 1 {
+		param.Limit = 5
+	}
+
+	query := `
+		WITH
+			source AS (
+				SELECT serie_id FROM articles WHERE id = $1),
+			shared AS (
+				SELECT
+					article_tags.article_id AS "id",
+					COUNT(*) AS "n_tag"
+				FROM article_tags
+				JOIN article_tags AS own
+					ON own.tag_id = article_tags.tag_id
+					AND own.article_id = $1
+				WHERE article_tags.article_id <> $1
+				GROUP BY article_tags.article_id)
+		SELECT
+			articles.id,
+			articles.slug,
+			articles.title,
+			articles.subtitle AS "synopsis",
+			COALESCE(shared.n_tag, 0) AS "shared_tags",
+			COALESCE(articles.serie_id = source.serie_id, FALSE) AS "same_serie"
+		FROM articles
+		CROSS JOIN source
+		LEFT JOIN shared ON shared.id = articles.id
+		WHERE articles.id <> $1
+			AND articles.state = 'published'
+			AND (shared.id IS NOT NULL OR articles.serie_id = source.serie_id)
+		ORDER BY
+			COALESCE(shared.n_tag, 0)
+				+ CASE WHEN articles.serie_id = source.serie_id THEN 1 ELSE 0 END DESC,
+			articles.updated_at DESC,
+			articles.id
+		LIMIT $2`
+	args := []any{id, param.Limit}
+
+	var rows []struct {
+		Id         int    `db:"id"`
+		Slug       string `db:"slug"`
+		Title      string `db:"title"`
+		Synopsis   string `db:"synopsis"`
+		SharedTags int    `db:"shared_tags"`
+		SameSerie  bool   `db:"same_serie"`
+	}
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
+		return []entity.RelatedEntry{}, fmt.Errorf(
+			"persistence<Pg.RelatedArticles>: %w", err)
+	}
+
+	var related []entity.RelatedEntry
+	for _, r := range rows {
+		related = append(related, entity.RelatedEntry{
+			Id:         r.Id,
+			Slug:       r.Slug,
+			Title:      r.Title,
+			Synopsis:   r.Synopsis,
+			SharedTags: r.SharedTags,
+			SameSerie:  r.SameSerie})
+	}
+	return related, nil
+}
+
+// Other projects ranked by the tags they share with the project, where
+// belonging to the same serie weighs as much as another shared tag
+func (p Pg) RelatedProjects(ctx context.Context, id int, param RelatedQueryParam) ([]entity.RelatedEntry, error) {
+	if param.Limit < 1 {
+		param.Limit = 5
+	}
+
+	query := `
+		WITH
+			source AS (
+				SELECT devblog_serie FROM projects WHERE id = $1),
+			shared AS (
+				SELECT
+					project_tags.project_id AS "id",
+					COUNT(*) AS "n_tag"
+				FROM project_tags
+				JOIN project_tags AS own
+					ON own.tag_id = project_tags.tag_id
+					AND own.project_id = $1
+				WHERE project_tags.project_id <> $1
+				GROUP BY project_tags.project_id)
+		SELECT
+			projects.id,
+			projects.slug,
+			projects.name AS "title",
+			projects.synopsis,
+			COALESCE(shared.n_tag, 0) AS "shared_tags",
+			COALESCE(projects.devblog_serie = source.devblog_serie, FALSE) AS "same_serie"
+		FROM projects
+		CROSS JOIN source
+		LEFT JOIN shared ON shared.id = projects.id
+		WHERE projects.id <> $1
+			AND projects.state = 'published'
+			AND (shared.id IS NOT NULL OR projects.devblog_serie = source.devblog_serie)
+		ORDER BY
+			COALESCE(shared.n_tag, 0)
+				+ CASE WHEN projects.devblog_serie = source.devblog_serie THEN 1 ELSE 0 END DESC,
+			projects.updated_at DESC,
+			projects.id
+		LIMIT $2`
+	args := []any{id, param.Limit}
+
+	var rows []struct {
+		Id         int    `db:"id"`
+		Slug       string `db:"slug"`
+		Title      string `db:"title"`
+		Synopsis   string `db:"synopsis"`
+		SharedTags int    `db:"shared_tags"`
+		SameSerie  bool   `db:"same_serie"`
+	}
+	if err := p.conn(ctx).SelectContext(ctx, &rows, query, args...); err != nil {
+		return []entity.RelatedEntry{}, fmt.Errorf(
+			"persistence<Pg.RelatedProjects>: %w", err)
+	}
+
+	var related []entity.RelatedEntry
+	for _, r := range rows {
+		related = append(related, entity.RelatedEntry{
+			Id:         r.Id,
+			Slug:       r.Slug,
+			Title:      r.Title,
+			Synopsis:   r.Synopsis,
+			SharedTags: r.SharedTags,
+			SameSerie:  r.SameSerie})
+	}
+	return related, nil
+}
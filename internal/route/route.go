@@ -2,9 +2,11 @@ package route
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -28,8 +30,17 @@ func (r Router) Handle(fx httpHandlerWithError) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		if err := fx(w, req); err != nil {
 			ctx := req.Context()
+
+			// The client is gone, nobody is left to read the error page
+			if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+				log.Println(err)
+				return
+			}
+
+			// The error page is still rendered after the request timed out
+			ctx = context.WithoutCancel(ctx)
 			switch statusCode := adapter.HttpStatusCode(err); statusCode {
-			case http.StatusNotFound:
+			case http.StatusBadRequest, http.StatusNotFound, http.StatusGatewayTimeout:
 				ctx = context.WithValue(ctx, "err", statusCode)
 			default:
 				ctx = context.WithValue(ctx, "err", http.StatusInternalServerError)
@@ -43,6 +54,18 @@ func (r Router) Handle(fx httpHandlerWithError) http.HandlerFunc {
 	}
 }
 
+// Abandons the queries of a request taking longer than `timeout`. Queries are
+// abandoned too once the client disconnects, as the request context is done
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func (r Router) UseOn(parent *chi.Mux) {
 	router := chi.NewRouter()
 
@@ -54,14 +77,27 @@ func (r Router) UseOn(parent *chi.Mux) {
 			"/static/",
 			http.FileServer(http.Dir("./static"))).ServeHTTP)
 
-	router.Get("/project/{id}", r.Handle(r.handler.Project))
-	router.Get("/article/{id}", r.Handle(r.handler.Article))
-	router.Get("/serie/{id}", r.Handle(r.handler.Serie))
+	router.Get("/project/{ref}", r.Handle(r.handler.Project))
+	router.Get("/article/{ref}", r.Handle(r.handler.Article))
+	router.Get("/article/{ref}/history", r.Handle(r.handler.ArticleHistory))
+	router.Get("/article/{ref}/diff", r.Handle(r.handler.ArticleDiff))
+	router.Get("/serie/{ref}", r.Handle(r.handler.Serie))
+	router.Get("/serie/{ref}/articles", r.Handle(r.handler.SerieArticles))
+	router.Get("/serie/{ref}/projects", r.Handle(r.handler.SerieProjects))
+	for _, name := range []string{"feed.xml", "rss.xml", "feed.json"} {
+		router.Get("/"+name, r.Handle(r.handler.Feed))
+		router.Get("/serie/{ref}/"+name, r.Handle(r.handler.SerieFeed))
+		router.Get("/tag/{name}/"+name, r.Handle(r.handler.TagFeed))
+	}
+	router.Get("/sitemap.xml", r.Handle(r.handler.Sitemap))
+	router.Get("/sitemap-{part}.xml", r.Handle(r.handler.SitemapPart))
+	router.Get("/robots.txt", r.Handle(r.handler.Robots))
 	router.Get("/write", r.Handle(r.handler.MockSpace))
 	router.Get("/tags", r.Handle(r.handler.TagList))
 	router.Get("/series", r.Handle(r.handler.SerieList))
 	router.Get("/articles", r.Handle(r.handler.ArticleList))
 	router.Get("/projects", r.Handle(r.handler.ProjectList))
+	router.Get("/search", r.Handle(r.handler.Search))
 	router.Get("/home", r.Handle(r.handler.Home))
 	router.Get("/", r.Handle(r.handler.Home))
 	router.NotFound(r.Handle(
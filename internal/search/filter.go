@@ -0,0 +1,403 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/solsteace/misite/internal/persistence"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+const (
+	opTag     = "tag"
+	opSerie   = "serie"
+	opTitle   = "title"
+	opAfter   = "after"
+	opBefore  = "before"
+	opCreated = "created"
+	opSort    = "sort"
+)
+
+// Values of `sort:`, where `-` reverses the title order
+var sorts = map[string]persistence.Sort{
+	"updated": persistence.SortUpdated,
+	"created": persistence.SortCreated,
+	"title":   persistence.SortTitle,
+	"-title":  persistence.SortTitleDesc,
+}
+
+// Reads an article search, like `-tag:draft serie:go|sql "query planner"`.
+// `after:`, `before:` and `created:` bound the creation date by a year,
+// month or day, like `after:2025-01 before:2025-06-30` where both ends are
+// inclusive
+func Articles(input string) (persistence.ExplorationQueryParam, error) {
+	param, err := exploration(input)
+	if err != nil {
+		return persistence.ExplorationQueryParam{}, badValues("search<Articles>", err)
+	}
+	return param, nil
+}
+
+// Reads a project search, like `tag:go,(cli|tui) -serie:archive sort:-title`,
+// with the same operators as `Articles`
+func Projects(input string) (persistence.ExplorationQueryParam, error) {
+	param, err := exploration(input)
+	if err != nil {
+		return persistence.ExplorationQueryParam{}, badValues("search<Projects>", err)
+	}
+	return param, nil
+}
+
+// Reads a serie search, where bare words and `title:` both match the name.
+// Series are sorted by their creation unless `sort:title` or `sort:-title`
+func Series(input string) (persistence.SerieExplorationQueryParam, error) {
+	root, err := Parse(input)
+	if err != nil {
+		return persistence.SerieExplorationQueryParam{}, badValues("search<Series>", err)
+	}
+
+	var param persistence.SerieExplorationQueryParam
+	w := walker{
+		operators: map[string]operator{
+			opTitle: {
+				include: func(values [][]string, pos int) error {
+					for _, group := range values {
+						if len(group) > 1 {
+							return Error{Pos: pos, Msg: "`title:` doesn't take alternatives"}
+						}
+						param.Include.Name = append(param.Include.Name, group...)
+					}
+					return nil
+				},
+				exclude: func(values []string, _ int) error {
+					param.Exclude.Name = append(param.Exclude.Name, values...)
+					return nil
+				}},
+			opAfter:   after(&param.Created),
+			opBefore:  before(&param.Created),
+			opCreated: created(&param.Created),
+			opSort: sortBy(&param.Sort,
+				persistence.SortCreated, persistence.SortTitle, persistence.SortTitleDesc)},
+		text: func(value string, negated bool) {
+			if negated {
+				param.Exclude.Name = append(param.Exclude.Name, value)
+			} else {
+				param.Include.Name = append(param.Include.Name, value)
+			}
+		}}
+	if err := w.walk(root, false); err != nil {
+		return persistence.SerieExplorationQueryParam{}, badValues("search<Series>", err)
+	}
+	return param, nil
+}
+
+// Reads a global search. Articles and projects take the operators of
+// `Articles`, while series and tags are matched by their name against the
+// keywords. Series are left out when searching by `tag:` or `serie:`, and
+// tags when searching by anything other than keywords
+func Everything(input string) (persistence.SearchQueryParam, error) {
+	param, err := exploration(input)
+	if err != nil {
+		return persistence.SearchQueryParam{}, badValues("search<Everything>", err)
+	}
+
+	articles, projects := param, param
+	everything := persistence.SearchQueryParam{
+		Articles: &articles,
+		Projects: &projects}
+	if len(param.Include.Tag) > 0 || len(param.Include.Serie) > 0 ||
+		len(param.Exclude.Tag) > 0 || len(param.Exclude.Serie) > 0 {
+		return everything, nil
+	}
+
+	var series persistence.SerieExplorationQueryParam
+	series.Include.Name = param.Include.Keyword
+	series.Exclude.Name = param.Exclude.Keyword
+	series.Created = param.Created
+	series.Sort = param.Sort
+	everything.Series = &series
+	if len(param.Include.Keyword) > 0 && param.Created == (persistence.TimeRange{}) {
+		var tags persistence.TagExplorationQueryParam
+		tags.Include.Name = param.Include.Keyword
+		tags.Exclude.Name = param.Exclude.Keyword
+		everything.Tags = &tags
+	}
+	return everything, nil
+}
+
+// Filters shared by the article and project searches
+func exploration(input string) (persistence.ExplorationQueryParam, error) {
+	root, err := Parse(input)
+	if err != nil {
+		return persistence.ExplorationQueryParam{}, err
+	}
+
+	var param persistence.ExplorationQueryParam
+	w := walker{
+		operators: map[string]operator{
+			opTag: {
+				include: func(values [][]string, _ int) error {
+					param.Include.Tag = append(param.Include.Tag, values...)
+					return nil
+				},
+				exclude: func(values []string, _ int) error {
+					param.Exclude.Tag = append(param.Exclude.Tag, values...)
+					return nil
+				}},
+			opSerie: {
+				// An entry belongs to a single serie at most, hence `,` is
+				// treated as `|`
+				include: func(values [][]string, _ int) error {
+					param.Include.Serie = append(param.Include.Serie, slices.Concat(values...)...)
+					return nil
+				},
+				exclude: func(values []string, _ int) error {
+					param.Exclude.Serie = append(param.Exclude.Serie, values...)
+					return nil
+				}},
+			opAfter:   after(&param.Created),
+			opBefore:  before(&param.Created),
+			opCreated: created(&param.Created),
+			opSort: sortBy(&param.Sort,
+				persistence.SortUpdated, persistence.SortCreated,
+				persistence.SortTitle, persistence.SortTitleDesc)},
+		text: func(value string, negated bool) {
+			if negated {
+				param.Exclude.Keyword = append(param.Exclude.Keyword, value)
+			} else {
+				param.Include.Keyword = append(param.Include.Keyword, value)
+			}
+		}}
+	if err := w.walk(root, false); err != nil {
+		return persistence.ExplorationQueryParam{}, err
+	}
+	return param, nil
+}
+
+// Handles the values of an operator
+type operator struct {
+	include func(values [][]string, pos int) error
+	exclude func(values []string, pos int) error // a negated term excludes every of its values
+}
+
+// Turns a syntax tree into filters, dispatching terms to their operators
+type walker struct {
+	operators map[string]operator
+	text      func(value string, negated bool)
+}
+
+func (w walker) walk(node Node, negated bool) error {
+	switch n := node.(type) {
+	case Not:
+		return w.walk(n.Node, !negated)
+	case Text:
+		w.text(n.Value, negated)
+		return nil
+	case Term:
+		op, err := w.operator(n)
+		if err != nil {
+			return err
+		} else if negated {
+			return op.exclude(slices.Concat(n.Values...), n.Pos)
+		}
+		return op.include(n.Values, n.Pos)
+	case Group:
+		if len(n.Nodes) == 1 {
+			return w.walk(n.Nodes[0], negated)
+		} else if n.Any == negated {
+			// Either every node should match, or, by negating alternatives,
+			// none of them may
+			for _, child := range n.Nodes {
+				if err := w.walk(child, negated); err != nil {
+					return err
+				}
+			}
+			return nil
+		} else if negated {
+			return Error{
+				Pos: n.Pos,
+				Msg: "only alternatives can be negated together, negate every term instead"}
+		}
+		return w.alternatives(n)
+	}
+	return Error{Pos: node.Position(), Msg: "unsupported search"}
+}
+
+// Merges alternatives of the same operator, like `tag:go | tag:sql`, into a
+// single group of values
+func (w walker) alternatives(group Group) error {
+	var first *Term
+	var values []string
+	var collect func(node Node) error
+	collect = func(node Node) error {
+		switch n := node.(type) {
+		case Group:
+			if !n.Any && len(n.Nodes) > 1 {
+				return Error{Pos: n.Pos, Msg: "alternatives can't contain terms that should all match"}
+			}
+			for _, child := range n.Nodes {
+				if err := collect(child); err != nil {
+					return err
+				}
+			}
+			return nil
+		case Term:
+			if len(n.Values) > 1 {
+				return Error{Pos: n.Pos, Msg: "alternatives can't contain values separated by `,`"}
+			} else if first != nil && first.Operator != n.Operator {
+				return Error{
+					Pos: n.Pos,
+					Msg: fmt.Sprintf("`%s:` can't be an alternative of `%s:`", n.Operator, first.Operator)}
+			} else if first == nil {
+				first = &n
+			}
+			values = append(values, n.Values[0]...)
+			return nil
+		}
+		return Error{
+			Pos: node.Position(),
+			Msg: "only operators, like `tag:go | tag:sql`, can be alternatives of each other"}
+	}
+	if err := collect(group); err != nil {
+		return err
+	}
+
+	op, err := w.operator(*first)
+	if err != nil {
+		return err
+	}
+	return op.include([][]string{values}, first.Pos)
+}
+
+func (w walker) operator(t Term) (operator, error) {
+	op, ok := w.operators[t.Operator]
+	if !ok {
+		var known []string
+		for name := range w.operators {
+			known = append(known, "`"+name+":`")
+		}
+		slices.Sort(known)
+		return operator{}, Error{
+			Pos: t.Pos,
+			Msg: fmt.Sprintf(
+				"unknown operator `%s:`, expected one of %s", t.Operator, strings.Join(known, ", "))}
+	}
+	return op, nil
+}
+
+// Narrows the window to entries created on or after the given period
+func after(window *persistence.TimeRange) operator {
+	return timeOperator(opAfter, func(p persistence.TimeRange) {
+		*window = intersect(*window, persistence.TimeRange{From: p.From})
+	})
+}
+
+// Narrows the window to entries created on or before the given period
+func before(window *persistence.TimeRange) operator {
+	return timeOperator(opBefore, func(p persistence.TimeRange) {
+		*window = intersect(*window, persistence.TimeRange{Until: p.Until})
+	})
+}
+
+// Narrows the window to entries created within the given period
+func created(window *persistence.TimeRange) operator {
+	return timeOperator(opCreated, func(p persistence.TimeRange) {
+		*window = intersect(*window, p)
+	})
+}
+
+func timeOperator(name string, narrow func(period persistence.TimeRange)) operator {
+	return operator{
+		include: func(values [][]string, pos int) error {
+			if len(values) > 1 || len(values[0]) > 1 {
+				return Error{Pos: pos, Msg: fmt.Sprintf("`%s:` takes a single date", name)}
+			}
+			p, ok := period(values[0][0])
+			if !ok {
+				return Error{
+					Pos: pos,
+					Msg: fmt.Sprintf(
+						"`%s:` expects a date like `2025`, `2025-01` or `2025-01-31`", name)}
+			}
+			narrow(p)
+			return nil
+		},
+		exclude: func(_ []string, pos int) error {
+			return Error{Pos: pos, Msg: fmt.Sprintf("`%s:` can't be negated", name)}
+		}}
+}
+
+// The year, month or day covered by a date, in UTC
+func period(date string) (persistence.TimeRange, bool) {
+	precisions := []struct {
+		layout string
+		years  int
+		months int
+		days   int
+	}{
+		{"2006", 1, 0, 0},
+		{"2006-01", 0, 1, 0},
+		{"2006-01-02", 0, 0, 1}}
+	for _, p := range precisions {
+		if start, err := time.Parse(p.layout, date); err == nil {
+			return persistence.TimeRange{
+				From:  start,
+				Until: start.AddDate(p.years, p.months, p.days)}, true
+		}
+	}
+	return persistence.TimeRange{}, false
+}
+
+// The window covered by both, where a zero bound is open
+func intersect(a, b persistence.TimeRange) persistence.TimeRange {
+	if a.From.IsZero() || b.From.After(a.From) {
+		a.From = b.From
+	}
+	if a.Until.IsZero() || (!b.Until.IsZero() && b.Until.Before(a.Until)) {
+		a.Until = b.Until
+	}
+	return a
+}
+
+// Picks a single sort among `allowed`
+func sortBy(sort *persistence.Sort, allowed ...persistence.Sort) operator {
+	var names []string
+	for name, s := range sorts {
+		if slices.Contains(allowed, s) {
+			names = append(names, "`"+name+"`")
+		}
+	}
+	slices.Sort(names)
+
+	isSet := false
+	return operator{
+		include: func(values [][]string, pos int) error {
+			if isSet {
+				return Error{Pos: pos, Msg: "`sort:` should be given once"}
+			} else if len(values) > 1 || len(values[0]) > 1 {
+				return Error{Pos: pos, Msg: "`sort:` takes a single order"}
+			}
+			s, ok := sorts[values[0][0]]
+			if !ok || !slices.Contains(allowed, s) {
+				return Error{
+					Pos: pos,
+					Msg: fmt.Sprintf(
+						"unknown order `%s`, expected %s",
+						values[0][0], strings.Join(names, ", "))}
+			}
+			*sort = s
+			isSet = true
+			return nil
+		},
+		exclude: func(_ []string, pos int) error {
+			return Error{Pos: pos, Msg: "`sort:` can't be negated, use `sort:-title` to reverse"}
+		}}
+}
+
+func badValues(caller string, err error) error {
+	return oops.BadValues{
+		Msg: err.Error(),
+		Err: fmt.Errorf("%s: %w", caller, err)}
+}
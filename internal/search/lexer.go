@@ -0,0 +1,151 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tOKEN_EOF tokenKind = iota
+	tOKEN_WORD
+	tOKEN_STRING // quoted with `"`
+	tOKEN_COLON
+	tOKEN_PIPE
+	tOKEN_COMMA
+	tOKEN_MINUS
+	tOKEN_LPAREN
+	tOKEN_RPAREN
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tOKEN_EOF:
+		return "end of search"
+	case tOKEN_WORD:
+		return "word"
+	case tOKEN_STRING:
+		return "quoted text"
+	case tOKEN_COLON:
+		return "`:`"
+	case tOKEN_PIPE:
+		return "`|`"
+	case tOKEN_COMMA:
+		return "`,`"
+	case tOKEN_MINUS:
+		return "`-`"
+	case tOKEN_LPAREN:
+		return "`(`"
+	case tOKEN_RPAREN:
+		return "`)`"
+	}
+	return "unknown token"
+}
+
+type token struct {
+	Kind  tokenKind
+	Value string // unquoted content, for words and strings
+	Pos   int    // 1-based column of the first character
+
+	// whether whitespace separates the token from the previous one. Values of
+	// an operator (`tag:a|b`) should be written without any
+	Spaced bool
+}
+
+// Characters that end a word
+const specialChars = `:|,()"`
+
+// Splits the input into tokens, always ending with a `tOKEN_EOF`
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+	spaced := true
+	for idx := 0; idx < len(runes); {
+		r := runes[idx]
+		pos := idx + 1
+		switch {
+		case unicode.IsSpace(r):
+			spaced = true
+			idx++
+			continue
+		case r == ':':
+			tokens = append(tokens, token{Kind: tOKEN_COLON, Pos: pos, Spaced: spaced})
+			idx++
+		case r == '|':
+			tokens = append(tokens, token{Kind: tOKEN_PIPE, Pos: pos, Spaced: spaced})
+			idx++
+		case r == ',':
+			tokens = append(tokens, token{Kind: tOKEN_COMMA, Pos: pos, Spaced: spaced})
+			idx++
+		case r == '(':
+			tokens = append(tokens, token{Kind: tOKEN_LPAREN, Pos: pos, Spaced: spaced})
+			idx++
+		case r == ')':
+			tokens = append(tokens, token{Kind: tOKEN_RPAREN, Pos: pos, Spaced: spaced})
+			idx++
+		case r == '-' && startsTerm(tokens, spaced) && idx+1 < len(runes) && !unicode.IsSpace(runes[idx+1]):
+			tokens = append(tokens, token{Kind: tOKEN_MINUS, Pos: pos, Spaced: spaced})
+			idx++
+		case r == '"':
+			var value strings.Builder
+			idx++
+			closed := false
+			for ; idx < len(runes); idx++ {
+				if runes[idx] == '\\' && idx+1 < len(runes) {
+					idx++
+					value.WriteRune(runes[idx])
+				} else if runes[idx] == '"' {
+					closed = true
+					idx++
+					break
+				} else {
+					value.WriteRune(runes[idx])
+				}
+			}
+			if !closed {
+				return []token{}, Error{Pos: pos, Msg: "quoted text is never closed"}
+			}
+			tokens = append(tokens, token{
+				Kind:   tOKEN_STRING,
+				Value:  value.String(),
+				Pos:    pos,
+				Spaced: spaced})
+		default:
+			start := idx
+			for idx < len(runes) && !unicode.IsSpace(runes[idx]) && !strings.ContainsRune(specialChars, runes[idx]) {
+				idx++
+			}
+			tokens = append(tokens, token{
+				Kind:   tOKEN_WORD,
+				Value:  string(runes[start:idx]),
+				Pos:    pos,
+				Spaced: spaced})
+		}
+		spaced = false
+	}
+	return append(tokens, token{Kind: tOKEN_EOF, Pos: len(runes) + 1, Spaced: true}), nil
+}
+
+// A `-` negates only when it begins a term, so `foo-bar` stays a single word
+func startsTerm(previous []token, spaced bool) bool {
+	if spaced || len(previous) == 0 {
+		return true
+	}
+	switch previous[len(previous)-1].Kind {
+	case tOKEN_LPAREN, tOKEN_PIPE, tOKEN_MINUS:
+		return true
+	}
+	return false
+}
+
+// A malformed search
+type Error struct {
+	Pos int // 1-based column where the problem was found
+	Msg string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s (at character %d)", e.Msg, e.Pos)
+}
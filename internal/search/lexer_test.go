@@ -0,0 +1,98 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []token
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  []token{{Kind: tOKEN_EOF, Pos: 1, Spaced: true}}},
+		{
+			name:  "words",
+			input: "go  sql",
+			want: []token{
+				{Kind: tOKEN_WORD, Value: "go", Pos: 1, Spaced: true},
+				{Kind: tOKEN_WORD, Value: "sql", Pos: 5, Spaced: true},
+				{Kind: tOKEN_EOF, Pos: 8, Spaced: true}}},
+		{
+			name:  "operator",
+			input: "tag:go,sql|pg",
+			want: []token{
+				{Kind: tOKEN_WORD, Value: "tag", Pos: 1, Spaced: true},
+				{Kind: tOKEN_COLON, Pos: 4},
+				{Kind: tOKEN_WORD, Value: "go", Pos: 5},
+				{Kind: tOKEN_COMMA, Pos: 7},
+				{Kind: tOKEN_WORD, Value: "sql", Pos: 8},
+				{Kind: tOKEN_PIPE, Pos: 11},
+				{Kind: tOKEN_WORD, Value: "pg", Pos: 12},
+				{Kind: tOKEN_EOF, Pos: 14, Spaced: true}}},
+		{
+			name:  "quoted text with escapes",
+			input: `"say \"hi\"" x`,
+			want: []token{
+				{Kind: tOKEN_STRING, Value: `say "hi"`, Pos: 1, Spaced: true},
+				{Kind: tOKEN_WORD, Value: "x", Pos: 14, Spaced: true},
+				{Kind: tOKEN_EOF, Pos: 15, Spaced: true}}},
+		{
+			name:  "minus within a word",
+			input: "foo-bar",
+			want: []token{
+				{Kind: tOKEN_WORD, Value: "foo-bar", Pos: 1, Spaced: true},
+				{Kind: tOKEN_EOF, Pos: 8, Spaced: true}}},
+		{
+			name:  "negated group",
+			input: "-(a|b)",
+			want: []token{
+				{Kind: tOKEN_MINUS, Pos: 1, Spaced: true},
+				{Kind: tOKEN_LPAREN, Pos: 2},
+				{Kind: tOKEN_WORD, Value: "a", Pos: 3},
+				{Kind: tOKEN_PIPE, Pos: 4},
+				{Kind: tOKEN_WORD, Value: "b", Pos: 5},
+				{Kind: tOKEN_RPAREN, Pos: 6},
+				{Kind: tOKEN_EOF, Pos: 7, Spaced: true}}},
+		{
+			name:  "lone minus",
+			input: "- a",
+			want: []token{
+				{Kind: tOKEN_WORD, Value: "-", Pos: 1, Spaced: true},
+				{Kind: tOKEN_WORD, Value: "a", Pos: 3, Spaced: true},
+				{Kind: tOKEN_EOF, Pos: 4, Spaced: true}}},
+		{
+			name:  "positions count runes",
+			input: "héllo x",
+			want: []token{
+				{Kind: tOKEN_WORD, Value: "héllo", Pos: 1, Spaced: true},
+				{Kind: tOKEN_WORD, Value: "x", Pos: 7, Spaced: true},
+				{Kind: tOKEN_EOF, Pos: 8, Spaced: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lex(tt.input)
+			if err != nil {
+				t.Fatalf("lex(%q) failed: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lex(%q)\n got: %+v\nwant: %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLexUnclosedQuote(t *testing.T) {
+	_, err := lex(`a "b c`)
+	var searchErr Error
+	if !errors.As(err, &searchErr) {
+		t.Fatalf("expected a search error, got %v", err)
+	} else if searchErr.Pos != 3 {
+		t.Errorf("expected the error at 3, got %d", searchErr.Pos)
+	}
+}
@@ -0,0 +1,239 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A piece of a parsed search
+type Node interface {
+	Position() int // 1-based column where the node begins
+}
+
+// An operator with its values, like `tag:go,sql|pg`. Values separated by `,`
+// start a new group, while those separated by `|` are alternatives within
+// the same group
+type Term struct {
+	Operator string
+	Values   [][]string
+	Pos      int
+}
+
+// A bare word or a quoted phrase
+type Text struct {
+	Value  string
+	Quoted bool
+	Pos    int
+}
+
+// A node prefixed by `-`
+type Not struct {
+	Node Node
+	Pos  int
+}
+
+// Nodes that should all match, or any of them when `Any` is set. The whole
+// search is a group too
+type Group struct {
+	Nodes []Node
+	Any   bool
+	Pos   int
+}
+
+func (n Term) Position() int  { return n.Pos }
+func (n Text) Position() int  { return n.Pos }
+func (n Not) Position() int   { return n.Pos }
+func (n Group) Position() int { return n.Pos }
+
+// Builds the syntax tree of a search, following this grammar:
+//
+//	search  := seq EOF
+//	seq     := item ( ( ' ' | '|' ) item )*   ; either all ' ' or all '|'
+//	item    := '-'? ( '(' seq ')' | operator | WORD | STRING )
+//	operator:= WORD ':' values
+//	values  := value ( ( ',' | '|' ) value )* ; written without spaces
+//	value   := WORD | STRING
+//
+// Words and values are lowercased, and `_` within an unquoted value stands
+// for a space
+func Parse(input string) (Group, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return Group{}, err
+	}
+
+	p := parser{tokens: tokens}
+	root, err := p.seq()
+	if err != nil {
+		return Group{}, err
+	}
+	if t := p.peek(); t.Kind != tOKEN_EOF {
+		return Group{}, Error{Pos: t.Pos, Msg: "`)` doesn't close any `(`"}
+	}
+	return root, nil
+}
+
+type parser struct {
+	tokens []token
+	idx    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.idx]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if idx := p.idx + offset; idx < len(p.tokens) {
+		return p.tokens[idx]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.idx]
+	if t.Kind != tOKEN_EOF {
+		p.idx++
+	}
+	return t
+}
+
+func (p *parser) seq() (Group, error) {
+	group := Group{Pos: p.peek().Pos}
+	hasAll, hasAny := false, false
+	for {
+		t := p.peek()
+		if t.Kind == tOKEN_EOF || t.Kind == tOKEN_RPAREN {
+			break
+		}
+
+		if len(group.Nodes) == 0 && t.Kind == tOKEN_PIPE {
+			return Group{}, Error{Pos: t.Pos, Msg: "`|` should be placed between two terms"}
+		} else if len(group.Nodes) > 0 {
+			if t.Kind == tOKEN_PIPE {
+				p.next()
+				if after := p.peek(); after.Kind == tOKEN_EOF || after.Kind == tOKEN_RPAREN {
+					return Group{}, Error{Pos: t.Pos, Msg: "`|` should be placed between two terms"}
+				}
+				hasAny = true
+			} else {
+				hasAll = true
+			}
+			if hasAll && hasAny {
+				return Group{}, Error{
+					Pos: t.Pos,
+					Msg: "mixing `|` with spaces is ambiguous, wrap the alternatives within parentheses"}
+			}
+		}
+
+		node, err := p.item()
+		if err != nil {
+			return Group{}, err
+		}
+		group.Nodes = append(group.Nodes, node)
+	}
+	group.Any = hasAny
+	return group, nil
+}
+
+func (p *parser) item() (Node, error) {
+	if t := p.peek(); t.Kind == tOKEN_MINUS {
+		p.next()
+		node, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: node, Pos: t.Pos}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (Node, error) {
+	t := p.next()
+	switch t.Kind {
+	case tOKEN_LPAREN:
+		group, err := p.seq()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.Kind != tOKEN_RPAREN {
+			return nil, Error{Pos: t.Pos, Msg: "`(` is never closed"}
+		} else if len(group.Nodes) == 0 {
+			return nil, Error{Pos: t.Pos, Msg: "parentheses should enclose something"}
+		}
+		group.Pos = t.Pos
+		return group, nil
+	case tOKEN_STRING:
+		return Text{Value: strings.ToLower(t.Value), Quoted: true, Pos: t.Pos}, nil
+	case tOKEN_WORD:
+		if colon := p.peek(); colon.Kind == tOKEN_COLON && !colon.Spaced {
+			p.next()
+			values, err := p.values(t)
+			if err != nil {
+				return nil, err
+			}
+			return Term{Operator: strings.ToLower(t.Value), Values: values, Pos: t.Pos}, nil
+		}
+		return Text{Value: strings.ToLower(t.Value), Pos: t.Pos}, nil
+	case tOKEN_COLON:
+		return nil, Error{Pos: t.Pos, Msg: "`:` should follow an operator, like `tag:`"}
+	case tOKEN_COMMA:
+		return nil, Error{Pos: t.Pos, Msg: "`,` only separates the values of an operator"}
+	case tOKEN_RPAREN:
+		return nil, Error{Pos: t.Pos, Msg: "`)` doesn't close any `(`"}
+	}
+	return nil, Error{Pos: t.Pos, Msg: fmt.Sprintf("expected a term, found %s", t.Kind)}
+}
+
+func (p *parser) values(operator token) ([][]string, error) {
+	value := func(separator token) (string, error) {
+		t := p.peek()
+		if t.Spaced || (t.Kind != tOKEN_WORD && t.Kind != tOKEN_STRING) {
+			if separator.Kind == tOKEN_COLON {
+				return "", Error{
+					Pos: operator.Pos,
+					Msg: fmt.Sprintf("`%s:` is missing a value", operator.Value)}
+			}
+			return "", Error{
+				Pos: separator.Pos,
+				Msg: fmt.Sprintf("expected a value after %s", separator.Kind)}
+		}
+		p.next()
+		if t.Kind == tOKEN_STRING {
+			return strings.ToLower(t.Value), nil
+		}
+		return strings.ToLower(strings.ReplaceAll(t.Value, "_", " ")), nil
+	}
+
+	first, err := value(p.tokens[p.idx-1])
+	if err != nil {
+		return [][]string{}, err
+	}
+	values := [][]string{{first}}
+	for {
+		separator := p.peek()
+		if separator.Spaced || (separator.Kind != tOKEN_COMMA && separator.Kind != tOKEN_PIPE) {
+			break
+		}
+		// `tag:a|serie:b` combines two operators rather than listing values
+		if separator.Kind == tOKEN_PIPE &&
+			p.peekAt(1).Kind == tOKEN_WORD &&
+			p.peekAt(2).Kind == tOKEN_COLON && !p.peekAt(2).Spaced {
+			break
+		}
+
+		p.next()
+		v, err := value(separator)
+		if err != nil {
+			return [][]string{}, err
+		}
+		if separator.Kind == tOKEN_COMMA {
+			values = append(values, []string{v})
+		} else {
+			values[len(values)-1] = append(values[len(values)-1], v)
+		}
+	}
+	if t := p.peek(); t.Kind == tOKEN_COLON && !t.Spaced {
+		return [][]string{}, Error{Pos: t.Pos, Msg: "operators can't be nested within each other"}
+	}
+	return values, nil
+}
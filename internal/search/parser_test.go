@@ -0,0 +1,103 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Group
+	}{
+		{
+			input: "",
+			want:  Group{Pos: 1}},
+		{
+			input: "Go SQL",
+			want: Group{Pos: 1, Nodes: []Node{
+				Text{Value: "go", Pos: 1},
+				Text{Value: "sql", Pos: 4}}}},
+		{
+			input: "a|b",
+			want: Group{Pos: 1, Any: true, Nodes: []Node{
+				Text{Value: "a", Pos: 1},
+				Text{Value: "b", Pos: 3}}}},
+		{
+			input: "tag:Go,sql|pg",
+			want: Group{Pos: 1, Nodes: []Node{
+				Term{Operator: "tag", Values: [][]string{{"go"}, {"sql", "pg"}}, Pos: 1}}}},
+		{
+			input: "tag:a|serie:b",
+			want: Group{Pos: 1, Any: true, Nodes: []Node{
+				Term{Operator: "tag", Values: [][]string{{"a"}}, Pos: 1},
+				Term{Operator: "serie", Values: [][]string{{"b"}}, Pos: 7}}}},
+		{
+			input: `serie:my_serie tag:"c_sharp"`,
+			want: Group{Pos: 1, Nodes: []Node{
+				Term{Operator: "serie", Values: [][]string{{"my serie"}}, Pos: 1},
+				Term{Operator: "tag", Values: [][]string{{"c_sharp"}}, Pos: 16}}}},
+		{
+			input: `-tag:x "Hello World"`,
+			want: Group{Pos: 1, Nodes: []Node{
+				Not{Node: Term{Operator: "tag", Values: [][]string{{"x"}}, Pos: 2}, Pos: 1},
+				Text{Value: "hello world", Quoted: true, Pos: 8}}}},
+		{
+			input: "(a|b) -(c d)",
+			want: Group{Pos: 1, Nodes: []Node{
+				Group{Pos: 1, Any: true, Nodes: []Node{
+					Text{Value: "a", Pos: 2},
+					Text{Value: "b", Pos: 4}}},
+				Not{Pos: 7, Node: Group{Pos: 8, Nodes: []Node{
+					Text{Value: "c", Pos: 9},
+					Text{Value: "d", Pos: 11}}}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q)\n got: %+v\nwant: %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		msg   string // part of the message
+	}{
+		{"|a", 1, "between two terms"},
+		{"a|", 2, "between two terms"},
+		{"a b|c", 4, "ambiguous"},
+		{"(a", 1, "never closed"},
+		{"a)", 2, "doesn't close"},
+		{"()", 1, "enclose something"},
+		{"tag:", 1, "missing a value"},
+		{"tag: x", 1, "missing a value"},
+		{"tag:a,", 6, "after `,`"},
+		{"tag:a:b", 6, "nested"},
+		{":a", 1, "should follow an operator"},
+		{",a", 1, "only separates"},
+		{`"a`, 1, "never closed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var searchErr Error
+			if !errors.As(err, &searchErr) {
+				t.Fatalf("Parse(%q): expected a search error, got %v", tt.input, err)
+			}
+			if searchErr.Pos != tt.pos || !strings.Contains(searchErr.Msg, tt.msg) {
+				t.Errorf("Parse(%q): got %q at %d, want %q at %d",
+					tt.input, searchErr.Msg, searchErr.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
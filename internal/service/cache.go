@@ -0,0 +1,51 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// Keeps computed values around for a while. Content is written by a separate
+// process (see `cmd/crud`), so values are never invalidated but only expire
+type cache[K comparable, V any] struct {
+	mu      *sync.Mutex
+	ttl     time.Duration
+	entries map[K]cacheEntry[V]
+}
+
+type cacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+func newCache[K comparable, V any](ttl time.Duration) *cache[K, V] {
+	return &cache[K, V]{
+		mu:      &sync.Mutex{},
+		ttl:     ttl,
+		entries: map[K]cacheEntry[V]{}}
+}
+
+func (c *cache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *cache[K, V]) put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries { // drops the expired ones along the way
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
@@ -1,212 +1,387 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"os"
+	"strings"
 
 	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/markdown"
 )
 
-func (s Service) InsertArticles(articles []entity.WriteArticle) error {
-	contents := make([]string, len(articles))
+func (s Service) InsertArticles(ctx context.Context, articles []entity.WriteArticle) ([]entity.WriteArticle, error) {
 	for idx, a := range articles {
-		f, err := os.Open(a.Content)
+		state, err := publication(a.State, a.PublishAt)
 		if err != nil {
-			return fmt.Errorf("service<Service.InsertArticles>: %w", err)
+			return nil, fmt.Errorf("service<Service.InsertArticles>: %w", err)
+		} else if err := checkSlug(a.Slug); err != nil {
+			return nil, fmt.Errorf("service<Service.InsertArticles>: %w", err)
 		}
+		articles[idx].State = state
+	}
 
-		content, err := io.ReadAll(f)
+	contents := make([]string, len(articles))
+	for idx, a := range articles {
+		content, meta, err := readContent(a.Content)
 		if err != nil {
-			return fmt.Errorf("service<Service.InsertArticles>: %w", err)
+			return nil, fmt.Errorf("service<Service.InsertArticles>: %w", err)
 		}
-		contents[idx] = string(content)
+		articles[idx] = describeArticle(a, meta)
+		contents[idx] = content
 	}
 
-	if err := s.store.InsertArticles(articles, contents); err != nil {
-		return fmt.Errorf("service<Service.InsertArticles>: %w", err)
+	ids, err := s.store.InsertArticles(ctx, articles, contents)
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.InsertArticles>: %w", err)
 	}
-	return nil
+	for idx, id := range ids {
+		articles[idx].Id = id
+	}
+	return articles, nil
 }
 
-func (s Service) UpsertArticles(articles []entity.WriteArticle) error {
-	contents := make([]string, len(articles))
+func (s Service) UpsertArticles(ctx context.Context, articles []entity.WriteArticle) error {
 	for idx, a := range articles {
-		f, err := os.Open(a.Content)
+		if err := checkSlug(a.Slug); err != nil {
+			return fmt.Errorf("service<Service.UpsertArticles>: %w", err)
+		}
+
+		// Leaving out both the state and the time keeps them as they are
+		if a.State == "" && a.PublishAt.IsZero() {
+			continue
+		}
+		state, err := publication(a.State, a.PublishAt)
 		if err != nil {
 			return fmt.Errorf("service<Service.UpsertArticles>: %w", err)
 		}
+		articles[idx].State = state
+	}
 
-		content, err := io.ReadAll(f)
+	contents := make([]string, len(articles))
+	for idx, a := range articles {
+		content, meta, err := readContent(a.Content)
 		if err != nil {
 			return fmt.Errorf("service<Service.UpsertArticles>: %w", err)
 		}
-		contents[idx] = string(content)
+		articles[idx] = describeArticle(a, meta)
+		contents[idx] = content
 	}
 
-	if err := s.store.UpsertArticles(articles, contents); err != nil {
+	if err := s.store.UpsertArticles(ctx, articles, contents); err != nil {
 		return fmt.Errorf("service<Service.UpsertArticles>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) DeleteArticles(articles []entity.DeleteById) error {
-	if err := s.store.DeleteArticles(articles); err != nil {
+func (s Service) DeleteArticles(ctx context.Context, articles []entity.DeleteById) error {
+	if err := s.store.DeleteArticles(ctx, articles); err != nil {
 		return fmt.Errorf("service<Service.DeleteArticles>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) InsertArticleTags(articleTags []entity.WriteArticleTag) error {
-	if err := s.store.InsertArticlesTags(articleTags); err != nil {
-		return fmt.Errorf("service<Service.InsertArticleTags>: %w", err)
+func (s Service) InsertArticleTags(ctx context.Context, articleTags []entity.WriteArticleTag) ([]entity.WriteArticleTag, error) {
+	ids, err := s.store.InsertArticlesTags(ctx, articleTags)
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.InsertArticleTags>: %w", err)
 	}
-	return nil
+	for idx, id := range ids {
+		articleTags[idx].Id = id
+	}
+	return articleTags, nil
 }
 
-func (s Service) UpsertArticleTags(articleTags []entity.WriteArticleTag) error {
-	if err := s.store.UpsertArticleTags(articleTags); err != nil {
+func (s Service) UpsertArticleTags(ctx context.Context, articleTags []entity.WriteArticleTag) error {
+	if err := s.store.UpsertArticleTags(ctx, articleTags); err != nil {
 		return fmt.Errorf("service<Service.UpsertArticleTags>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) DeleteArticleTags(articleTags []entity.DeleteById) error {
-	if err := s.store.DeleteArticleTags(articleTags); err != nil {
+func (s Service) DeleteArticleTags(ctx context.Context, articleTags []entity.DeleteById) error {
+	if err := s.store.DeleteArticleTags(ctx, articleTags); err != nil {
 		return fmt.Errorf("service<Service.DeleteArticleTags>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) InsertProjects(projects []entity.WriteProject) error {
-	contents := make([]string, len(projects))
-	for idx, a := range projects {
-		f, err := os.Open(a.Description)
+func (s Service) InsertProjects(ctx context.Context, projects []entity.WriteProject) ([]entity.WriteProject, error) {
+	for idx, p := range projects {
+		state, err := publication(p.State, p.PublishAt)
 		if err != nil {
-			return fmt.Errorf("service<Service.InsertProjects>: %w", err)
+			return nil, fmt.Errorf("service<Service.InsertProjects>: %w", err)
+		} else if err := checkSlug(p.Slug); err != nil {
+			return nil, fmt.Errorf("service<Service.InsertProjects>: %w", err)
 		}
+		projects[idx].State = state
+	}
 
-		content, err := io.ReadAll(f)
+	contents := make([]string, len(projects))
+	for idx, a := range projects {
+		content, meta, err := readContent(a.Description)
 		if err != nil {
-			return fmt.Errorf("service<Service.InsertProjects>: %w", err)
+			return nil, fmt.Errorf("service<Service.InsertProjects>: %w", err)
 		}
-		contents[idx] = string(content)
+		projects[idx] = describeProject(a, meta)
+		contents[idx] = content
 	}
 
-	if err := s.store.InsertProjects(projects, contents); err != nil {
-		return fmt.Errorf("service<Service.InsertProjects>: %w", err)
+	ids, err := s.store.InsertProjects(ctx, projects, contents)
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.InsertProjects>: %w", err)
 	}
-	return nil
+	for idx, id := range ids {
+		projects[idx].Id = id
+	}
+	return projects, nil
 }
 
-func (s Service) UpsertProjects(projects []entity.WriteProject) error {
-	contents := make([]string, len(projects))
-	for idx, a := range projects {
-		f, err := os.Open(a.Description)
+func (s Service) UpsertProjects(ctx context.Context, projects []entity.WriteProject) error {
+	for idx, p := range projects {
+		if err := checkSlug(p.Slug); err != nil {
+			return fmt.Errorf("service<Service.UpsertProjects>: %w", err)
+		}
+
+		// Leaving out both the state and the time keeps them as they are
+		if p.State == "" && p.PublishAt.IsZero() {
+			continue
+		}
+		state, err := publication(p.State, p.PublishAt)
 		if err != nil {
-			return fmt.Errorf("service<Service.UpsertArticles>: %w", err)
+			return fmt.Errorf("service<Service.UpsertProjects>: %w", err)
 		}
+		projects[idx].State = state
+	}
 
-		content, err := io.ReadAll(f)
+	contents := make([]string, len(projects))
+	for idx, a := range projects {
+		content, meta, err := readContent(a.Description)
 		if err != nil {
-			return fmt.Errorf("service<Service.UpsertArticles>: %w", err)
+			return fmt.Errorf("service<Service.UpsertProjects>: %w", err)
 		}
-		contents[idx] = string(content)
+		projects[idx] = describeProject(a, meta)
+		contents[idx] = content
 	}
 
-	if err := s.store.UpsertProjects(projects, contents); err != nil {
+	if err := s.store.UpsertProjects(ctx, projects, contents); err != nil {
 		return fmt.Errorf("service<Service.UpsertProjects>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) DeleteProjects(projects []entity.DeleteById) error {
-	if err := s.store.DeleteProjects(projects); err != nil {
+func (s Service) DeleteProjects(ctx context.Context, projects []entity.DeleteById) error {
+	if err := s.store.DeleteProjects(ctx, projects); err != nil {
 		return fmt.Errorf("service<Service.DeleteProjects>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) InsertProjectTags(projectTags []entity.WriteProjectTag) error {
-	if err := s.store.InsertProjectTags(projectTags); err != nil {
-		return fmt.Errorf("service<Service.InsertProjectTags>: %w", err)
+func (s Service) InsertProjectTags(ctx context.Context, projectTags []entity.WriteProjectTag) ([]entity.WriteProjectTag, error) {
+	ids, err := s.store.InsertProjectTags(ctx, projectTags)
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.InsertProjectTags>: %w", err)
 	}
-	return nil
+	for idx, id := range ids {
+		projectTags[idx].Id = id
+	}
+	return projectTags, nil
 }
 
-func (s Service) UpsertProjectTags(projectTags []entity.WriteProjectTag) error {
-	if err := s.store.UpsertProjectTags(projectTags); err != nil {
+func (s Service) UpsertProjectTags(ctx context.Context, projectTags []entity.WriteProjectTag) error {
+	if err := s.store.UpsertProjectTags(ctx, projectTags); err != nil {
 		return fmt.Errorf("service<Service.UpsertProjectTags>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) DeleteProjectTags(projectTags []entity.DeleteById) error {
-	if err := s.store.DeleteProjectTags(projectTags); err != nil {
+func (s Service) DeleteProjectTags(ctx context.Context, projectTags []entity.DeleteById) error {
+	if err := s.store.DeleteProjectTags(ctx, projectTags); err != nil {
 		return fmt.Errorf("service<Service.DeleteProjectTags>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) InsertProjectLinks(projectLinks []entity.WriteProjectLink) error {
-	if err := s.store.InsertProjectLinks(projectLinks); err != nil {
-		return fmt.Errorf("service<Service.InsertProjectLinks>: %w", err)
+func (s Service) InsertProjectLinks(ctx context.Context, projectLinks []entity.WriteProjectLink) ([]entity.WriteProjectLink, error) {
+	ids, err := s.store.InsertProjectLinks(ctx, projectLinks)
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.InsertProjectLinks>: %w", err)
 	}
-	return nil
+	for idx, id := range ids {
+		projectLinks[idx].Id = id
+	}
+	return projectLinks, nil
 }
 
-func (s Service) UpsertProjectLinks(projectLinks []entity.WriteProjectLink) error {
-	if err := s.store.UpsertProjectLinks(projectLinks); err != nil {
+func (s Service) UpsertProjectLinks(ctx context.Context, projectLinks []entity.WriteProjectLink) error {
+	if err := s.store.UpsertProjectLinks(ctx, projectLinks); err != nil {
 		return fmt.Errorf("service<Service.UpsertProjectLinks>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) DeleteProjectLinks(projectLinks []entity.DeleteById) error {
-	if err := s.store.DeleteProjectLinks(projectLinks); err != nil {
+func (s Service) DeleteProjectLinks(ctx context.Context, projectLinks []entity.DeleteById) error {
+	if err := s.store.DeleteProjectLinks(ctx, projectLinks); err != nil {
 		return fmt.Errorf("service<Service.DeleteProjectLinks>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) InsertTags(tags []entity.WriteTag) error {
-	if err := s.store.InsertTags(tags); err != nil {
-		return fmt.Errorf("service<Service.UpsertTags>: %w", err)
+func (s Service) InsertTags(ctx context.Context, tags []entity.WriteTag) ([]entity.WriteTag, error) {
+	ids, err := s.store.InsertTags(ctx, tags)
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.InsertTags>: %w", err)
 	}
-	return nil
+	for idx, id := range ids {
+		tags[idx].Id = id
+	}
+	return tags, nil
 }
 
-func (s Service) UpsertTags(tags []entity.WriteTag) error {
-	if err := s.store.UpsertTags(tags); err != nil {
+func (s Service) UpsertTags(ctx context.Context, tags []entity.WriteTag) error {
+	if err := s.store.UpsertTags(ctx, tags); err != nil {
 		return fmt.Errorf("service<Service.UpsertTags>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) DeleteTags(tags []entity.DeleteById) error {
-	if err := s.store.DeleteTags(tags); err != nil {
+func (s Service) DeleteTags(ctx context.Context, tags []entity.DeleteById) error {
+	if err := s.store.DeleteTags(ctx, tags); err != nil {
 		return fmt.Errorf("service<Service.DeleteTags>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) InsertSeries(series []entity.WriteSerie) error {
-	if err := s.store.InsertSeries(series); err != nil {
-		return fmt.Errorf("service<Service.InsertSeries>: %w", err)
+func (s Service) InsertSeries(ctx context.Context, series []entity.WriteSerie) ([]entity.WriteSerie, error) {
+	for idx, sr := range series {
+		state, err := publication(sr.State, sr.PublishAt)
+		if err != nil {
+			return nil, fmt.Errorf("service<Service.InsertSeries>: %w", err)
+		} else if err := checkSlug(sr.Slug); err != nil {
+			return nil, fmt.Errorf("service<Service.InsertSeries>: %w", err)
+		}
+		series[idx].State = state
 	}
-	return nil
+
+	ids, err := s.store.InsertSeries(ctx, series)
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.InsertSeries>: %w", err)
+	}
+	for idx, id := range ids {
+		series[idx].Id = id
+	}
+	return series, nil
 }
 
-func (s Service) UpsertSeries(series []entity.WriteSerie) error {
-	if err := s.store.UpsertSeries(series); err != nil {
+func (s Service) UpsertSeries(ctx context.Context, series []entity.WriteSerie) error {
+	for idx, sr := range series {
+		if err := checkSlug(sr.Slug); err != nil {
+			return fmt.Errorf("service<Service.UpsertSeries>: %w", err)
+		}
+
+		// Leaving out both the state and the time keeps them as they are
+		if sr.State == "" && sr.PublishAt.IsZero() {
+			continue
+		}
+		state, err := publication(sr.State, sr.PublishAt)
+		if err != nil {
+			return fmt.Errorf("service<Service.UpsertSeries>: %w", err)
+		}
+		series[idx].State = state
+	}
+
+	if err := s.store.UpsertSeries(ctx, series); err != nil {
 		return fmt.Errorf("service<Service.UpsertSeries>: %w", err)
 	}
 	return nil
 }
 
-func (s Service) DeleteSeries(series []entity.DeleteById) error {
-	if err := s.store.DeleteSeries(series); err != nil {
+func (s Service) DeleteSeries(ctx context.Context, series []entity.DeleteById) error {
+	if err := s.store.DeleteSeries(ctx, series); err != nil {
 		return fmt.Errorf("service<Service.DeleteSeries>: %w", err)
 	}
 	return nil
 }
+
+// Everything written through `cmd/crud`, as it's currently stored
+func (s Service) Snapshot(ctx context.Context) (entity.Snapshot, error) {
+	snapshot, err := s.store.Snapshot(ctx)
+	if err != nil {
+		return snapshot, fmt.Errorf("service<Service.Snapshot>: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Reads the content of an entry from `path`. Markdown is converted into HTML,
+// along with the front matter describing the entry, while anything else is
+// taken as HTML already
+func readContent(path string) (string, markdown.FrontMatter, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return "", markdown.FrontMatter{}, err
+	}
+
+	if !markdown.IsFile(path) {
+		return string(source), markdown.FrontMatter{}, nil
+	}
+	meta, content, err := markdown.Convert(source)
+	if err != nil {
+		return "", meta, fmt.Errorf("%s: %w", path, err)
+	}
+	return content, meta, nil
+}
+
+// Fills what `a` leaves out with its front matter, after its flat serie
+func describeArticle(a entity.WriteArticle, meta markdown.FrontMatter) entity.WriteArticle {
+	if a.Serie == nil && a.SerieId != nil {
+		a.Serie = &entity.WriteSeriePart{Id: *a.SerieId, Order: a.SerieOrder}
+	}
+	if a.Title == "" {
+		a.Title = meta.Title
+	}
+	if a.Subtitle == "" {
+		a.Subtitle = meta.Subtitle
+	}
+	if a.Serie == nil && meta.Serie != "" {
+		a.Serie = &entity.WriteSeriePart{Slug: meta.Serie, Order: meta.Order}
+	}
+	if a.Tags == nil {
+		a.Tags = meta.Tags
+	}
+	a.Tags = tagNames(a.Tags)
+	return a
+}
+
+// Like `describeArticle`, where the title and subtitle name the project and
+// its synopsis
+func describeProject(p entity.WriteProject, meta markdown.FrontMatter) entity.WriteProject {
+	if p.Name == "" {
+		p.Name = meta.Title
+	}
+	if p.Synopsis == "" {
+		p.Synopsis = meta.Subtitle
+	}
+	if p.Serie == nil && meta.Serie != "" {
+		p.Serie = &entity.WriteSeriePart{Slug: meta.Serie}
+	}
+	if p.Tags == nil {
+		p.Tags = meta.Tags
+	}
+	p.Tags = tagNames(p.Tags)
+	return p
+}
+
+// Trims the names of tags, dropping the blank ones. No tags at all stays nil,
+// as it leaves the tags of the entry as they are
+func tagNames(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	trimmed := []string{}
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			trimmed = append(trimmed, n)
+		}
+	}
+	return trimmed
+}
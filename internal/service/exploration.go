@@ -1,15 +1,17 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/solsteace/misite/internal/entity"
 	"github.com/solsteace/misite/internal/persistence"
 	"github.com/solsteace/misite/internal/utility/lib/oops"
+	"golang.org/x/sync/errgroup"
 )
 
-func (s Service) Projects(param persistence.ProjectsQueryParam) ([]entity.ProjectListPage, error) {
-	projects, err := s.store.Projects(param)
+func (s Service) Projects(ctx context.Context, param persistence.ProjectsQueryParam) ([]entity.ProjectListPage, error) {
+	projects, err := s.store.Projects(ctx, param)
 	if err != nil {
 		return []entity.ProjectListPage{}, fmt.Errorf(
 			"service<Service.Projects>: %w", err)
@@ -17,8 +19,8 @@ func (s Service) Projects(param persistence.ProjectsQueryParam) ([]entity.Projec
 	return projects, nil
 }
 
-func (s Service) Articles(param persistence.ArticlesQueryParam) ([]entity.ArticleListPage, error) {
-	articles, err := s.store.Articles(param)
+func (s Service) Articles(ctx context.Context, param persistence.ArticlesQueryParam) ([]entity.ArticleListPage, error) {
+	articles, err := s.store.Articles(ctx, param)
 	if err != nil {
 		return []entity.ArticleListPage{}, fmt.Errorf(
 			"service<Service.Articles>: %w", err)
@@ -26,8 +28,8 @@ func (s Service) Articles(param persistence.ArticlesQueryParam) ([]entity.Articl
 	return articles, nil
 }
 
-func (s Service) SerieList(param persistence.SerieListQueryParam) ([]entity.SerieListPage, error) {
-	serieList, err := s.store.SerieList(param)
+func (s Service) SerieList(ctx context.Context, param persistence.SerieListQueryParam) ([]entity.SerieListPage, error) {
+	serieList, err := s.store.SerieList(ctx, param)
 	if err != nil {
 		return []entity.SerieListPage{}, fmt.Errorf(
 			"service<Service.SerieList>: %w", err)
@@ -35,11 +37,72 @@ func (s Service) SerieList(param persistence.SerieListQueryParam) ([]entity.Seri
 	return serieList, nil
 }
 
+// Previews every kind of entry matching the search, alongside how many of
+// them matched. Every kind is looked up concurrently, where the first
+// failure cancels the others
+func (s Service) Search(ctx context.Context, param persistence.SearchQueryParam) (entity.SearchPage, error) {
+	var result entity.SearchPage
+	group, ctx := errgroup.WithContext(ctx)
+	if f := param.Articles; f != nil {
+		group.Go(func() (err error) {
+			result.Articles, err = s.store.Articles(ctx, persistence.ArticlesQueryParam{
+				Limit:                 param.Limit,
+				ExplorationQueryParam: *f})
+			return err
+		})
+		group.Go(func() (err error) {
+			result.NArticle, err = s.store.CountArticles(ctx, *f)
+			return err
+		})
+	}
+	if f := param.Projects; f != nil {
+		group.Go(func() (err error) {
+			result.Projects, err = s.store.Projects(ctx, persistence.ProjectsQueryParam{
+				Limit:                 param.Limit,
+				ExplorationQueryParam: *f})
+			return err
+		})
+		group.Go(func() (err error) {
+			result.NProject, err = s.store.CountProjects(ctx, *f)
+			return err
+		})
+	}
+	if f := param.Series; f != nil {
+		group.Go(func() (err error) {
+			result.Series, err = s.store.SerieList(ctx, persistence.SerieListQueryParam{
+				Limit:                      param.Limit,
+				SerieExplorationQueryParam: *f})
+			return err
+		})
+		group.Go(func() (err error) {
+			result.NSerie, err = s.store.CountSeries(ctx, *f)
+			return err
+		})
+	}
+	if f := param.Tags; f != nil {
+		group.Go(func() (err error) {
+			result.Tags, err = s.store.TagList(ctx, persistence.TagListQueryParam{
+				Limit:                    param.Limit,
+				TagExplorationQueryParam: *f})
+			return err
+		})
+		group.Go(func() (err error) {
+			result.NTag, err = s.store.CountTags(ctx, *f)
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return entity.SearchPage{}, fmt.Errorf("service<Service.Search>: %w", err)
+	}
+	return result, nil
+}
+
 func (s Service) SerieArticleList(
+	ctx context.Context,
 	id int,
 	param persistence.SerieContentQueryParam,
 ) ([]entity.SeriePageArticleList, error) {
-	serieArticles, err := s.store.SerieArticleList(id, param)
+	serieArticles, err := s.store.SerieArticleList(ctx, id, param)
 	if err != nil {
 		return []entity.SeriePageArticleList{}, fmt.Errorf(
 			"service<Service.SerieArticles>: %w", err)
@@ -48,10 +111,11 @@ func (s Service) SerieArticleList(
 }
 
 func (s Service) SerieProjectList(
+	ctx context.Context,
 	id int,
 	param persistence.SerieContentQueryParam,
 ) ([]entity.SeriePageProjectList, error) {
-	serieProjects, err := s.store.SerieProjectList(id, param)
+	serieProjects, err := s.store.SerieProjectList(ctx, id, param)
 	if err != nil {
 		return []entity.SeriePageProjectList{}, fmt.Errorf(
 			"service<Service.SerieProjects>: %w", err)
@@ -59,15 +123,15 @@ func (s Service) SerieProjectList(
 	return serieProjects, nil
 }
 
-func (s Service) Tags(by string, param persistence.TagQueryParams) ([]entity.TagStatPage, error) {
+func (s Service) Tags(ctx context.Context, by string, param persistence.TagQueryParams) ([]entity.TagStatPage, error) {
 	var tagStats []entity.TagStatPage
 	var err error
 
 	switch by {
 	case "article":
-		tagStats, err = s.store.ArticleTags(param)
+		tagStats, err = s.store.ArticleTags(ctx, param)
 	case "project":
-		tagStats, err = s.store.ProjectTags(param)
+		tagStats, err = s.store.ProjectTags(ctx, param)
 	default:
 		return []entity.TagStatPage{}, oops.NotFound{
 			Err: fmt.Errorf("`by` should be either `article` or `project`")}
@@ -79,3 +143,20 @@ func (s Service) Tags(by string, param persistence.TagQueryParams) ([]entity.Tag
 
 	return tagStats, nil
 }
+
+// Finds the tag named `name`, regardless of its case
+func (s Service) Tag(ctx context.Context, name string) (entity.TagListPage, error) {
+	tag, err := s.store.TagByName(ctx, name)
+	if err != nil {
+		return entity.TagListPage{}, fmt.Errorf("service<Service.Tag>: %w", err)
+	}
+	return tag, nil
+}
+
+func (s Service) Sitemap(ctx context.Context) ([]entity.SitemapEntry, error) {
+	entries, err := s.store.Sitemap(ctx)
+	if err != nil {
+		return []entity.SitemapEntry{}, fmt.Errorf("service<Service.Sitemap>: %w", err)
+	}
+	return entries, nil
+}
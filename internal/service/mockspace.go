@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -11,7 +12,7 @@ import (
 	"github.com/solsteace/misite/internal/utility/lib/oops"
 )
 
-func (s Service) ArticleWritespace() (entity.ArticlePage, error) {
+func (s Service) ArticleWritespace(ctx context.Context) (entity.ArticlePage, error) {
 	f, err := os.Open("./static/testarticle.html")
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
@@ -31,7 +32,7 @@ func (s Service) ArticleWritespace() (entity.ArticlePage, error) {
 		Content:  string(content)}, nil
 }
 
-func (s Service) ProjectWritespace() (entity.ProjectPage, error) {
+func (s Service) ProjectWritespace(ctx context.Context) (entity.ProjectPage, error) {
 	f, err := os.Open("./static/testproject.html")
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
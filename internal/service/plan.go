@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+)
+
+// Runs `fx` as a single write: either every write made within it is kept, or
+// none is. On `dryRun` none is kept either way, and the plan of what they
+// would have done is given instead
+func (s Service) Atomic(
+	ctx context.Context,
+	dryRun bool,
+	fx func(ctx context.Context) error,
+) ([]entity.Change, error) {
+	var plan []entity.Change
+	err := s.store.Atomic(ctx, !dryRun, func(ctx context.Context) error {
+		var before entity.Snapshot
+		if dryRun {
+			var err error
+			if before, err = s.store.Snapshot(ctx); err != nil {
+				return err
+			}
+		}
+		if err := fx(ctx); err != nil {
+			return err
+		}
+		if !dryRun {
+			return nil
+		}
+
+		after, err := s.store.Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+		plan = planChanges(before, after)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.Atomic>: %w", err)
+	}
+	return plan, nil
+}
+
+// What turned `before` into `after`, rows being matched by their id. Rows
+// removed along with another one, like the tags of a deleted article, show
+// up as deleted too
+func planChanges(before, after entity.Snapshot) []entity.Change {
+	var plan []entity.Change
+	ref := snapshotRefs{before: before, after: after}
+
+	plan = append(plan, diffRows(before.Series, after.Series,
+		func(sr entity.WriteSerie) int { return sr.Id },
+		func(sr entity.WriteSerie) string { return sr.Slug },
+		"serie", serieChanges)...)
+	plan = append(plan, diffRows(before.Articles, after.Articles,
+		func(a entity.WriteArticle) int { return a.Id },
+		func(a entity.WriteArticle) string { return a.Slug },
+		"article", articleChanges)...)
+	plan = append(plan, diffRows(before.Projects, after.Projects,
+		func(p entity.WriteProject) int { return p.Id },
+		func(p entity.WriteProject) string { return p.Slug },
+		"project", projectChanges)...)
+	plan = append(plan, diffRows(before.Tags, after.Tags,
+		func(t entity.WriteTag) int { return t.Id },
+		func(t entity.WriteTag) string { return t.Name },
+		"tag", func(before, after entity.WriteTag) []entity.FieldChange {
+			var fields fieldChanges
+			fields.compare("name", before.Name, after.Name)
+			return fields
+		})...)
+	plan = append(plan, diffRows(before.ArticleTags, after.ArticleTags,
+		func(at entity.WriteArticleTag) int { return at.Id },
+		func(at entity.WriteArticleTag) string {
+			return ref.article(at.ArticleId) + " " + ref.tag(at.TagId)
+		},
+		"article_tag", func(before, after entity.WriteArticleTag) []entity.FieldChange {
+			var fields fieldChanges
+			fields.compare("article", ref.article(before.ArticleId), ref.article(after.ArticleId))
+			fields.compare("tag", ref.tag(before.TagId), ref.tag(after.TagId))
+			return fields
+		})...)
+	plan = append(plan, diffRows(before.ProjectTags, after.ProjectTags,
+		func(pt entity.WriteProjectTag) int { return pt.Id },
+		func(pt entity.WriteProjectTag) string {
+			return ref.project(pt.ProjectId) + " " + ref.tag(pt.TagId)
+		},
+		"project_tag", func(before, after entity.WriteProjectTag) []entity.FieldChange {
+			var fields fieldChanges
+			fields.compare("project", ref.project(before.ProjectId), ref.project(after.ProjectId))
+			fields.compare("tag", ref.tag(before.TagId), ref.tag(after.TagId))
+			return fields
+		})...)
+	plan = append(plan, diffRows(before.ProjectLinks, after.ProjectLinks,
+		func(l entity.WriteProjectLink) int { return l.Id },
+		func(l entity.WriteProjectLink) string { return ref.project(l.ProjectId) + " " + l.Url },
+		"project_link", func(before, after entity.WriteProjectLink) []entity.FieldChange {
+			var fields fieldChanges
+			fields.compare("project", ref.project(before.ProjectId), ref.project(after.ProjectId))
+			return append(fields, linkChanges(before, after)...)
+		})...)
+	return plan
+}
+
+// Inserted rows come first, then the updated ones and lastly the deleted ones
+func diffRows[T any](
+	before, after []T,
+	id func(T) int,
+	ref func(T) string,
+	kind string,
+	changes func(before, after T) []entity.FieldChange,
+) []entity.Change {
+	previous := map[int]T{}
+	for _, row := range before {
+		previous[id(row)] = row
+	}
+
+	var inserted, updated, deleted []entity.Change
+	for _, row := range after {
+		old, ok := previous[id(row)]
+		delete(previous, id(row))
+		if !ok {
+			inserted = append(inserted, entity.Change{
+				Entity: kind,
+				Ref:    ref(row),
+				Action: entity.ChangeInsert})
+		} else if fields := changes(old, row); len(fields) > 0 {
+			updated = append(updated, entity.Change{
+				Entity: kind,
+				Ref:    ref(row),
+				Action: entity.ChangeUpdate,
+				Fields: fields})
+		}
+	}
+	for _, row := range before {
+		if _, ok := previous[id(row)]; ok {
+			deleted = append(deleted, entity.Change{
+				Entity: kind,
+				Ref:    ref(row),
+				Action: entity.ChangeDelete})
+		}
+	}
+	return slices.Concat(inserted, updated, deleted)
+}
+
+// Names rows of a snapshot by their id, looking into the state after the
+// changes first, as deleted rows are only found before them
+type snapshotRefs struct {
+	before entity.Snapshot
+	after  entity.Snapshot
+}
+
+func (r snapshotRefs) article(id int) string {
+	for _, articles := range [][]entity.WriteArticle{r.after.Articles, r.before.Articles} {
+		if idx := slices.IndexFunc(articles, func(a entity.WriteArticle) bool { return a.Id == id }); idx >= 0 {
+			return articles[idx].Slug
+		}
+	}
+	return "#" + strconv.Itoa(id)
+}
+
+func (r snapshotRefs) project(id int) string {
+	for _, projects := range [][]entity.WriteProject{r.after.Projects, r.before.Projects} {
+		if idx := slices.IndexFunc(projects, func(p entity.WriteProject) bool { return p.Id == id }); idx >= 0 {
+			return projects[idx].Slug
+		}
+	}
+	return "#" + strconv.Itoa(id)
+}
+
+func (r snapshotRefs) tag(id int) string {
+	for _, tags := range [][]entity.WriteTag{r.after.Tags, r.before.Tags} {
+		if idx := slices.IndexFunc(tags, func(t entity.WriteTag) bool { return t.Id == id }); idx >= 0 {
+			return tags[idx].Name
+		}
+	}
+	return "#" + strconv.Itoa(id)
+}
+
+func serieChanges(before, after entity.WriteSerie) []entity.FieldChange {
+	var fields fieldChanges
+	fields.compare("name", before.Name, after.Name)
+	fields.compare("thumbnail", before.Thumbnail, after.Thumbnail)
+	fields.compare("description", before.Description, after.Description)
+	fields.compare("state", before.State, after.State)
+	fields.compare("publish_at", before.PublishAt, after.PublishAt)
+	return fields
+}
+
+// Articles are compared along with their content
+func articleChanges(before, after entity.WriteArticle) []entity.FieldChange {
+	var fields fieldChanges
+	fields.compare("title", before.Title, after.Title)
+	fields.compare("subtitle", before.Subtitle, after.Subtitle)
+	fields.compare("thumbnail", before.Thumbnail, after.Thumbnail)
+	fields.compare("content", before.Content, after.Content)
+	fields.compare("state", before.State, after.State)
+	fields.compare("publish_at", before.PublishAt, after.PublishAt)
+	fields.compare("serie", before.Serie, after.Serie)
+	fields.compare("tags", before.Tags, after.Tags)
+	return fields
+}
+
+// Projects are compared along with their description. Their serie has no
+// order to speak of
+func projectChanges(before, after entity.WriteProject) []entity.FieldChange {
+	var fields fieldChanges
+	fields.compare("name", before.Name, after.Name)
+	fields.compare("synopsis", before.Synopsis, after.Synopsis)
+	fields.compare("thumbnail", before.Thumbnail, after.Thumbnail)
+	fields.compare("description", before.Description, after.Description)
+	fields.compare("state", before.State, after.State)
+	fields.compare("publish_at", before.PublishAt, after.PublishAt)
+	fields.compare("serie", serieSlug(before.Serie), serieSlug(after.Serie))
+	fields.compare("tags", before.Tags, after.Tags)
+	return fields
+}
+
+func linkChanges(before, after entity.WriteProjectLink) []entity.FieldChange {
+	var fields fieldChanges
+	fields.compare("url", before.Url, after.Url)
+	fields.compare("display_text", before.DisplayText, after.DisplayText)
+	return fields
+}
+
+func serieSlug(part *entity.WriteSeriePart) string {
+	if part == nil {
+		return ""
+	}
+	return part.Slug
+}
+
+type fieldChanges []entity.FieldChange
+
+// Records the field `name` when its values differ, as they're shown
+func (f *fieldChanges) compare(name string, before, after any) {
+	b, a := shown(before), shown(after)
+	if b != a {
+		*f = append(*f, entity.FieldChange{Name: name, Before: b, After: a})
+	}
+}
+
+// Times are shown in the precision of a Postgres `TIMESTAMP`, and tags as a
+// set. No serie, thumbnail or time are shown as nothing
+func shown(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+	case *entity.WriteSeriePart:
+		if v == nil || v.Slug == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s #%d", v.Slug, v.Order)
+	case []string:
+		set := slices.Clone(v)
+		slices.Sort(set)
+		return strings.Join(slices.Compact(set), ", ")
+	}
+	return fmt.Sprint(v)
+}
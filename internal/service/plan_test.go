@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/persistence"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+)
+
+type row struct {
+	id    int
+	value string
+}
+
+func TestDiffRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []row
+		after  []row
+		want   []entity.Change
+	}{
+		{name: "nothing"},
+		{
+			name:   "unchanged",
+			before: []row{{1, "a"}, {2, "b"}},
+			after:  []row{{2, "b"}, {1, "a"}}},
+		{
+			name:   "inserted, updated then deleted",
+			before: []row{{1, "a"}, {2, "b"}, {3, "c"}},
+			after:  []row{{4, "d"}, {2, "B"}, {1, "a"}, {5, "e"}},
+			want: []entity.Change{
+				{Entity: "row", Ref: "d", Action: entity.ChangeInsert},
+				{Entity: "row", Ref: "e", Action: entity.ChangeInsert},
+				{Entity: "row", Ref: "B", Action: entity.ChangeUpdate, Fields: []entity.FieldChange{
+					{Name: "value", Before: "b", After: "B"}}},
+				{Entity: "row", Ref: "c", Action: entity.ChangeDelete}}},
+		{
+			name:   "matched by id rather than ref",
+			before: []row{{1, "a"}},
+			after:  []row{{2, "a"}},
+			want: []entity.Change{
+				{Entity: "row", Ref: "a", Action: entity.ChangeInsert},
+				{Entity: "row", Ref: "a", Action: entity.ChangeDelete}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffRows(tt.before, tt.after,
+				func(r row) int { return r.id },
+				func(r row) string { return r.value },
+				"row", func(before, after row) []entity.FieldChange {
+					var fields fieldChanges
+					fields.compare("value", before.value, after.value)
+					return fields
+				})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffRows\n got: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanChanges(t *testing.T) {
+	publishAt := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	thumbnail := "cover.png"
+	before := entity.Snapshot{
+		Series: []entity.WriteSerie{{Id: 1, Name: "Basics", Slug: "basics"}},
+		Articles: []entity.WriteArticle{
+			{
+				Id: 1, Title: "Hello", Slug: "hello", Content: "<p>Hi</p>",
+				State: entity.StatePublished,
+				Serie: &entity.WriteSeriePart{Id: 1, Slug: "basics", Order: 1},
+				Tags:  []string{"sql", "go"}},
+			{Id: 2, Title: "Gone", Slug: "gone", State: entity.StateDraft}},
+		Tags: []entity.WriteTag{{Id: 1, Name: "go"}, {Id: 2, Name: "sql"}},
+		ArticleTags: []entity.WriteArticleTag{
+			{Id: 1, ArticleId: 1, TagId: 1},
+			{Id: 2, ArticleId: 1, TagId: 2},
+			{Id: 3, ArticleId: 2, TagId: 1}},
+	}
+	after := entity.Snapshot{
+		Series: []entity.WriteSerie{{Id: 1, Name: "Basics", Slug: "basics"}},
+		Articles: []entity.WriteArticle{{
+			Id: 1, Title: "Hello!", Slug: "hello", Content: "<p>Hi</p>",
+			State:     entity.StateScheduled,
+			PublishAt: publishAt,
+			Thumbnail: &thumbnail,
+			Serie:     &entity.WriteSeriePart{Id: 1, Slug: "basics", Order: 2},
+			Tags:      []string{"go", "sql", "go"},
+			UpdatedAt: publishAt, // not part of the plan
+		}},
+		Tags: []entity.WriteTag{{Id: 1, Name: "go"}, {Id: 2, Name: "sql"}},
+		ArticleTags: []entity.WriteArticleTag{
+			{Id: 1, ArticleId: 1, TagId: 1},
+			{Id: 2, ArticleId: 1, TagId: 2}},
+	}
+
+	want := []entity.Change{
+		{Entity: "article", Ref: "hello", Action: entity.ChangeUpdate, Fields: []entity.FieldChange{
+			{Name: "title", Before: "Hello", After: "Hello!"},
+			{Name: "thumbnail", Before: "", After: "cover.png"},
+			{Name: "state", Before: string(entity.StatePublished), After: string(entity.StateScheduled)},
+			{Name: "publish_at", Before: "", After: "2026-05-01T08:00:00Z"},
+			{Name: "serie", Before: "basics #1", After: "basics #2"}}},
+		{Entity: "article", Ref: "gone", Action: entity.ChangeDelete},
+		{Entity: "article_tag", Ref: "gone go", Action: entity.ChangeDelete},
+	}
+	if got := planChanges(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("planChanges\n got: %+v\nwant: %+v", got, want)
+	}
+	if got := planChanges(after, after); len(got) > 0 {
+		t.Errorf("expected no change between the same snapshots, got %+v", got)
+	}
+}
+
+func TestSnapshotRefs(t *testing.T) {
+	ref := snapshotRefs{
+		before: entity.Snapshot{
+			Articles: []entity.WriteArticle{{Id: 1, Slug: "old"}, {Id: 2, Slug: "deleted"}},
+			Projects: []entity.WriteProject{{Id: 1, Slug: "project"}},
+			Tags:     []entity.WriteTag{{Id: 1, Name: "go"}}},
+		after: entity.Snapshot{
+			Articles: []entity.WriteArticle{{Id: 1, Slug: "new"}}},
+	}
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{ref.article(1), "new"},
+		{ref.article(2), "deleted"},
+		{ref.article(3), "#3"},
+		{ref.project(1), "project"},
+		{ref.tag(1), "go"},
+		{ref.tag(2), "#2"},
+	}
+	for i, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("ref %d: got %q, want %q", i, tt.got, tt.want)
+		}
+	}
+}
+
+func TestAtomic(t *testing.T) {
+	ctx := context.Background()
+	files := contentFiles(t, map[string]string{"a.html": "<p>a</p>", "b.html": "<p>b</p>"})
+	fails := errors.New("fails")
+	write := func(s Service) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if err := s.UpsertArticles(ctx, []entity.WriteArticle{
+				{Id: 1, Title: "Edited", Slug: "hello", Content: files["b.html"]},
+				{Id: 2, Title: "New", Slug: "new", Content: files["a.html"]}}); err != nil {
+				return err
+			}
+			return s.DeleteArticles(ctx, []entity.DeleteById{{Id: 3}})
+		}
+	}
+	tests := []struct {
+		name   string
+		dryRun bool
+		fx     func(s Service) func(ctx context.Context) error
+		err    error
+		plan   []entity.Change
+		want   []string // titles left, by id
+	}{
+		{
+			name: "written",
+			fx:   write,
+			want: []string{"Edited", "New"}},
+		{
+			name:   "dry run",
+			dryRun: true,
+			fx:     write,
+			plan: []entity.Change{
+				{Entity: "article", Ref: "new", Action: entity.ChangeInsert},
+				{Entity: "article", Ref: "hello", Action: entity.ChangeUpdate, Fields: []entity.FieldChange{
+					{Name: "title", Before: "Hello", After: "Edited"},
+					{Name: "content", Before: "<p>a</p>", After: "<p>b</p>"}}},
+				{Entity: "article", Ref: "gone", Action: entity.ChangeDelete}},
+			want: []string{"Hello", "Gone"}},
+		{
+			name: "failed",
+			fx: func(s Service) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					if err := write(s)(ctx); err != nil {
+						return err
+					}
+					return fails
+				}
+			},
+			err:  fails,
+			want: []string{"Hello", "Gone"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(persistence.NewMemory(cursor.NewSealer([]byte("key"))))
+			if err := s.UpsertArticles(ctx, []entity.WriteArticle{
+				{Id: 1, Title: "Hello", Slug: "hello", Content: files["a.html"]},
+				{Id: 3, Title: "Gone", Slug: "gone", Content: files["a.html"]}}); err != nil {
+				t.Fatal(err)
+			}
+
+			plan, err := s.Atomic(ctx, tt.dryRun, tt.fx(s))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(plan, tt.plan) {
+				t.Errorf("plan\n got: %+v\nwant: %+v", plan, tt.plan)
+			}
+
+			snapshot, err := s.Snapshot(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, a := range snapshot.Articles {
+				got = append(got, a.Title)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got articles %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+// Fills in the state of an entry being written, see `entity.WriteArticle.State`
+func publication(state entity.PublicationState, publishAt time.Time) (entity.PublicationState, error) {
+	switch {
+	case state == "" && publishAt.IsZero():
+		return entity.StatePublished, nil
+	case state == "":
+		return entity.StateScheduled, nil
+	case !state.Valid():
+		return "", oops.BadValues{
+			Msg: fmt.Sprintf("`%s` isn't a publication state", state)}
+	case state == entity.StateScheduled && publishAt.IsZero():
+		return "", oops.BadValues{
+			Msg: "a scheduled entry needs its `publish_at`"}
+	}
+	return state, nil
+}
+
+// Publishes scheduled entries as their time comes, until `ctx` is done.
+// Besides waking up for the next scheduled entry, it also checks every
+// `maxWait`, since entries may be scheduled by another process (see `cmd/crud`)
+func (s Service) RunScheduler(ctx context.Context, maxWait time.Duration) {
+	for {
+		published, err := s.store.PublishDue(ctx)
+		if err != nil {
+			log.Printf("service<Service.RunScheduler>: %v", err)
+		} else if published > 0 {
+			log.Printf("Published %d scheduled entries", published)
+		}
+
+		wait := maxWait
+		if next, ok, err := s.store.UntilNextPublication(ctx); err != nil {
+			log.Printf("service<Service.RunScheduler>: %v", err)
+		} else if ok && next < wait {
+			wait = max(next, time.Second) // keeps from spinning on clock skews
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
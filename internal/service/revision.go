@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/diff"
+	"golang.org/x/sync/errgroup"
+)
+
+func (s Service) ArticleHistory(ctx context.Context, id int) (entity.ArticleHistoryPage, error) {
+	history, err := s.store.ArticleHistory(ctx, id)
+	if err != nil {
+		return entity.ArticleHistoryPage{}, fmt.Errorf("service<Service.ArticleHistory>: %w", err)
+	}
+	return history, nil
+}
+
+// Changes made to an article going from the revision `fromId` to `toId`,
+// which may be given in any order
+func (s Service) ArticleDiff(ctx context.Context, id int, fromId int, toId int) (entity.ArticleDiffPage, error) {
+	var history entity.ArticleHistoryPage
+	var from, to entity.Revision
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.Go(func() (err error) {
+		history, err = s.store.ArticleHistory(groupCtx, id)
+		return err
+	})
+	group.Go(func() (err error) {
+		from, err = s.store.ArticleRevision(groupCtx, id, fromId)
+		return err
+	})
+	group.Go(func() (err error) {
+		to, err = s.store.ArticleRevision(groupCtx, id, toId)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return entity.ArticleDiffPage{}, fmt.Errorf("service<Service.ArticleDiff>: %w", err)
+	}
+
+	return entity.ArticleDiffPage{
+		History:        history,
+		From:           from,
+		To:             to,
+		TitleChanges:   diff.Words(from.Title, to.Title),
+		ContentChanges: diff.Words(plainText(from.Content), plainText(to.Content))}, nil
+}
+
+func (s Service) RollbackArticles(ctx context.Context, rollbacks []entity.RollbackArticle) error {
+	for idx, r := range rollbacks {
+		if r.Message == "" {
+			rollbacks[idx].Message = fmt.Sprintf("Rolled back to revision %d", r.RevisionId)
+		}
+	}
+	if err := s.store.RollbackArticles(ctx, rollbacks); err != nil {
+		return fmt.Errorf("service<Service.RollbackArticles>: %w", err)
+	}
+	return nil
+}
+
+var markup = regexp.MustCompile(`<[^>]*>`)
+
+// Text of an HTML content as a reader sees it. Tags are replaced by spaces,
+// so words of adjacent blocks aren't glued together
+func plainText(content string) string {
+	return html.UnescapeString(markup.ReplaceAllString(content, " "))
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+)
+
+// Fills the store with the `cmd/crud` data files found in `dir`. A file that
+// is missing or fails to load is only reported, so a partial seed still
+// leaves something to look at
+func (s Service) Seed(ctx context.Context, dir string) {
+	steps := []struct {
+		file string
+		load func(ctx context.Context, f *os.File) error
+	}{
+		{"writeSerie.json", seedWith(s.UpsertSeries)},
+		{"writeTag.json", seedWith(s.UpsertTags)},
+		{"writeArticle.json", seedWith(s.UpsertArticles)},
+		{"writeArticleTag.json", seedWith(s.UpsertArticleTags)},
+		{"writeProject.json", seedWith(s.UpsertProjects)},
+		{"writeProjectTag.json", seedWith(s.UpsertProjectTags)},
+		{"writeProjectLink.json", seedWith(s.UpsertProjectLinks)}}
+	for _, step := range steps {
+		f, err := os.Open(path.Join(dir, step.file))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				log.Printf("seeding %s: %v", step.file, err)
+			}
+			continue
+		}
+
+		if err := step.load(ctx, f); err != nil {
+			log.Printf("seeding %s: %v", step.file, err)
+		}
+		f.Close()
+	}
+}
+
+// Upserts the rows under `data` of a data file
+func seedWith[T any](upsert func(ctx context.Context, rows []T) error) func(context.Context, *os.File) error {
+	return func(ctx context.Context, f *os.File) error {
+		var data struct {
+			Rows []T `json:"data"`
+		}
+		if err := json.NewDecoder(f).Decode(&data); err != nil {
+			return fmt.Errorf("service<Service.Seed>: %w", err)
+		}
+		if err := upsert(ctx, data.Rows); err != nil {
+			return fmt.Errorf("service<Service.Seed>: %w", err)
+		}
+		return nil
+	}
+}
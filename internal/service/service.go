@@ -1,13 +1,82 @@
 package service
 
 import (
+	"context"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
 	"github.com/solsteace/misite/internal/persistence"
 )
 
+// Everything the service needs from the persistence layer. Satisfied by
+// `persistence.Pg` and `persistence.Memory`
+type Store interface {
+	Articles(ctx context.Context, param persistence.ArticlesQueryParam) ([]entity.ArticleListPage, error)
+	Projects(ctx context.Context, param persistence.ProjectsQueryParam) ([]entity.ProjectListPage, error)
+	SerieList(ctx context.Context, param persistence.SerieListQueryParam) ([]entity.SerieListPage, error)
+	ArticleTags(ctx context.Context, param persistence.TagQueryParams) ([]entity.TagStatPage, error)
+	ProjectTags(ctx context.Context, param persistence.TagQueryParams) ([]entity.TagStatPage, error)
+	TagList(ctx context.Context, param persistence.TagListQueryParam) ([]entity.TagListPage, error)
+	TagByName(ctx context.Context, name string) (entity.TagListPage, error)
+	CountArticles(ctx context.Context, param persistence.ExplorationQueryParam) (int, error)
+	CountProjects(ctx context.Context, param persistence.ExplorationQueryParam) (int, error)
+	CountSeries(ctx context.Context, param persistence.SerieExplorationQueryParam) (int, error)
+	CountTags(ctx context.Context, param persistence.TagExplorationQueryParam) (int, error)
+
+	Article(ctx context.Context, id int) (entity.ArticlePage, error)
+	Project(ctx context.Context, id int) (entity.ProjectPage, error)
+	Serie(ctx context.Context, id int) (entity.SeriePage, error)
+	SerieArticleList(ctx context.Context, id int, param persistence.SerieContentQueryParam) ([]entity.SeriePageArticleList, error)
+	SerieProjectList(ctx context.Context, id int, param persistence.SerieContentQueryParam) ([]entity.SeriePageProjectList, error)
+	SerieContents(ctx context.Context, id int) ([]entity.SerieContentEntry, error)
+	ArticleSeriePart(ctx context.Context, id int) (entity.SeriePart, error)
+	CountArticleMatchingTags(ctx context.Context, tagId []int) ([]entity.Tag, []int, error)
+	CountProjectMatchingTags(ctx context.Context, tagId []int) ([]entity.Tag, []int, error)
+	RelatedArticles(ctx context.Context, id int, param persistence.RelatedQueryParam) ([]entity.RelatedEntry, error)
+	RelatedProjects(ctx context.Context, id int, param persistence.RelatedQueryParam) ([]entity.RelatedEntry, error)
+	ArticleHistory(ctx context.Context, id int) (entity.ArticleHistoryPage, error)
+	ArticleRevision(ctx context.Context, articleId int, revisionId int) (entity.Revision, error)
+	PublishDue(ctx context.Context) (int, error)
+	UntilNextPublication(ctx context.Context) (time.Duration, bool, error)
+	ResolveSlug(ctx context.Context, kind entity.SlugKind, ref string) (int, string, error)
+	Sitemap(ctx context.Context) ([]entity.SitemapEntry, error)
+	Snapshot(ctx context.Context) (entity.Snapshot, error)
+	Atomic(ctx context.Context, commit bool, fx func(ctx context.Context) error) error
+
+	InsertArticles(ctx context.Context, articles []entity.WriteArticle, contents []string) ([]int, error)
+	UpsertArticles(ctx context.Context, articles []entity.WriteArticle, contents []string) error
+	DeleteArticles(ctx context.Context, articles []entity.DeleteById) error
+	RollbackArticles(ctx context.Context, rollbacks []entity.RollbackArticle) error
+	InsertArticlesTags(ctx context.Context, articleTags []entity.WriteArticleTag) ([]int, error)
+	UpsertArticleTags(ctx context.Context, articleTags []entity.WriteArticleTag) error
+	DeleteArticleTags(ctx context.Context, articleTags []entity.DeleteById) error
+	InsertProjects(ctx context.Context, projects []entity.WriteProject, contents []string) ([]int, error)
+	UpsertProjects(ctx context.Context, projects []entity.WriteProject, contents []string) error
+	DeleteProjects(ctx context.Context, projects []entity.DeleteById) error
+	InsertProjectTags(ctx context.Context, projectTags []entity.WriteProjectTag) ([]int, error)
+	UpsertProjectTags(ctx context.Context, projectTags []entity.WriteProjectTag) error
+	DeleteProjectTags(ctx context.Context, projectTags []entity.DeleteById) error
+	InsertProjectLinks(ctx context.Context, projectLinks []entity.WriteProjectLink) ([]int, error)
+	UpsertProjectLinks(ctx context.Context, projectLinks []entity.WriteProjectLink) error
+	DeleteProjectLinks(ctx context.Context, projectLinks []entity.DeleteById) error
+	InsertTags(ctx context.Context, tags []entity.WriteTag) ([]int, error)
+	UpsertTags(ctx context.Context, tags []entity.WriteTag) error
+	DeleteTags(ctx context.Context, tags []entity.DeleteById) error
+	InsertSeries(ctx context.Context, series []entity.WriteSerie) ([]int, error)
+	UpsertSeries(ctx context.Context, series []entity.WriteSerie) error
+	DeleteSeries(ctx context.Context, series []entity.DeleteById) error
+}
+
 type Service struct {
-	store *persistence.Pg // TODO: change to interface if needed
+	store Store
+
+	relatedArticles *cache[int, entity.RelatedPage]
+	relatedProjects *cache[int, entity.RelatedPage]
 }
 
-func NewService(store *persistence.Pg) Service {
-	return Service{store: store}
+func NewService(store Store) Service {
+	return Service{
+		store:           store,
+		relatedArticles: newCache[int, entity.RelatedPage](rELATED_TTL),
+		relatedProjects: newCache[int, entity.RelatedPage](rELATED_TTL)}
 }
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+	"github.com/solsteace/misite/internal/utility/lib/slug"
+)
+
+// Finds the entry `ref` leads to, either through its slug, id or a former
+// slug. Gives its id and current slug
+func (s Service) ResolveSlug(ctx context.Context, kind entity.SlugKind, ref string) (int, string, error) {
+	id, current, err := s.store.ResolveSlug(ctx, kind, ref)
+	if err != nil {
+		return 0, "", fmt.Errorf("service<Service.ResolveSlug>: %w", err)
+	}
+	return id, current, nil
+}
+
+// A slug given along with an entry being written, if any, should be usable
+// in its URL
+func checkSlug(given string) error {
+	if given != "" && !slug.Valid(given) {
+		return oops.BadValues{
+			Msg: fmt.Sprintf(
+				"`%s` isn't a slug: use lowercase letters, digits and single hyphens, and not only digits",
+				given)}
+	}
+	return nil
+}
@@ -1,13 +1,16 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/persistence"
 )
 
-func (s Service) Project(id int) (entity.ProjectPage, error) {
-	project, err := s.store.Project(id)
+func (s Service) Project(ctx context.Context, id int) (entity.ProjectPage, error) {
+	project, err := s.store.Project(ctx, id)
 	if err != nil {
 		return entity.ProjectPage{}, fmt.Errorf(
 			"service<Service.Project>: %w", err)
@@ -15,8 +18,8 @@ func (s Service) Project(id int) (entity.ProjectPage, error) {
 	return project, nil
 }
 
-func (s Service) Article(id int) (entity.ArticlePage, error) {
-	article, err := s.store.Article(id)
+func (s Service) Article(ctx context.Context, id int) (entity.ArticlePage, error) {
+	article, err := s.store.Article(ctx, id)
 	if err != nil {
 		return entity.ArticlePage{}, fmt.Errorf(
 			"service<Service.Article>: %w", err)
@@ -24,11 +27,101 @@ func (s Service) Article(id int) (entity.ArticlePage, error) {
 	return article, nil
 }
 
-func (s Service) Serie(id int) (entity.SeriePage, error) {
-	serie, err := s.store.Serie(id)
+func (s Service) Serie(ctx context.Context, id int) (entity.SeriePage, error) {
+	serie, err := s.store.Serie(ctx, id)
 	if err != nil {
 		return entity.SeriePage{}, fmt.Errorf(
 			"service<Service.Serie>: %w", err)
 	}
 	return serie, nil
 }
+
+func (s Service) SerieContents(ctx context.Context, id int) ([]entity.SerieContentEntry, error) {
+	contents, err := s.store.SerieContents(ctx, id)
+	if err != nil {
+		return []entity.SerieContentEntry{}, fmt.Errorf(
+			"service<Service.SerieContents>: %w", err)
+	}
+	return contents, nil
+}
+
+func (s Service) ArticleSeriePart(ctx context.Context, id int) (entity.SeriePart, error) {
+	part, err := s.store.ArticleSeriePart(ctx, id)
+	if err != nil {
+		return entity.SeriePart{}, fmt.Errorf(
+			"service<Service.ArticleSeriePart>: %w", err)
+	}
+	return part, nil
+}
+
+const (
+	rELATED_TTL  = 10 * time.Minute // how long a related panel is kept before being ranked again
+	rELATED_SIZE = 5
+)
+
+// Articles related to `article`, along with how many articles have each of
+// its tags
+func (s Service) RelatedArticles(ctx context.Context, article entity.ArticlePage) (entity.RelatedPage, error) {
+	if related, ok := s.relatedArticles.get(article.Id); ok {
+		return related, nil
+	}
+
+	entries, err := s.store.RelatedArticles(ctx,
+		article.Id, persistence.RelatedQueryParam{Limit: rELATED_SIZE})
+	if err != nil {
+		return entity.RelatedPage{}, fmt.Errorf(
+			"service<Service.RelatedArticles>: %w", err)
+	}
+	var tagId []int
+	for _, t := range article.Tag {
+		tagId = append(tagId, t.Id)
+	}
+	tags, count, err := s.store.CountArticleMatchingTags(ctx, tagId)
+	if err != nil {
+		return entity.RelatedPage{}, fmt.Errorf(
+			"service<Service.RelatedArticles>: %w", err)
+	}
+
+	related := entity.RelatedPage{Entries: entries, Tag: tagStats(tags, count)}
+	s.relatedArticles.put(article.Id, related)
+	return related, nil
+}
+
+// Projects related to `project`, along with how many projects have each of
+// its tags
+func (s Service) RelatedProjects(ctx context.Context, project entity.ProjectPage) (entity.RelatedPage, error) {
+	if related, ok := s.relatedProjects.get(project.Id); ok {
+		return related, nil
+	}
+
+	entries, err := s.store.RelatedProjects(ctx,
+		project.Id, persistence.RelatedQueryParam{Limit: rELATED_SIZE})
+	if err != nil {
+		return entity.RelatedPage{}, fmt.Errorf(
+			"service<Service.RelatedProjects>: %w", err)
+	}
+	var tagId []int
+	for _, t := range project.Tag {
+		tagId = append(tagId, t.Id)
+	}
+	tags, count, err := s.store.CountProjectMatchingTags(ctx, tagId)
+	if err != nil {
+		return entity.RelatedPage{}, fmt.Errorf(
+			"service<Service.RelatedProjects>: %w", err)
+	}
+
+	related := entity.RelatedPage{Entries: entries, Tag: tagStats(tags, count)}
+	s.relatedProjects.put(project.Id, related)
+	return related, nil
+}
+
+func tagStats(tags []entity.Tag, count []int) []entity.TagStatPage {
+	var stats []entity.TagStatPage
+	for idx, t := range tags {
+		stats = append(stats, entity.TagStatPage{
+			Id:    t.Id,
+			Name:  t.Name,
+			Count: count[idx]})
+	}
+	return stats
+}
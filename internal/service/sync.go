@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+	"github.com/solsteace/misite/internal/utility/lib/slug"
+)
+
+// Recorded along with the revisions made by a sync, unless the entry tells
+// otherwise
+const sYNC_MESSAGE = "Synced from the content directory"
+
+// Writes needed to bring the site to a `entity.SyncSource`
+type syncPlan struct {
+	changes []entity.Change
+
+	insertSeries []entity.WriteSerie
+	updateSeries []entity.WriteSerie
+	deleteSeries []entity.DeleteById
+
+	insertArticles        []entity.WriteArticle
+	insertArticleContents []string
+	updateArticles        []entity.WriteArticle
+	updateArticleContents []string
+	deleteArticles        []entity.DeleteById
+
+	insertProjects        []entity.WriteProject
+	insertProjectContents []string
+	updateProjects        []entity.WriteProject
+	updateProjectContents []string
+	deleteProjects        []entity.DeleteById
+
+	insertLinks       []entity.WriteProjectLink
+	updateLinks       []entity.WriteProjectLink
+	deleteLinks       []entity.DeleteById
+	deleteProjectTags []entity.DeleteById
+}
+
+func (p *syncPlan) record(kind, ref string, action entity.ChangeAction, fields ...entity.FieldChange) {
+	p.changes = append(p.changes, entity.Change{
+		Entity: kind,
+		Ref:    ref,
+		Action: action,
+		Fields: fields})
+}
+
+// Makes the site match `source`. Entries are matched by their slug: the ones
+// missing from the site are inserted, the differing ones updated and the ones
+// missing from `source` deleted. Gives the changes made
+func (s Service) Sync(ctx context.Context, source entity.SyncSource) ([]entity.Change, error) {
+	var plan syncPlan
+	err := s.store.Atomic(ctx, true, func(ctx context.Context) error {
+		current, err := s.store.Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+		if plan, err = planSync(source, current); err != nil {
+			return err
+		}
+		return s.applySync(ctx, plan)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service<Service.Sync>: %w", err)
+	}
+	return plan.changes, nil
+}
+
+func planSync(source entity.SyncSource, current entity.Snapshot) (syncPlan, error) {
+	var plan syncPlan
+
+	series := map[string]entity.WriteSerie{}
+	for _, sr := range current.Series {
+		series[sr.Slug] = sr
+	}
+	wanted := map[string]bool{}
+	for _, sr := range source.Series {
+		if err := checkSyncSlug("serie", sr.Slug, wanted); err != nil {
+			return plan, err
+		}
+		state, err := publication(sr.State, sr.PublishAt)
+		if err != nil {
+			return plan, fmt.Errorf("serie %q: %w", sr.Slug, err)
+		}
+		sr.State = state
+
+		have, ok := series[sr.Slug]
+		if !ok {
+			plan.insertSeries = append(plan.insertSeries, sr)
+			plan.record("serie", sr.Slug, entity.ChangeInsert)
+			continue
+		}
+		sr.Id = have.Id
+		sr.State = keptPublication(sr.State, sr.PublishAt, have.State)
+		if fields := serieChanges(have, sr); len(fields) > 0 {
+			plan.updateSeries = append(plan.updateSeries, sr)
+			plan.record("serie", sr.Slug, entity.ChangeUpdate, fields...)
+		}
+	}
+	for _, sr := range current.Series {
+		if !wanted[sr.Slug] {
+			plan.deleteSeries = append(plan.deleteSeries, entity.DeleteById{Id: sr.Id})
+			plan.record("serie", sr.Slug, entity.ChangeDelete)
+		}
+	}
+
+	articles := map[string]entity.WriteArticle{}
+	for _, a := range current.Articles {
+		articles[a.Slug] = a
+	}
+	wanted = map[string]bool{}
+	for _, a := range source.Articles {
+		if err := checkSyncSlug("article", a.Slug, wanted); err != nil {
+			return plan, err
+		}
+		state, err := publication(a.State, a.PublishAt)
+		if err != nil {
+			return plan, fmt.Errorf("article %q: %w", a.Slug, err)
+		}
+		a.State = state
+
+		content, meta, err := readContent(a.Content)
+		if err != nil {
+			return plan, fmt.Errorf("article %q: %w", a.Slug, err)
+		}
+		a = describeArticle(a, meta)
+		if a.Serie == nil {
+			a.Serie = &entity.WriteSeriePart{}
+		}
+		if a.Tags == nil {
+			a.Tags = []string{}
+		}
+		if a.Thumbnail == nil {
+			a.Thumbnail = new(string)
+		}
+		if a.Message == "" {
+			a.Message = sYNC_MESSAGE
+		}
+
+		have, ok := articles[a.Slug]
+		if !ok {
+			plan.insertArticles = append(plan.insertArticles, a)
+			plan.insertArticleContents = append(plan.insertArticleContents, content)
+			plan.record("article", a.Slug, entity.ChangeInsert)
+			continue
+		}
+		a.Id = have.Id
+		a.State = keptPublication(a.State, a.PublishAt, have.State)
+		described := a
+		described.Content = content
+		if fields := articleChanges(have, described); len(fields) > 0 {
+			plan.updateArticles = append(plan.updateArticles, a)
+			plan.updateArticleContents = append(plan.updateArticleContents, content)
+			plan.record("article", a.Slug, entity.ChangeUpdate, fields...)
+		}
+	}
+	for _, a := range current.Articles {
+		if !wanted[a.Slug] {
+			plan.deleteArticles = append(plan.deleteArticles, entity.DeleteById{Id: a.Id})
+			plan.record("article", a.Slug, entity.ChangeDelete)
+		}
+	}
+
+	projects := map[string]entity.WriteProject{}
+	for _, p := range current.Projects {
+		projects[p.Slug] = p
+	}
+	wanted = map[string]bool{}
+	for _, p := range source.Projects {
+		if err := checkSyncSlug("project", p.Slug, wanted); err != nil {
+			return plan, err
+		}
+		state, err := publication(p.State, p.PublishAt)
+		if err != nil {
+			return plan, fmt.Errorf("project %q: %w", p.Slug, err)
+		}
+		p.State = state
+
+		content, meta, err := readContent(p.Description)
+		if err != nil {
+			return plan, fmt.Errorf("project %q: %w", p.Slug, err)
+		}
+		p = describeProject(p, meta)
+		if p.Serie == nil {
+			p.Serie = &entity.WriteSeriePart{}
+		}
+		if p.Tags == nil {
+			p.Tags = []string{}
+		}
+		if p.Thumbnail == nil {
+			p.Thumbnail = new(string)
+		}
+
+		// Links of existing projects are matched apart, so that a project
+		// isn't revised for them
+		links := p.Links
+		p.Links = nil
+		if p.Message == "" {
+			p.Message = sYNC_MESSAGE
+		}
+
+		have, ok := projects[p.Slug]
+		if !ok {
+			p.Links = links
+			if p.Links == nil {
+				p.Links = []entity.WriteProjectLink{}
+			}
+			plan.insertProjects = append(plan.insertProjects, p)
+			plan.insertProjectContents = append(plan.insertProjectContents, content)
+			plan.record("project", p.Slug, entity.ChangeInsert)
+			for _, l := range links {
+				plan.record("project_link", p.Slug+" "+l.Url, entity.ChangeInsert)
+			}
+			continue
+		}
+		p.Id = have.Id
+		p.State = keptPublication(p.State, p.PublishAt, have.State)
+		described := p
+		described.Description = content
+		if fields := projectChanges(have, described); len(fields) > 0 {
+			plan.updateProjects = append(plan.updateProjects, p)
+			plan.updateProjectContents = append(plan.updateProjectContents, content)
+			plan.record("project", p.Slug, entity.ChangeUpdate, fields...)
+		}
+		plan.syncLinks(p, links, current.ProjectLinks)
+	}
+	for _, p := range current.Projects {
+		if wanted[p.Slug] {
+			continue
+		}
+
+		// Neither the links nor the tags of a project go along with it
+		for _, l := range current.ProjectLinks {
+			if l.ProjectId == p.Id {
+				plan.deleteLinks = append(plan.deleteLinks, entity.DeleteById{Id: l.Id})
+			}
+		}
+		for _, pt := range current.ProjectTags {
+			if pt.ProjectId == p.Id {
+				plan.deleteProjectTags = append(plan.deleteProjectTags, entity.DeleteById{Id: pt.Id})
+			}
+		}
+		plan.deleteProjects = append(plan.deleteProjects, entity.DeleteById{Id: p.Id})
+		plan.record("project", p.Slug, entity.ChangeDelete)
+	}
+	return plan, nil
+}
+
+// Matches the links of an existing project with `links`, by their url
+func (p *syncPlan) syncLinks(
+	project entity.WriteProject,
+	links []entity.WriteProjectLink,
+	current []entity.WriteProjectLink,
+) {
+	have := map[string]entity.WriteProjectLink{}
+	for _, l := range current {
+		if l.ProjectId == project.Id {
+			have[l.Url] = l
+		}
+	}
+	for _, l := range links {
+		l.ProjectId = project.Id
+		ref := project.Slug + " " + l.Url
+		if existing, ok := have[l.Url]; !ok {
+			p.insertLinks = append(p.insertLinks, l)
+			p.record("project_link", ref, entity.ChangeInsert)
+		} else if existing.DisplayText != l.DisplayText {
+			l.Id = existing.Id
+			p.updateLinks = append(p.updateLinks, l)
+			p.record("project_link", ref, entity.ChangeUpdate, linkChanges(existing, l)...)
+		}
+		delete(have, l.Url)
+	}
+	for _, l := range current {
+		if _, ok := have[l.Url]; ok && l.ProjectId == project.Id {
+			p.deleteLinks = append(p.deleteLinks, entity.DeleteById{Id: l.Id})
+			p.record("project_link", project.Slug+" "+l.Url, entity.ChangeDelete)
+		}
+	}
+}
+
+// Series go in first and out last, as articles and projects refer to them.
+// Articles leave before the others are written, and the stored ones move
+// before new ones come in, so an order in a serie is free once it's left
+func (s Service) applySync(ctx context.Context, plan syncPlan) error {
+	steps := []struct {
+		pending bool
+		apply   func() error
+	}{
+		{len(plan.insertSeries) > 0, func() error {
+			_, err := s.store.InsertSeries(ctx, plan.insertSeries)
+			return err
+		}},
+		{len(plan.updateSeries) > 0, func() error { return s.store.UpsertSeries(ctx, plan.updateSeries) }},
+		{len(plan.deleteArticles) > 0, func() error { return s.store.DeleteArticles(ctx, plan.deleteArticles) }},
+		{len(plan.updateArticles) > 0, func() error {
+			return s.store.UpsertArticles(ctx, plan.updateArticles, plan.updateArticleContents)
+		}},
+		{len(plan.insertArticles) > 0, func() error {
+			_, err := s.store.InsertArticles(ctx, plan.insertArticles, plan.insertArticleContents)
+			return err
+		}},
+		{len(plan.insertProjects) > 0, func() error {
+			_, err := s.store.InsertProjects(ctx, plan.insertProjects, plan.insertProjectContents)
+			return err
+		}},
+		{len(plan.updateProjects) > 0, func() error {
+			return s.store.UpsertProjects(ctx, plan.updateProjects, plan.updateProjectContents)
+		}},
+		{len(plan.insertLinks) > 0, func() error {
+			_, err := s.store.InsertProjectLinks(ctx, plan.insertLinks)
+			return err
+		}},
+		{len(plan.updateLinks) > 0, func() error { return s.store.UpsertProjectLinks(ctx, plan.updateLinks) }},
+		{len(plan.deleteLinks) > 0, func() error { return s.store.DeleteProjectLinks(ctx, plan.deleteLinks) }},
+		{len(plan.deleteProjectTags) > 0, func() error {
+			return s.store.DeleteProjectTags(ctx, plan.deleteProjectTags)
+		}},
+		{len(plan.deleteProjects) > 0, func() error { return s.store.DeleteProjects(ctx, plan.deleteProjects) }},
+		{len(plan.deleteSeries) > 0, func() error { return s.store.DeleteSeries(ctx, plan.deleteSeries) }},
+	}
+	for _, step := range steps {
+		if !step.pending {
+			continue
+		}
+		if err := step.apply(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Slugs name the entries of a content directory, so they should be given,
+// well formed and not taken by another entry of the same kind
+func checkSyncSlug(kind, s string, taken map[string]bool) error {
+	switch {
+	case !slug.Valid(s):
+		return oops.BadValues{
+			Msg: fmt.Sprintf("`%s` isn't a valid slug of a %s", s, kind)}
+	case taken[s]:
+		return oops.BadValues{
+			Msg: fmt.Sprintf("there's more than a %s of slug `%s`", kind, s)}
+	}
+	taken[s] = true
+	return nil
+}
+
+// The state to write over the stored one. A scheduled entry which was
+// already published by the scheduler stays published
+func keptPublication(
+	state entity.PublicationState,
+	publishAt time.Time,
+	stored entity.PublicationState,
+) entity.PublicationState {
+	if state == entity.StateScheduled &&
+		stored == entity.StatePublished &&
+		!publishAt.After(time.Now()) {
+		return stored
+	}
+	return state
+}
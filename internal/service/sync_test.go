@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/solsteace/misite/internal/entity"
+	"github.com/solsteace/misite/internal/persistence"
+	"github.com/solsteace/misite/internal/utility/lib/cursor"
+	"github.com/solsteace/misite/internal/utility/lib/oops"
+)
+
+// Writes the content `files` into a directory, giving their paths by name
+func contentFiles(t *testing.T, files map[string]string) map[string]string {
+	dir := t.TempDir()
+	paths := map[string]string{}
+	for name, content := range files {
+		paths[name] = filepath.Join(dir, name)
+		if err := os.WriteFile(paths[name], []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestPlanSync(t *testing.T) {
+	files := contentFiles(t, map[string]string{
+		"kept.html":    "<p>kept</p>",
+		"edited.html":  "<p>edited</p>",
+		"front.md":     "---\ntitle: Front\ntags: [go]\n---\nHi\n",
+		"project.html": "<p>project</p>"})
+	current := entity.Snapshot{
+		Articles: []entity.WriteArticle{
+			{Id: 1, Slug: "kept", Title: "Kept", Content: "<p>kept</p>", State: entity.StatePublished},
+			{Id: 2, Slug: "edited", Title: "Edited", Content: "<p>before</p>", State: entity.StatePublished},
+			{Id: 3, Slug: "old-name", Title: "Kept", Content: "<p>kept</p>", State: entity.StatePublished}},
+		Projects: []entity.WriteProject{
+			{Id: 1, Slug: "tool", Name: "Tool", Description: "<p>project</p>", State: entity.StatePublished},
+			{Id: 2, Slug: "gone", Name: "Gone", State: entity.StatePublished}},
+		ProjectLinks: []entity.WriteProjectLink{
+			{Id: 1, ProjectId: 1, Url: "https://a.example", DisplayText: "A"},
+			{Id: 2, ProjectId: 1, Url: "https://b.example", DisplayText: "B"},
+			{Id: 3, ProjectId: 2, Url: "https://c.example", DisplayText: "C"}},
+		ProjectTags: []entity.WriteProjectTag{{Id: 7, ProjectId: 2, TagId: 1}},
+	}
+
+	tests := []struct {
+		name    string
+		source  entity.SyncSource
+		current entity.Snapshot
+		want    []entity.Change
+		check   func(t *testing.T, plan syncPlan)
+	}{
+		{
+			name: "nothing"},
+		{
+			name: "unchanged",
+			source: entity.SyncSource{Articles: []entity.WriteArticle{
+				{Slug: "kept", Title: "Kept", Content: files["kept.html"]}}},
+			current: entity.Snapshot{Articles: current.Articles[:1]}},
+		{
+			name: "added",
+			source: entity.SyncSource{Articles: []entity.WriteArticle{
+				{Slug: "kept", Title: "Kept", Content: files["kept.html"]},
+				{Slug: "front", Content: files["front.md"]}}},
+			current: entity.Snapshot{Articles: current.Articles[:1]},
+			want: []entity.Change{
+				{Entity: "article", Ref: "front", Action: entity.ChangeInsert}},
+			check: func(t *testing.T, plan syncPlan) {
+				a := plan.insertArticles[0]
+				if a.Title != "Front" || !reflect.DeepEqual(a.Tags, []string{"go"}) || a.Message != sYNC_MESSAGE {
+					t.Errorf("the front matter should describe the article, got %+v", a)
+				}
+				if plan.insertArticleContents[0] != "<p>Hi</p>\n" {
+					t.Errorf("got content %q", plan.insertArticleContents[0])
+				}
+			}},
+		{
+			name: "edited",
+			source: entity.SyncSource{Articles: []entity.WriteArticle{
+				{Slug: "edited", Title: "Edited", Content: files["edited.html"]}}},
+			current: entity.Snapshot{Articles: current.Articles[1:2]},
+			want: []entity.Change{
+				{Entity: "article", Ref: "edited", Action: entity.ChangeUpdate, Fields: []entity.FieldChange{
+					{Name: "content", Before: "<p>before</p>", After: "<p>edited</p>"}}}},
+			check: func(t *testing.T, plan syncPlan) {
+				if plan.updateArticles[0].Id != 2 || plan.updateArticles[0].Content != files["edited.html"] {
+					t.Errorf("the stored article should be updated, got %+v", plan.updateArticles[0])
+				}
+			}},
+		{
+			name: "renamed",
+			source: entity.SyncSource{Articles: []entity.WriteArticle{
+				{Slug: "new-name", Title: "Kept", Content: files["kept.html"]}}},
+			current: entity.Snapshot{Articles: current.Articles[2:]},
+			want: []entity.Change{
+				{Entity: "article", Ref: "new-name", Action: entity.ChangeInsert},
+				{Entity: "article", Ref: "old-name", Action: entity.ChangeDelete}},
+			check: func(t *testing.T, plan syncPlan) {
+				if !reflect.DeepEqual(plan.deleteArticles, []entity.DeleteById{{Id: 3}}) {
+					t.Errorf("got deletions %+v", plan.deleteArticles)
+				}
+			}},
+		{
+			name:    "removed",
+			current: entity.Snapshot{Articles: current.Articles[:1]},
+			want: []entity.Change{
+				{Entity: "article", Ref: "kept", Action: entity.ChangeDelete}}},
+		{
+			name: "links of a kept project, and a removed project",
+			source: entity.SyncSource{Projects: []entity.WriteProject{{
+				Slug:        "tool",
+				Name:        "Tool",
+				Description: files["project.html"],
+				Links: []entity.WriteProjectLink{
+					{Url: "https://a.example", DisplayText: "A"},
+					{Url: "https://b.example", DisplayText: "Bee"},
+					{Url: "https://d.example", DisplayText: "D"}}}}},
+			current: entity.Snapshot{
+				Projects:     current.Projects,
+				ProjectLinks: current.ProjectLinks,
+				ProjectTags:  current.ProjectTags},
+			want: []entity.Change{
+				{Entity: "project_link", Ref: "tool https://b.example", Action: entity.ChangeUpdate, Fields: []entity.FieldChange{
+					{Name: "display_text", Before: "B", After: "Bee"}}},
+				{Entity: "project_link", Ref: "tool https://d.example", Action: entity.ChangeInsert},
+				{Entity: "project", Ref: "gone", Action: entity.ChangeDelete}},
+			check: func(t *testing.T, plan syncPlan) {
+				if len(plan.updateProjects) > 0 {
+					t.Errorf("the project shouldn't be revised for its links, got %+v", plan.updateProjects)
+				}
+				if !reflect.DeepEqual(plan.deleteLinks, []entity.DeleteById{{Id: 3}}) ||
+					!reflect.DeepEqual(plan.deleteProjectTags, []entity.DeleteById{{Id: 7}}) {
+					t.Errorf("the links and tags of the removed project should go, got %+v and %+v",
+						plan.deleteLinks, plan.deleteProjectTags)
+				}
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planSync(tt.source, tt.current)
+			if err != nil {
+				t.Fatalf("planSync failed: %v", err)
+			}
+			if !reflect.DeepEqual(plan.changes, tt.want) {
+				t.Errorf("changes\n got: %+v\nwant: %+v", plan.changes, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, plan)
+			}
+		})
+	}
+}
+
+func TestPlanSyncErrors(t *testing.T) {
+	files := contentFiles(t, map[string]string{"a.html": "<p>a</p>"})
+	tests := []struct {
+		name   string
+		source entity.SyncSource
+	}{
+		{"invalid slug", entity.SyncSource{Series: []entity.WriteSerie{{Slug: "Not A Slug"}}}},
+		{"numeric slug", entity.SyncSource{Articles: []entity.WriteArticle{{Slug: "42", Content: files["a.html"]}}}},
+		{"taken slug", entity.SyncSource{Articles: []entity.WriteArticle{
+			{Slug: "a", Content: files["a.html"]},
+			{Slug: "a", Content: files["a.html"]}}}},
+		{"scheduled without a time", entity.SyncSource{Projects: []entity.WriteProject{
+			{Slug: "a", Description: files["a.html"], State: entity.StateScheduled}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := planSync(tt.source, entity.Snapshot{})
+			if !errors.As(err, &oops.BadValues{}) {
+				t.Errorf("expected bad values, got %v", err)
+			}
+		})
+	}
+}
+
+// Syncing a directory after another should leave the site as if it was
+// only synced with the latter, and syncing it again should change nothing
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(persistence.NewMemory(cursor.NewSealer([]byte("key"))))
+	files := contentFiles(t, map[string]string{
+		"a.html": "<p>a</p>",
+		"b.html": "<p>b</p>",
+		"c.html": "<p>c</p>"})
+	part := func(order int) *entity.WriteSeriePart {
+		return &entity.WriteSeriePart{Slug: "basics", Order: order}
+	}
+
+	sources := []entity.SyncSource{
+		{
+			Series: []entity.WriteSerie{{Slug: "basics", Name: "Basics"}},
+			Articles: []entity.WriteArticle{
+				{Slug: "first", Title: "A", Content: files["a.html"], Serie: part(1)},
+				{Slug: "second", Title: "B", Content: files["b.html"], Serie: part(2)},
+				{Slug: "removed", Title: "C", Content: files["c.html"]}}},
+		{
+			Series: []entity.WriteSerie{{Slug: "basics", Name: "Basics"}},
+			Articles: []entity.WriteArticle{
+				{Slug: "first", Title: "A", Content: files["a.html"], Serie: part(1)},
+				// renamed, its place in the serie freed by the former one
+				{Slug: "second-part", Title: "B", Content: files["b.html"], Serie: part(2)},
+				{Slug: "added", Title: "C", Content: files["c.html"], Serie: part(3)}}},
+	}
+	for _, source := range sources {
+		if _, err := s.Sync(ctx, source); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	}
+
+	snapshot, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, a := range snapshot.Articles {
+		got[a.Slug] = shown(a.Serie)
+	}
+	want := map[string]string{
+		"first":       "basics #1",
+		"second-part": "basics #2",
+		"added":       "basics #3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got articles %v, want %v", got, want)
+	}
+
+	changes, err := s.Sync(ctx, sources[1])
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	} else if len(changes) > 0 {
+		t.Errorf("expected nothing left to sync, got %+v", changes)
+	}
+}
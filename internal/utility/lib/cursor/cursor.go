@@ -0,0 +1,75 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Returned when a cursor can't be read, or wasn't sealed with the same key
+var ErrInvalid = errors.New("cursor is malformed or wasn't issued by us")
+
+// Length of the signature kept within a cursor, as the full digest only makes
+// the urls longer
+const sIGNATURE_SIZE = 16
+
+// Seals values into opaque cursors handed out to clients, so they couldn't be
+// crafted or tampered with without knowing the key
+type Sealer struct {
+	key []byte
+}
+
+func NewSealer(key []byte) Sealer {
+	return Sealer{key: key}
+}
+
+// A key for cursors that only need to last as long as the process does
+func RandomKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("cursor<RandomKey>: %w", err)
+	}
+	return key, nil
+}
+
+// Encodes `v` as JSON along with its signature
+func (s Sealer) Seal(v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(s.sign(payload)), nil
+}
+
+// Decodes a cursor sealed by `Seal` into `v`, failing with `ErrInvalid` on
+// anything else
+func (s Sealer) Open(cursor string, v any) error {
+	encodedPayload, encodedSignature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return ErrInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.sign(payload)) {
+		return ErrInvalid
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+func (s Sealer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(payload)
+	return mac.Sum(nil)[:sIGNATURE_SIZE]
+}
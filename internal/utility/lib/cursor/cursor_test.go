@@ -0,0 +1,94 @@
+package cursor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type position struct {
+	Title string `json:"t"`
+	Id    int    `json:"i"`
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	sealer := NewSealer([]byte("key"))
+	tests := []position{
+		{},
+		{Title: "hello", Id: 42},
+		{Title: "naïve \"quotes\" & .dots.", Id: -1},
+	}
+	for _, want := range tests {
+		sealed, err := sealer.Seal(want)
+		if err != nil {
+			t.Fatalf("Seal(%+v) failed: %v", want, err)
+		}
+		var got position
+		if err := sealer.Open(sealed, &got); err != nil {
+			t.Fatalf("Open(%q) failed: %v", sealed, err)
+		} else if got != want {
+			t.Errorf("Open(Seal(%+v)) = %+v", want, got)
+		}
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	sealer := NewSealer([]byte("key"))
+	sealed, err := sealer.Seal(position{Title: "hello", Id: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(sealed, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"t":"hello","i":43}`))
+	unsigned := []byte("not json")
+
+	tests := []struct {
+		name   string
+		sealer Sealer
+		cursor string
+	}{
+		{"empty", sealer, ""},
+		{"without signature", sealer, payload},
+		{"forged payload", sealer, forged + "." + signature},
+		{"flipped signature", sealer, payload + "." + flipFirst(signature)},
+		{"truncated signature", sealer, payload + "." + signature[:len(signature)-2]},
+		{"payload isn't base64", sealer, "%%%." + signature},
+		{"signature isn't base64", sealer, payload + ".%%%"},
+		{"another key", NewSealer([]byte("other key")), sealed},
+		{"payload isn't JSON", sealer,
+			base64.RawURLEncoding.EncodeToString(unsigned) + "." +
+				base64.RawURLEncoding.EncodeToString(sealer.sign(unsigned))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got position
+			if err := tt.sealer.Open(tt.cursor, &got); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Open(%q) = %v, want ErrInvalid", tt.cursor, err)
+			}
+		})
+	}
+}
+
+func TestRandomKey(t *testing.T) {
+	a, err := RandomKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := RandomKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 32 || bytes.Equal(a, b) {
+		t.Errorf("expected distinct 32-byte keys, got %x and %x", a, b)
+	}
+}
+
+// Changes the first character of a base64 string into another valid one
+func flipFirst(s string) string {
+	if s[0] == 'A' {
+		return "B" + s[1:]
+	}
+	return "A" + s[1:]
+}
@@ -0,0 +1,131 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+)
+
+type Op int
+
+const (
+	OpEqual  Op = iota
+	OpInsert    // only found in the newer text
+	OpDelete    // only found in the older text
+)
+
+// A run of words sharing the same fate between both texts
+type Chunk struct {
+	Op    Op
+	Words []string
+}
+
+func (c Chunk) Text() string {
+	return strings.Join(c.Words, " ")
+}
+
+// Word-level difference between `old` and `new`, where words are separated
+// by any whitespace. The whitespace itself isn't compared
+func Words(old, new string) []Chunk {
+	return diff(strings.Fields(old), strings.Fields(new))
+}
+
+func diff(a, b []string) []Chunk {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var chunks []Chunk
+	chunks = push(chunks, OpEqual, a[:prefix]...)
+	for _, e := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		chunks = push(chunks, e.op, e.word)
+	}
+	return push(chunks, OpEqual, a[len(a)-suffix:]...)
+}
+
+// Appends the words to the last chunk when it's of the same kind
+func push(chunks []Chunk, op Op, words ...string) []Chunk {
+	if len(words) == 0 {
+		return chunks
+	} else if n := len(chunks); n > 0 && chunks[n-1].Op == op {
+		chunks[n-1].Words = append(chunks[n-1].Words, words...)
+		return chunks
+	}
+	return append(chunks, Chunk{Op: op, Words: slices.Clone(words)})
+}
+
+type edit struct {
+	op   Op
+	word string
+}
+
+// Shortest edit script turning `a` into `b`, following Myers' "An O(ND)
+// Difference Algorithm and Its Variations". The furthest reaching paths of
+// every step are kept to walk the script back afterwards
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down, inserting from `b`
+			} else {
+				x = v[offset+k-1] + 1 // right, deleting from `a`
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var script []edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			script = append(script, edit{op: OpEqual, word: a[x]})
+		}
+		if x == prevX {
+			y--
+			script = append(script, edit{op: OpInsert, word: b[y]})
+		} else {
+			x--
+			script = append(script, edit{op: OpDelete, word: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		script = append(script, edit{op: OpEqual, word: a[x]})
+	}
+	slices.Reverse(script)
+	return script
+}
@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []Chunk
+	}{
+		{name: "empty"},
+		{name: "only whitespace", old: " \n\t", new: "  "},
+		{
+			name: "identical",
+			old:  "the quick fox",
+			new:  "the quick fox",
+			want: []Chunk{{OpEqual, []string{"the", "quick", "fox"}}}},
+		{
+			name: "whitespace isn't compared",
+			old:  "the  quick\nfox",
+			new:  "the quick fox ",
+			want: []Chunk{{OpEqual, []string{"the", "quick", "fox"}}}},
+		{
+			name: "insert only",
+			old:  "the fox",
+			new:  "the quick brown fox jumps",
+			want: []Chunk{
+				{OpEqual, []string{"the"}},
+				{OpInsert, []string{"quick", "brown"}},
+				{OpEqual, []string{"fox"}},
+				{OpInsert, []string{"jumps"}}}},
+		{
+			name: "insert into nothing",
+			new:  "the fox",
+			want: []Chunk{{OpInsert, []string{"the", "fox"}}}},
+		{
+			name: "delete only",
+			old:  "the quick brown fox jumps",
+			new:  "quick fox",
+			want: []Chunk{
+				{OpDelete, []string{"the"}},
+				{OpEqual, []string{"quick"}},
+				{OpDelete, []string{"brown"}},
+				{OpEqual, []string{"fox"}},
+				{OpDelete, []string{"jumps"}}}},
+		{
+			name: "delete everything",
+			old:  "the fox",
+			want: []Chunk{{OpDelete, []string{"the", "fox"}}}},
+		{
+			name: "replaced",
+			old:  "the quick fox",
+			new:  "the slow fox",
+			want: []Chunk{
+				{OpEqual, []string{"the"}},
+				{OpDelete, []string{"quick"}},
+				{OpInsert, []string{"slow"}},
+				{OpEqual, []string{"fox"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Words(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\n got: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Whatever the script, keeping the equal words with either side's own words
+// should give back that side
+func TestWordsRebuildsBothTexts(t *testing.T) {
+	tests := [][2]string{
+		{"a b c a b b a", "c b a b a c"},
+		{"one two three", "three two one"},
+		{"x x x", "x y x y x"},
+		{"a", "b"},
+	}
+	for _, tt := range tests {
+		var old, new []string
+		for _, c := range Words(tt[0], tt[1]) {
+			if c.Op != OpInsert {
+				old = append(old, c.Text())
+			}
+			if c.Op != OpDelete {
+				new = append(new, c.Text())
+			}
+		}
+		if got := strings.Join(old, " "); got != tt[0] {
+			t.Errorf("%q -> %q: rebuilt old %q", tt[0], tt[1], got)
+		}
+		if got := strings.Join(new, " "); got != tt[1] {
+			t.Errorf("%q -> %q: rebuilt new %q", tt[0], tt[1], got)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// A syndication feed, written as either Atom, RSS 2.0 or JSON Feed 1.1
+type Feed struct {
+	Title       string
+	Description string
+	Author      string
+	Link        string // absolute url of the page the feed follows
+	Self        string // absolute url of the feed itself
+	Entries     []Entry
+}
+
+type Entry struct {
+	Link       string // absolute url, which also identifies the entry
+	Title      string
+	Summary    string
+	Content    string // HTML
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// Time of the latest update among the entries, zero when there's none
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, e := range f.Entries {
+		if e.Updated.After(updated) {
+			updated = e.Updated
+		}
+	}
+	return updated
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  *atomAuthor `xml:"author"`
+	Entries []atomEntry
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	XMLName    xml.Name       `xml:"entry"`
+	Id         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    atomText       `xml:"content"`
+}
+
+func (f Feed) WriteAtom(w io.Writer) error {
+	feed := atomFeed{
+		Id:      f.Self,
+		Title:   f.Title,
+		Updated: f.Updated().UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Href: f.Self},
+			{Rel: "alternate", Href: f.Link}}}
+	if f.Author != "" {
+		feed.Author = &atomAuthor{Name: f.Author}
+	}
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			Id:        e.Link,
+			Title:     e.Title,
+			Link:      atomLink{Rel: "alternate", Href: e.Link},
+			Published: e.Published.UTC().Format(time.RFC3339),
+			Updated:   e.Updated.UTC().Format(time.RFC3339),
+			Summary:   e.Summary,
+			Content:   atomText{Type: "html", Body: e.Content}}
+		for _, c := range e.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return writeXml(w, feed)
+}
+
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNs    string     `xml:"xmlns:atom,attr"`
+	ContentNs string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Body        string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        rssGuid  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description,omitempty"`
+	Content     string   `xml:"content:encoded"`
+}
+
+// RSS items carry no update time, only when they were published
+func (f Feed) WriteRss(w io.Writer) error {
+	feed := rss{
+		Version:   "2.0",
+		AtomNs:    "http://www.w3.org/2005/Atom",
+		ContentNs: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Self:        atomLink{Rel: "self", Href: f.Self}}}
+	if updated := f.Updated(); !updated.IsZero() {
+		feed.Channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, e := range f.Entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			Guid:        rssGuid{IsPermaLink: true, Body: e.Link},
+			PubDate:     e.Published.UTC().Format(time.RFC1123Z),
+			Categories:  e.Categories,
+			Description: e.Summary,
+			Content:     e.Content})
+	}
+	return writeXml(w, feed)
+}
+
+func writeXml(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageUrl string       `json:"home_page_url"`
+	FeedUrl     string       `json:"feed_url"`
+	Description string       `json:"description,omitempty"`
+	Authors     []jsonAuthor `json:"authors,omitempty"`
+	Items       []jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonItem struct {
+	Id            string    `json:"id"`
+	Url           string    `json:"url"`
+	Title         string    `json:"title"`
+	Summary       string    `json:"summary,omitempty"`
+	ContentHtml   string    `json:"content_html"`
+	Tags          []string  `json:"tags,omitempty"`
+	DatePublished time.Time `json:"date_published"`
+	DateModified  time.Time `json:"date_modified"`
+}
+
+func (f Feed) WriteJson(w io.Writer) error {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageUrl: f.Link,
+		FeedUrl:     f.Self,
+		Description: f.Description,
+		Items:       []jsonItem{}}
+	if f.Author != "" {
+		feed.Authors = []jsonAuthor{{Name: f.Author}}
+	}
+	for _, e := range f.Entries {
+		feed.Items = append(feed.Items, jsonItem{
+			Id:            e.Link,
+			Url:           e.Link,
+			Title:         e.Title,
+			Summary:       e.Summary,
+			ContentHtml:   e.Content,
+			Tags:          e.Categories,
+			DatePublished: e.Published.UTC(),
+			DateModified:  e.Updated.UTC()})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(feed)
+}
@@ -0,0 +1,244 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	published = time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("WIB", 7*60*60))
+	updated   = time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)
+)
+
+func testFeed() Feed {
+	return Feed{
+		Title:       "misite",
+		Description: "Latest articles",
+		Author:      "someone",
+		Link:        "https://example.com/articles",
+		Self:        "https://example.com/feed.xml",
+		Entries: []Entry{
+			{
+				Link:       "https://example.com/article/hello",
+				Title:      "Hello & welcome",
+				Summary:    "A greeting",
+				Content:    `<p>a <b>bold</b> & "quoted" move</p>`,
+				Categories: []string{"go", "sql"},
+				Published:  published,
+				Updated:    updated},
+			{
+				Link:      "https://example.com/article/older",
+				Title:     "Older",
+				Content:   "<p>old</p>",
+				Published: published,
+				Updated:   published}},
+	}
+}
+
+func TestUpdated(t *testing.T) {
+	tests := []struct {
+		name string
+		feed Feed
+		want time.Time
+	}{
+		{"no entry", Feed{}, time.Time{}},
+		{"latest update", testFeed(), updated},
+	}
+	for _, tt := range tests {
+		if got := tt.feed.Updated(); !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var out bytes.Buffer
+	if err := testFeed().WriteAtom(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), xml.Header) {
+		t.Errorf("missing the XML header:\n%s", out.String())
+	}
+
+	var got struct {
+		Id      string `xml:"id"`
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+		Links   []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Author  string `xml:"author>name"`
+		Entries []struct {
+			Id         string `xml:"id"`
+			Title      string `xml:"title"`
+			Published  string `xml:"published"`
+			Updated    string `xml:"updated"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+			Summary string `xml:"summary"`
+			Content struct {
+				Type string `xml:"type,attr"`
+				Body string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("the feed isn't valid XML: %v", err)
+	}
+
+	if got.Id != "https://example.com/feed.xml" || got.Title != "misite" || got.Author != "someone" {
+		t.Errorf("unexpected feed: %+v", got)
+	}
+	if got.Updated != "2026-02-03T04:05:06Z" {
+		t.Errorf("got updated %q", got.Updated)
+	}
+	if len(got.Links) != 2 || got.Links[0].Rel != "self" || got.Links[1].Href != "https://example.com/articles" {
+		t.Errorf("unexpected links: %+v", got.Links)
+	}
+	if len(got.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got.Entries))
+	}
+	entry := got.Entries[0]
+	if entry.Id != "https://example.com/article/hello" || entry.Title != "Hello & welcome" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry.Published != "2026-01-01T20:04:05Z" {
+		t.Errorf("the publication should be in UTC, got %q", entry.Published)
+	}
+	if entry.Content.Type != "html" || entry.Content.Body != testFeed().Entries[0].Content {
+		t.Errorf("the content should come back as it is, got %+v", entry.Content)
+	}
+	if len(entry.Categories) != 2 || entry.Categories[1].Term != "sql" {
+		t.Errorf("unexpected categories: %+v", entry.Categories)
+	}
+}
+
+func TestWriteRss(t *testing.T) {
+	tests := []struct {
+		name          string
+		feed          Feed
+		lastBuildDate string
+		items         int
+	}{
+		{"entries", testFeed(), "Tue, 03 Feb 2026 04:05:06 +0000", 2},
+		{"no entry", Feed{Title: "empty"}, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.feed.WriteRss(&out); err != nil {
+				t.Fatal(err)
+			}
+
+			var got struct {
+				Version string `xml:"version,attr"`
+				Channel struct {
+					Title         string `xml:"title"`
+					LastBuildDate string `xml:"lastBuildDate"`
+					Items         []struct {
+						Guid struct {
+							IsPermaLink bool   `xml:"isPermaLink,attr"`
+							Body        string `xml:",chardata"`
+						} `xml:"guid"`
+						PubDate string `xml:"pubDate"`
+						Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+					} `xml:"item"`
+				} `xml:"channel"`
+			}
+			if err := xml.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("the feed isn't valid XML: %v", err)
+			}
+			if got.Version != "2.0" || got.Channel.Title != tt.feed.Title {
+				t.Errorf("unexpected channel: %+v", got)
+			}
+			if got.Channel.LastBuildDate != tt.lastBuildDate {
+				t.Errorf("got lastBuildDate %q, want %q", got.Channel.LastBuildDate, tt.lastBuildDate)
+			}
+			if len(got.Channel.Items) != tt.items {
+				t.Fatalf("got %d items, want %d", len(got.Channel.Items), tt.items)
+			}
+			if tt.items == 0 {
+				return
+			}
+			item := got.Channel.Items[0]
+			if !item.Guid.IsPermaLink || item.Guid.Body != "https://example.com/article/hello" {
+				t.Errorf("unexpected guid: %+v", item.Guid)
+			}
+			if item.PubDate != "Thu, 01 Jan 2026 20:04:05 +0000" {
+				t.Errorf("got pubDate %q", item.PubDate)
+			}
+			if item.Content != tt.feed.Entries[0].Content {
+				t.Errorf("the content should come back as it is, got %q", item.Content)
+			}
+		})
+	}
+}
+
+func TestWriteJson(t *testing.T) {
+	tests := []struct {
+		name string
+		feed Feed
+		want map[string]any
+	}{
+		{
+			name: "no entry",
+			feed: Feed{Title: "empty", Link: "https://example.com", Self: "https://example.com/feed.json"},
+			want: map[string]any{
+				"version":       "https://jsonfeed.org/version/1.1",
+				"title":         "empty",
+				"home_page_url": "https://example.com",
+				"feed_url":      "https://example.com/feed.json",
+				"items":         []any{}}},
+		{
+			name: "entries",
+			feed: Feed{
+				Title: "misite",
+				Self:  "https://example.com/feed.json",
+				Entries: []Entry{{
+					Link:       "https://example.com/article/hello",
+					Title:      "Hello",
+					Content:    "<p>a & b</p>",
+					Categories: []string{"go"},
+					Published:  published,
+					Updated:    updated}}},
+			want: map[string]any{
+				"version":       "https://jsonfeed.org/version/1.1",
+				"title":         "misite",
+				"home_page_url": "",
+				"feed_url":      "https://example.com/feed.json",
+				"items": []any{map[string]any{
+					"id":             "https://example.com/article/hello",
+					"url":            "https://example.com/article/hello",
+					"title":          "Hello",
+					"content_html":   "<p>a & b</p>",
+					"tags":           []any{"go"},
+					"date_published": "2026-01-01T20:04:05Z",
+					"date_modified":  "2026-02-03T04:05:06Z"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.feed.WriteJson(&out); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(out.String(), `\u003c`) {
+				t.Errorf("HTML shouldn't be escaped:\n%s", out.String())
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("the feed isn't valid JSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\n got: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
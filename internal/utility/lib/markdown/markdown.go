@@ -0,0 +1,130 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	goldmarkHtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Describes the document it's written on top of, between `---` lines. Every
+// field is optional
+type FrontMatter struct {
+	Title    string   `yaml:"title"`    // or the name of a project
+	Subtitle string   `yaml:"subtitle"` // or the synopsis of a project
+	Tags     []string `yaml:"tags"`     // by name
+	Serie    string   `yaml:"serie"`    // by slug
+	Order    int      `yaml:"order"`    // within the serie, for articles
+}
+
+// CommonMark with tables and footnotes. Raw HTML is kept, as the documents
+// are written by the authors of the site themselves
+var converter = goldmark.New(
+	goldmark.WithExtensions(extension.Table, extension.Footnote),
+	goldmark.WithRendererOptions(
+		goldmarkHtml.WithUnsafe(),
+		renderer.WithNodeRenderers(util.Prioritized(codeBlockRenderer{}, 100))))
+
+// Whether the file of `path` is written in Markdown, judging by its extension
+func IsFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// Converts a Markdown document into HTML, along with its front matter
+func Convert(source []byte) (FrontMatter, string, error) {
+	var meta FrontMatter
+	header, body, err := split(source)
+	if err != nil {
+		return meta, "", fmt.Errorf("markdown.Convert: %w", err)
+	}
+	if len(header) > 0 {
+		decoder := yaml.NewDecoder(bytes.NewReader(header))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&meta); err != nil {
+			return meta, "", fmt.Errorf("markdown.Convert: front matter: %w", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := converter.Convert(body, &out); err != nil {
+		return meta, "", fmt.Errorf("markdown.Convert: %w", err)
+	}
+	return meta, out.String(), nil
+}
+
+// Separates the front matter from the document. Documents without one are
+// given back whole
+func split(source []byte) ([]byte, []byte, error) {
+	source = bytes.TrimPrefix(source, []byte("\ufeff")) // BOM
+	firstLine, rest, _ := bytes.Cut(source, []byte("\n"))
+	if string(bytes.TrimRight(firstLine, " \t\r")) != "---" {
+		return nil, source, nil
+	}
+
+	var header []byte
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		switch string(bytes.TrimRight(line, " \t\r")) {
+		case "---", "...":
+			return header, rest, nil
+		}
+		header = append(append(header, line...), '\n')
+	}
+	return nil, nil, errors.New("front matter isn't closed by `---`")
+}
+
+// Writes code blocks the way hand-written articles do, so the highlighter
+// finds them: the language goes on the wrapper, which the highlighted code
+// replaces the content of
+type codeBlockRenderer struct{}
+
+func (r codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.render)
+	reg.Register(ast.KindCodeBlock, r.render)
+}
+
+func (r codeBlockRenderer) render(
+	w util.BufWriter,
+	source []byte,
+	node ast.Node,
+	entering bool,
+) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	var language string
+	if fenced, ok := node.(*ast.FencedCodeBlock); ok && fenced.Info != nil {
+		language = string(fenced.Language(source))
+	}
+	if language == "" {
+		w.WriteString(`<div class="codeblock"><pre class="codeblock__code"><code>`)
+	} else {
+		escaped := html.EscapeString(language)
+		fmt.Fprintf(w,
+			`<div class="codeblock" data-lang="%s"><pre class="codeblock__code"><code class="language-%s">`,
+			escaped, escaped)
+	}
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		w.WriteString(html.EscapeString(string(line.Value(source))))
+	}
+	w.WriteString("</code></pre></div>\n")
+	return ast.WalkSkipChildren, nil
+}
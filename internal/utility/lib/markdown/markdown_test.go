@@ -0,0 +1,131 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantMeta FrontMatter
+		wantHtml string
+	}{
+		{
+			name:     "without front matter",
+			source:   "# Hello\n\nWorld\n",
+			wantHtml: "<h1>Hello</h1>\n<p>World</p>\n"},
+		{
+			name: "front matter",
+			source: "---\n" +
+				"title: Hello\n" +
+				"subtitle: A greeting\n" +
+				"tags: [go, sql]\n" +
+				"serie: basics\n" +
+				"order: 2\n" +
+				"---\n" +
+				"World\n",
+			wantMeta: FrontMatter{
+				Title:    "Hello",
+				Subtitle: "A greeting",
+				Tags:     []string{"go", "sql"},
+				Serie:    "basics",
+				Order:    2},
+			wantHtml: "<p>World</p>\n"},
+		{
+			name:     "front matter closed by dots, with CRLF",
+			source:   "---\r\ntitle: Hello\r\n...\r\nWorld\r\n",
+			wantMeta: FrontMatter{Title: "Hello"},
+			wantHtml: "<p>World</p>\n"},
+		{
+			name:     "empty front matter",
+			source:   "---\n---\nWorld\n",
+			wantHtml: "<p>World</p>\n"},
+		{
+			name:     "BOM",
+			source:   "\ufeff---\ntitle: Hello\n---\nWorld\n",
+			wantMeta: FrontMatter{Title: "Hello"},
+			wantHtml: "<p>World</p>\n"},
+		{
+			name:     "thematic break past the first line",
+			source:   "World\n\n---\n",
+			wantHtml: "<p>World</p>\n<hr>\n"},
+		{
+			name:   "fenced code with a language",
+			source: "```go\nif a < b && c {\n}\n```\n",
+			wantHtml: `<div class="codeblock" data-lang="go"><pre class="codeblock__code">` +
+				`<code class="language-go">if a &lt; b &amp;&amp; c {` + "\n}\n" +
+				"</code></pre></div>\n"},
+		{
+			name:   "fenced code without a language",
+			source: "```\n<b>\n```\n",
+			wantHtml: `<div class="codeblock"><pre class="codeblock__code"><code>` +
+				"&lt;b&gt;\n</code></pre></div>\n"},
+		{
+			name:   "indented code",
+			source: "    x := 1\n",
+			wantHtml: `<div class="codeblock"><pre class="codeblock__code"><code>` +
+				"x := 1\n</code></pre></div>\n"},
+		{
+			name:   "language is escaped",
+			source: "```a\"b\nx\n```\n",
+			wantHtml: `<div class="codeblock" data-lang="a&#34;b"><pre class="codeblock__code">` +
+				`<code class="language-a&#34;b">x` + "\n</code></pre></div>\n"},
+		{
+			name:     "raw HTML is kept",
+			source:   "<aside>Note</aside>\n",
+			wantHtml: "<aside>Note</aside>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, got, err := Convert([]byte(tt.source))
+			if err != nil {
+				t.Fatalf("Convert failed: %v", err)
+			}
+			if !reflect.DeepEqual(meta, tt.wantMeta) {
+				t.Errorf("front matter\n got: %+v\nwant: %+v", meta, tt.wantMeta)
+			}
+			if got != tt.wantHtml {
+				t.Errorf("html\n got: %q\nwant: %q", got, tt.wantHtml)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		msg    string // part of the error
+	}{
+		{"front matter never closed", "---\ntitle: Hello\nWorld\n", "isn't closed"},
+		{"unknown field", "---\nauthor: me\n---\nWorld\n", "front matter"},
+		{"mistyped field", "---\norder: first\n---\nWorld\n", "front matter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Convert([]byte(tt.source))
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("expected an error about %q, got %v", tt.msg, err)
+			}
+		})
+	}
+}
+
+func TestIsFile(t *testing.T) {
+	tests := map[string]bool{
+		"post.md":           true,
+		"dir/post.MD":       true,
+		"post.markdown":     true,
+		"post.html":         false,
+		"md":                false,
+		"post.md/image.png": false,
+	}
+	for path, want := range tests {
+		if got := IsFile(path); got != want {
+			t.Errorf("IsFile(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
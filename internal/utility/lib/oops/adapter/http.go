@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -18,6 +19,8 @@ func HttpStatusCode(err error) int {
 		return http.StatusForbidden
 	case errors.As(err, &oops.NotFound{}):
 		return http.StatusNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,110 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Limits of a single sitemap, past which the urls should be split across
+// several sitemaps listed by an index
+const (
+	MaxUrls  = 50_000
+	MaxBytes = 50 * 1024 * 1024 // uncompressed
+)
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// Either a page of a sitemap, or a sitemap of an index
+type Url struct {
+	Loc     string    // absolute
+	LastMod time.Time // left out when zero
+}
+
+type xmlUrl struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func (u Url) xml() xmlUrl {
+	entry := xmlUrl{Loc: u.Loc}
+	if !u.LastMod.IsZero() {
+		entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	return entry
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Urls    []xmlUrl `xml:"url"`
+}
+
+type index struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []xmlUrl `xml:"sitemap"`
+}
+
+// Splits `urls` into sitemaps within the limits, keeping their order. Always
+// gives at least one, even if empty
+func Split(urls []Url) [][]Url {
+	// Room taken by the XML header and the `urlset` element itself
+	overhead := len(xml.Header) + len(`<urlset xmlns=""></urlset>`) + len(namespace)
+
+	sitemaps := [][]Url{{}}
+	size := overhead
+	for _, u := range urls {
+		encoded, _ := xml.Marshal(struct {
+			XMLName xml.Name `xml:"url"`
+			xmlUrl
+		}{xmlUrl: u.xml()})
+		current := &sitemaps[len(sitemaps)-1]
+		if len(*current) == MaxUrls || size+len(encoded) > MaxBytes {
+			sitemaps = append(sitemaps, []Url{})
+			current = &sitemaps[len(sitemaps)-1]
+			size = overhead
+		}
+		*current = append(*current, u)
+		size += len(encoded)
+	}
+	return sitemaps
+}
+
+// Latest modification among `urls`, zero when there's none
+func LastMod(urls []Url) time.Time {
+	var latest time.Time
+	for _, u := range urls {
+		if u.LastMod.After(latest) {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
+
+func WriteUrlSet(w io.Writer, urls []Url) error {
+	set := urlSet{Xmlns: namespace, Urls: []xmlUrl{}}
+	for _, u := range urls {
+		set.Urls = append(set.Urls, u.xml())
+	}
+	return write(w, set)
+}
+
+func WriteIndex(w io.Writer, sitemaps []Url) error {
+	idx := index{Xmlns: namespace, Sitemaps: []xmlUrl{}}
+	for _, s := range sitemaps {
+		idx.Sitemaps = append(idx.Sitemaps, s.xml())
+	}
+	return write(w, idx)
+}
+
+func write(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,157 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func urls(n int, loc string) []Url {
+	urls := make([]Url, n)
+	for idx := range urls {
+		urls[idx] = Url{Loc: fmt.Sprintf("%s/%d", loc, idx)}
+	}
+	return urls
+}
+
+func TestSplitByCount(t *testing.T) {
+	tests := []struct {
+		urls int
+		want []int // urls of each sitemap
+	}{
+		{0, []int{0}},
+		{1, []int{1}},
+		{MaxUrls, []int{MaxUrls}},
+		{MaxUrls + 1, []int{MaxUrls, 1}},
+		{2*MaxUrls + 1, []int{MaxUrls, MaxUrls, 1}},
+	}
+	for _, tt := range tests {
+		given := urls(tt.urls, "https://example.com/article")
+		sitemaps := Split(given)
+		var got []int
+		for _, s := range sitemaps {
+			got = append(got, len(s))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%d urls: got sitemaps of %v, want %v", tt.urls, got, tt.want)
+			continue
+		}
+		// in order, with none lost along the way
+		idx := 0
+		for _, s := range sitemaps {
+			for _, u := range s {
+				if u != given[idx] {
+					t.Fatalf("%d urls: got %v at %d, want %v", tt.urls, u, idx, given[idx])
+				}
+				idx++
+			}
+		}
+	}
+}
+
+func TestSplitBySize(t *testing.T) {
+	// few enough for the count to stay within its limit, long enough for the
+	// sitemap to grow past its size
+	loc := "https://example.com/article/" + strings.Repeat("a", 2500)
+	given := urls(MaxUrls/2, loc)
+	sitemaps := Split(given)
+	if len(sitemaps) < 2 {
+		t.Fatalf("expected the urls to be split, got %d sitemap", len(sitemaps))
+	}
+
+	total := 0
+	for idx, s := range sitemaps {
+		var out bytes.Buffer
+		if err := WriteUrlSet(&out, s); err != nil {
+			t.Fatal(err)
+		}
+		if out.Len() > MaxBytes {
+			t.Errorf("sitemap %d takes %d bytes, past %d", idx, out.Len(), MaxBytes)
+		}
+		total += len(s)
+		if idx == len(sitemaps)-1 {
+			continue
+		}
+
+		// splitting any later would've been too late
+		out.Reset()
+		if err := WriteUrlSet(&out, append(s[:len(s):len(s)], sitemaps[idx+1][0])); err != nil {
+			t.Fatal(err)
+		}
+		if out.Len() <= MaxBytes {
+			t.Errorf("sitemap %d was split early, it'd take %d bytes with the next url", idx, out.Len())
+		}
+	}
+	if total != len(given) {
+		t.Errorf("got %d urls across the sitemaps, want %d", total, len(given))
+	}
+}
+
+func TestLastMod(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got := LastMod(nil); !got.IsZero() {
+		t.Errorf("expected no modification, got %v", got)
+	}
+	if got := LastMod([]Url{{LastMod: older}, {LastMod: newer}, {}}); !got.Equal(newer) {
+		t.Errorf("got %v, want %v", got, newer)
+	}
+}
+
+func TestWriteUrlSet(t *testing.T) {
+	var out bytes.Buffer
+	wib := time.FixedZone("WIB", 7*60*60)
+	err := WriteUrlSet(&out, []Url{
+		{Loc: "https://example.com/?a=1&b=2", LastMod: time.Date(2026, 1, 2, 3, 4, 5, 0, wib)},
+		{Loc: "https://example.com/article/hello"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := xml.Header +
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+		`<url><loc>https://example.com/?a=1&amp;b=2</loc><lastmod>2026-01-01T20:04:05Z</lastmod></url>` +
+		`<url><loc>https://example.com/article/hello</loc></url>` +
+		`</urlset>`
+	if out.String() != want {
+		t.Errorf("\n got: %s\nwant: %s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := WriteUrlSet(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	want = xml.Header + `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`
+	if out.String() != want {
+		t.Errorf("\n got: %s\nwant: %s", out.String(), want)
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	lastMod := time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)
+	sitemaps := Split(urls(MaxUrls+1, "https://example.com/article"))
+	sitemaps[1][0].LastMod = lastMod
+
+	var parts []Url
+	for idx, s := range sitemaps {
+		parts = append(parts, Url{
+			Loc:     fmt.Sprintf("https://example.com/sitemap-%d.xml", idx+1),
+			LastMod: LastMod(s)})
+	}
+	var out bytes.Buffer
+	if err := WriteIndex(&out, parts); err != nil {
+		t.Fatal(err)
+	}
+
+	want := xml.Header +
+		`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+		`<sitemap><loc>https://example.com/sitemap-1.xml</loc></sitemap>` +
+		`<sitemap><loc>https://example.com/sitemap-2.xml</loc><lastmod>2026-02-03T04:05:06Z</lastmod></sitemap>` +
+		`</sitemapindex>`
+	if out.String() != want {
+		t.Errorf("\n got: %s\nwant: %s", out.String(), want)
+	}
+}
@@ -0,0 +1,35 @@
+package slug
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	separators = regexp.MustCompile(`[^a-z0-9]+`)
+	wellFormed = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	numeric    = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// Turns `s` into lowercase words joined by hyphens, dropping anything else.
+// Follows the same rule as the backfill of the `add_slugs` migration
+func Make(s string) string {
+	return strings.Trim(separators.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// Whether `s` could be a slug. Numbers are left out, as they'd be taken for
+// an id instead
+func Valid(s string) bool {
+	return wellFormed.MatchString(s) && !numeric.MatchString(s)
+}
+
+// Numbers `base` until it's no longer `taken`, like `base`, `base-2`,
+// `base-3` and so on
+func Free(base string, taken func(slug string) bool) string {
+	candidate := base
+	for n := 2; taken(candidate); n++ {
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+	return candidate
+}
@@ -0,0 +1,69 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := map[string]string{
+		"Hello World":              "hello-world",
+		"  Hello,  World!  ":       "hello-world",
+		"Go 1.24: what's new?":     "go-1-24-what-s-new",
+		"already-a-slug":           "already-a-slug",
+		"--dashes--":               "dashes",
+		"Crème brûlée":             "cr-me-br-l-e",
+		"Ünïcödé":                  "n-c-d",
+		"日本語":                      "",
+		"Rust 🦀 and Go":            "rust-and-go",
+		"":                         "",
+		"snake_case and CamelCase": "snake-case-and-camelcase",
+	}
+	for s, want := range tests {
+		if got := Make(s); got != want {
+			t.Errorf("Make(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := map[string]bool{
+		"hello":        true,
+		"hello-world":  true,
+		"go-1-24":      true,
+		"404-page":     true,
+		"":             false,
+		"404":          false, // taken for an id
+		"Hello":        false,
+		"hello--world": false,
+		"-hello":       false,
+		"hello-":       false,
+		"héllo":        false,
+		"hello world":  false,
+	}
+	for s, want := range tests {
+		if got := Valid(s); got != want {
+			t.Errorf("Valid(%q) = %t, want %t", s, got, want)
+		}
+	}
+}
+
+func TestFree(t *testing.T) {
+	tests := []struct {
+		taken []string
+		want  string
+	}{
+		{nil, "hello"},
+		{[]string{"hello-2"}, "hello"},
+		{[]string{"hello"}, "hello-2"},
+		{[]string{"hello", "hello-2", "hello-3"}, "hello-4"},
+		{[]string{"hello", "hello-3"}, "hello-2"},
+	}
+	for _, tt := range tests {
+		taken := map[string]bool{}
+		for _, s := range tt.taken {
+			taken[s] = true
+		}
+		got := Free("hello", func(s string) bool { return taken[s] })
+		if got != tt.want {
+			t.Errorf("Free with %v taken = %q, want %q", tt.taken, got, tt.want)
+		}
+	}
+}
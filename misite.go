@@ -0,0 +1,12 @@
+// Assets that are shipped within the binaries
+package misite
+
+import "embed"
+
+// Schema migrations, written in goose's format
+//
+//go:embed _etc/migration/*.sql
+var Migrations embed.FS
+
+// Where the migrations are located within `Migrations`
+const MigrationDir = "_etc/migration"